@@ -0,0 +1,335 @@
+package log
+
+import (
+	"bufio"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// 缓冲区满时的处理策略.
+const (
+	// OverflowBlock 阻塞写日志的调用方，直到缓冲区有空闲位置.
+	OverflowBlock = "block"
+	// OverflowDropOldest 丢弃缓冲区中最早的日志.
+	OverflowDropOldest = "drop-oldest"
+	// OverflowDropDebugFirst 优先丢弃级别最低的日志（首先是 Debug），同级别时丢弃最早的日志.
+	OverflowDropDebugFirst = "drop-debug-first"
+)
+
+// asyncWriteBufferSize 是异步写入时合并输出使用的缓冲大小.
+const asyncWriteBufferSize = 256 * 1024
+
+var droppedEntries atomic.Uint64
+
+// DroppedEntries 返回异步模式下因缓冲区溢出而被丢弃的日志条数.
+func DroppedEntries() uint64 {
+	return droppedEntries.Load()
+}
+
+// AsyncOptions 异步写日志的配置.
+type AsyncOptions struct {
+	Enabled        bool          `json:"enabled"         mapstructure:"enabled"`         // 是否开启异步写日志
+	BufferSize     int           `json:"buffer-size"     mapstructure:"buffer-size"`     // 缓冲区最多可以容纳的日志条数
+	FlushInterval  time.Duration `json:"flush-interval"  mapstructure:"flush-interval"`  // 将缓冲的日志刷新到输出的时间间隔
+	OverflowPolicy string        `json:"overflow-policy" mapstructure:"overflow-policy"` // 缓冲区满时的处理策略，支持 block、drop-oldest 和 drop-debug-first
+}
+
+// NewAsyncOptions 创建一个带有默认参数的 AsyncOptions 对象.
+func NewAsyncOptions() AsyncOptions {
+	return AsyncOptions{
+		Enabled:        false,
+		BufferSize:     1024,
+		FlushInterval:  time.Second,
+		OverflowPolicy: OverflowBlock,
+	}
+}
+
+// Validate 验证异步写日志的配置.
+func (o *AsyncOptions) Validate() []error {
+	if !o.Enabled {
+		return nil
+	}
+
+	var errs []error
+	if o.BufferSize <= 0 {
+		errs = append(errs, fmt.Errorf("async buffer size must be greater than 0, got %d", o.BufferSize))
+	}
+	if o.FlushInterval <= 0 {
+		errs = append(errs, fmt.Errorf("async flush interval must be greater than 0, got %s", o.FlushInterval))
+	}
+	switch o.OverflowPolicy {
+	case OverflowBlock, OverflowDropOldest, OverflowDropDebugFirst:
+	default:
+		errs = append(errs, fmt.Errorf("not a valid async overflow policy: %q", o.OverflowPolicy))
+	}
+
+	return errs
+}
+
+type asyncEntry struct {
+	level zapcore.Level
+	buf   *buffer.Buffer
+}
+
+// asyncWriter 将编码后的日志放入有界环形缓冲区，由后台 goroutine 批量写入输出.
+type asyncWriter struct {
+	out    zapcore.WriteSyncer
+	errOut zapcore.WriteSyncer
+	policy string
+
+	mu      sync.Mutex
+	notFull *sync.Cond
+	entries []asyncEntry
+	head    int
+	size    int
+	dropped atomic.Uint64
+
+	wake chan struct{}
+	sync chan chan error
+
+	closed   bool // 由 mu 保护，关闭后的日志直接写入输出
+	stop     chan struct{}
+	done     chan struct{}
+	closeErr error
+}
+
+func newAsyncWriter(out, errOut zapcore.WriteSyncer, opts AsyncOptions) *asyncWriter {
+	w := &asyncWriter{
+		out:     out,
+		errOut:  errOut,
+		policy:  opts.OverflowPolicy,
+		entries: make([]asyncEntry, opts.BufferSize),
+		wake:    make(chan struct{}, 1),
+		sync:    make(chan chan error),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	w.notFull = sync.NewCond(&w.mu)
+	go w.run(opts.FlushInterval)
+
+	return w
+}
+
+// enqueue 将一条编码后的日志放入缓冲区，缓冲区满时按照溢出策略处理.
+func (w *asyncWriter) enqueue(level zapcore.Level, buf *buffer.Buffer) {
+	w.mu.Lock()
+	for w.size == len(w.entries) && !w.closed {
+		if w.policy == OverflowBlock {
+			w.notFull.Wait()
+
+			continue
+		}
+		if !w.evict(level) {
+			w.mu.Unlock()
+			w.drop(buf)
+
+			return
+		}
+	}
+	if w.closed {
+		w.mu.Unlock()
+		if _, err := w.out.Write(buf.Bytes()); err != nil {
+			w.reportError("write error: %v", err)
+		}
+		buf.Free()
+
+		return
+	}
+	w.entries[(w.head+w.size)%len(w.entries)] = asyncEntry{level: level, buf: buf}
+	w.size++
+	w.mu.Unlock()
+
+	select {
+	case w.wake <- struct{}{}:
+	default:
+	}
+}
+
+// evict 按照溢出策略从缓冲区移除一条日志. 返回 false 表示应该丢弃新的日志.
+func (w *asyncWriter) evict(level zapcore.Level) bool {
+	victim := 0
+	if w.policy == OverflowDropDebugFirst {
+		for i := 1; i < w.size; i++ {
+			if w.at(i).level < w.at(victim).level {
+				victim = i
+			}
+		}
+		if level < w.at(victim).level {
+			return false
+		}
+	}
+
+	w.drop(w.at(victim).buf)
+	for i := victim; i > 0; i-- {
+		w.entries[(w.head+i)%len(w.entries)] = w.at(i - 1)
+	}
+	w.entries[w.head] = asyncEntry{}
+	w.head = (w.head + 1) % len(w.entries)
+	w.size--
+
+	return true
+}
+
+func (w *asyncWriter) at(i int) asyncEntry {
+	return w.entries[(w.head+i)%len(w.entries)]
+}
+
+func (w *asyncWriter) drop(buf *buffer.Buffer) {
+	buf.Free()
+	droppedEntries.Add(1)
+	w.dropped.Add(1)
+}
+
+func (w *asyncWriter) run(interval time.Duration) {
+	defer close(w.done)
+	bw := bufio.NewWriterSize(w.out, asyncWriteBufferSize)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-w.stop:
+			w.drain(bw)
+			w.closeErr = w.flush(bw)
+
+			return
+		case <-w.wake:
+			w.drain(bw)
+		case <-ticker.C:
+			w.drain(bw)
+			_ = w.flush(bw)
+		case done := <-w.sync:
+			w.drain(bw)
+			done <- w.flush(bw)
+		}
+	}
+}
+
+// drain 取出缓冲区中全部日志并写入 bw.
+func (w *asyncWriter) drain(bw *bufio.Writer) {
+	w.mu.Lock()
+	if w.size == 0 {
+		w.mu.Unlock()
+
+		return
+	}
+	pending := make([]asyncEntry, w.size)
+	for i := range pending {
+		pending[i] = w.at(i)
+		w.entries[(w.head+i)%len(w.entries)] = asyncEntry{}
+	}
+	w.head, w.size = 0, 0
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+
+	for _, e := range pending {
+		if _, err := bw.Write(e.buf.Bytes()); err != nil {
+			w.reportError("write error: %v", err)
+		}
+		e.buf.Free()
+	}
+}
+
+func (w *asyncWriter) flush(bw *bufio.Writer) error {
+	if dropped := w.dropped.Swap(0); dropped > 0 {
+		w.reportError("%d entries dropped due to async buffer overflow", dropped)
+	}
+	if err := bw.Flush(); err != nil {
+		w.reportError("write error: %v", err)
+
+		return err
+	}
+
+	return w.out.Sync()
+}
+
+func (w *asyncWriter) reportError(format string, args ...interface{}) {
+	fmt.Fprintf(w.errOut, "%s log: "+format+"\n", append([]interface{}{time.Now().Format(time.RFC3339)}, args...)...)
+	_ = w.errOut.Sync()
+}
+
+// Sync 将缓冲区中的全部日志写入输出并同步，返回时已经写入的日志不会丢失.
+func (w *asyncWriter) Sync() error {
+	done := make(chan error)
+	select {
+	case w.sync <- done:
+		return <-done
+	case <-w.done:
+		return w.out.Sync()
+	}
+}
+
+// Close 将缓冲区中的全部日志写入输出并同步，然后停止后台 goroutine. 关闭后的日志直接写入输出.
+func (w *asyncWriter) Close() error {
+	w.mu.Lock()
+	if w.closed {
+		w.mu.Unlock()
+		<-w.done
+
+		return nil
+	}
+	w.closed = true
+	// 唤醒阻塞在已满缓冲区上的调用方
+	w.notFull.Broadcast()
+	w.mu.Unlock()
+
+	close(w.stop)
+	<-w.done
+
+	return w.closeErr
+}
+
+// asyncCore 在调用方的 goroutine 中编码日志，然后交给 asyncWriter 异步写入.
+type asyncCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	out *asyncWriter
+}
+
+func newAsyncCore(enc zapcore.Encoder, out *asyncWriter, enab zapcore.LevelEnabler) zapcore.Core {
+	return &asyncCore{LevelEnabler: enab, enc: enc, out: out}
+}
+
+func (c *asyncCore) Level() zapcore.Level {
+	return zapcore.LevelOf(c.LevelEnabler)
+}
+
+func (c *asyncCore) With(fields []Field) zapcore.Core {
+	clone := c.enc.Clone()
+	for i := range fields {
+		fields[i].AddTo(clone)
+	}
+
+	return &asyncCore{LevelEnabler: c.LevelEnabler, enc: clone, out: c.out}
+}
+
+func (c *asyncCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *asyncCore) Write(ent zapcore.Entry, fields []Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	c.out.enqueue(ent.Level, buf)
+	if ent.Level > zapcore.ErrorLevel {
+		// 进程可能马上退出，需要同步写入
+		return c.Sync()
+	}
+
+	return nil
+}
+
+func (c *asyncCore) Sync() error {
+	return c.out.Sync()
+}
@@ -0,0 +1,172 @@
+package log
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+func newTestAsyncWriter(size int, policy string) *asyncWriter {
+	w := &asyncWriter{
+		out:     zapcore.AddSync(&bytes.Buffer{}),
+		errOut:  zapcore.AddSync(&bytes.Buffer{}),
+		policy:  policy,
+		entries: make([]asyncEntry, size),
+		wake:    make(chan struct{}, 1),
+		sync:    make(chan chan error),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+	}
+	w.notFull = sync.NewCond(&w.mu)
+
+	return w
+}
+
+func enqueueString(w *asyncWriter, level zapcore.Level, s string) {
+	buf := buffer.NewPool().Get()
+	buf.AppendString(s)
+	w.enqueue(level, buf)
+}
+
+func queued(w *asyncWriter) []string {
+	var out []string
+	for i := 0; i < w.size; i++ {
+		out = append(out, w.at(i).buf.String())
+	}
+
+	return out
+}
+
+func Test_AsyncOverflowPolicy(t *testing.T) {
+	tests := []struct {
+		policy   string
+		expected []string
+	}{
+		{OverflowDropOldest, []string{"info-2", "debug-3", "warn-4"}},
+		{OverflowDropDebugFirst, []string{"info-2", "warn-4", "error-5"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy, func(t *testing.T) {
+			before := DroppedEntries()
+			w := newTestAsyncWriter(3, tt.policy)
+			enqueueString(w, DebugLevel, "debug-1")
+			enqueueString(w, InfoLevel, "info-2")
+			enqueueString(w, DebugLevel, "debug-3")
+			enqueueString(w, WarnLevel, "warn-4")
+			if tt.policy == OverflowDropDebugFirst {
+				enqueueString(w, ErrorLevel, "error-5")
+				// 新日志的级别比缓冲区中全部日志都低时，丢弃新日志
+				enqueueString(w, DebugLevel, "debug-6")
+			}
+
+			assert.Equal(t, tt.expected, queued(w))
+			assert.Equal(t, before+w.dropped.Load(), DroppedEntries())
+			assert.NotZero(t, w.dropped.Load())
+		})
+	}
+}
+
+func Test_AsyncOverflowBlock(t *testing.T) {
+	w := newTestAsyncWriter(1, OverflowBlock)
+	enqueueString(w, InfoLevel, "info-1")
+
+	done := make(chan struct{})
+	go func() {
+		enqueueString(w, InfoLevel, "info-2")
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		t.Fatal("enqueue should block when the buffer is full")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	var out bytes.Buffer
+	w.out = zapcore.AddSync(&out)
+	go w.run(time.Hour)
+	<-done
+	assert.Nil(t, w.Sync())
+	assert.Equal(t, "info-1info-2", out.String())
+}
+
+func Test_AsyncFlush(t *testing.T) {
+	output := filepath.Join(t.TempDir(), "test.log")
+	opts := NewOptions()
+	opts.OutputPaths = []string{output}
+	opts.Async.Enabled = true
+	opts.Async.FlushInterval = time.Hour
+	assert.Empty(t, opts.Validate())
+
+	logger := New(opts)
+	for i := 0; i < 50; i++ {
+		logger.Info("Hello world!", Int("i", i))
+	}
+	logger.Flush()
+
+	data, err := os.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, 50, strings.Count(string(data), "Hello world!"))
+}
+
+// waitGoroutines 等待 goroutine 的数量变为 n，超时返回 false.
+func waitGoroutines(n int) bool {
+	for deadline := time.Now().Add(time.Second); time.Now().Before(deadline); time.Sleep(10 * time.Millisecond) {
+		if runtime.NumGoroutine() == n {
+			return true
+		}
+	}
+
+	return false
+}
+
+func Test_AsyncClose(t *testing.T) {
+	before := runtime.NumGoroutine()
+	output := filepath.Join(t.TempDir(), "test.log")
+	opts := NewOptions()
+	opts.OutputPaths = []string{output}
+	opts.Async.Enabled = true
+	opts.Async.FlushInterval = time.Hour
+
+	for i := 0; i < 3; i++ {
+		Init(opts)
+		Info("Hello world!", Int("i", i))
+	}
+	assert.Nil(t, opts.Build())
+	Info("Hello world!", Int("i", 3))
+	derived := WithName("derived")
+	// 只有当前记录器的异步写入 goroutine 在运行
+	assert.True(t, waitGoroutines(before+1), "goroutines: %d, expected %d", runtime.NumGoroutine(), before+1)
+
+	// 替换记录器后，之前的记录器缓冲的日志已经写入，异步写入的 goroutine 已经退出
+	Init(NewOptions())
+	assert.True(t, waitGoroutines(before), "goroutines: %d, expected %d", runtime.NumGoroutine(), before)
+
+	// 派生的记录器仍然可以写入之前的输出
+	derived.Info("Hello world!", Int("i", 4))
+
+	data, err := os.ReadFile(output)
+	assert.Nil(t, err)
+	assert.Equal(t, 5, strings.Count(string(data), "Hello world!"))
+
+	// 关闭后的日志直接写入输出
+	w := newTestAsyncWriter(1, OverflowBlock)
+	var out bytes.Buffer
+	w.out = zapcore.AddSync(&out)
+	go w.run(time.Hour)
+	enqueueString(w, InfoLevel, "info-1")
+	assert.Nil(t, w.Close())
+	enqueueString(w, InfoLevel, "info-2")
+	assert.Nil(t, w.Sync())
+	assert.Equal(t, "info-1info-2", out.String())
+}
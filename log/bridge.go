@@ -0,0 +1,134 @@
+package log
+
+import (
+	"fmt"
+	"log"
+	"log/slog"
+	"sync"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+
+	"github.com/gzwillyy/components/log/klog"
+)
+
+// BridgeOptions 配置 klog、logr、slog 和标准库 log 如何接入 zap 日志.
+type BridgeOptions struct {
+	InfoVerbosity int    `json:"info-verbosity" mapstructure:"info-verbosity"` // V(n) 中 n 不大于该值时以 Info 级别输出，否则以 Debug 级别输出
+	MaxVerbosity  int    `json:"max-verbosity"  mapstructure:"max-verbosity"`  // V(n) 中 n 大于该值的日志不输出
+	StdLogLevel   string `json:"std-log-level"  mapstructure:"std-log-level"`  // 标准库 log 输出的日志使用的级别
+}
+
+// NewBridgeOptions 创建一个带有默认参数的 BridgeOptions 对象.
+func NewBridgeOptions() BridgeOptions {
+	return BridgeOptions{
+		InfoVerbosity: 4,
+		MaxVerbosity:  10,
+		StdLogLevel:   zapcore.InfoLevel.String(),
+	}
+}
+
+// Validate 验证接入配置.
+func (o *BridgeOptions) Validate() []error {
+	var errs []error
+	if o.InfoVerbosity < 0 {
+		errs = append(errs, fmt.Errorf("info verbosity must not be negative, got %d", o.InfoVerbosity))
+	}
+	if o.MaxVerbosity < o.InfoVerbosity {
+		errs = append(errs, fmt.Errorf("max verbosity %d must not be less than info verbosity %d",
+			o.MaxVerbosity, o.InfoVerbosity))
+	}
+	if _, err := o.stdLogLevel(); err != nil {
+		errs = append(errs, err)
+	}
+
+	return errs
+}
+
+// VerbosityLevel 返回 V(v) 对应的日志级别，第二个返回值为 false 表示该 verbosity 的日志不输出.
+func (o *BridgeOptions) VerbosityLevel(v int) (Level, bool) {
+	switch {
+	case v < 0 || v > o.MaxVerbosity:
+		return zapcore.InvalidLevel, false
+	case v <= o.InfoVerbosity:
+		return zapcore.InfoLevel, true
+	default:
+		return zapcore.DebugLevel, true
+	}
+}
+
+// enabledVerbosity 返回在 core 的级别下能够输出的最大 verbosity，都不能输出时返回 -1.
+func (o *BridgeOptions) enabledVerbosity(core zapcore.LevelEnabler) int {
+	switch {
+	case core.Enabled(zapcore.DebugLevel):
+		return o.MaxVerbosity
+	case core.Enabled(zapcore.InfoLevel):
+		return o.InfoVerbosity
+	default:
+		return -1
+	}
+}
+
+func (o *BridgeOptions) stdLogLevel() (Level, error) {
+	if o.StdLogLevel == "" {
+		return zapcore.InfoLevel, nil
+	}
+	var level Level
+	if err := level.UnmarshalText([]byte(o.StdLogLevel)); err != nil {
+		return level, fmt.Errorf("not a valid std log level: %w", err)
+	}
+
+	return level, nil
+}
+
+var (
+	bridgeMu sync.Mutex
+	// bridge 是当前生效的接入配置，CheckIntLevel 和 logr 适配器根据它映射 verbosity.
+	bridge = NewBridgeOptions()
+	// bridgeLogger 是当前接收重定向日志的记录器.
+	bridgeLogger *zap.Logger
+	// restoreBridges 撤销上一次 installBridges 对 slog 和标准库 log 的重定向.
+	restoreBridges = func() {}
+)
+
+func currentBridge() (*zap.Logger, BridgeOptions) {
+	bridgeMu.Lock()
+	defer bridgeMu.Unlock()
+
+	return bridgeLogger, bridge
+}
+
+// installBridges 将 klog、klog/v2、slog 和标准库 log 的输出统一重定向到 l，
+// 是整个包中唯一进行全局重定向的地方. l 不应该带有额外的 caller skip.
+func installBridges(l *zap.Logger, o BridgeOptions) {
+	stdLevel, err := o.stdLogLevel()
+	if err != nil {
+		stdLevel = zapcore.InfoLevel
+	}
+
+	bridgeMu.Lock()
+	defer bridgeMu.Unlock()
+
+	// 先撤销上一次的重定向，避免重复包装
+	restoreBridges()
+	prevSlog, prevFlags := slog.Default(), log.Flags()
+
+	bridge, bridgeLogger = o, l
+	klog.InitLogger(l)
+	klog.SetLogger(newLogr(l.WithOptions(zap.AddCallerSkip(1)), o))
+	// klog 的输出都以 Info 级别写入，映射为 Debug 级别的 V(n) 不输出，而不是提升为 Info 级别
+	verbosity := o.enabledVerbosity(l.Core())
+	klog.SetVerbosity(min(verbosity, o.InfoVerbosity), verbosity)
+	// slog.SetDefault 同样会接管标准库 log，所以必须在 RedirectStdLogAt 之前调用
+	slog.SetDefault(slog.New(newSlogHandler(l)))
+	restoreStdLog, err := zap.RedirectStdLogAt(l, stdLevel)
+	if err != nil {
+		restoreStdLog = func() {}
+	}
+
+	restoreBridges = func() {
+		slog.SetDefault(prevSlog)
+		restoreStdLog()
+		log.SetFlags(prevFlags)
+	}
+}
@@ -0,0 +1,134 @@
+package log_test
+
+import (
+	"context"
+	stdlog "log"
+	"log/slog"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/klog"
+	klogv2 "k8s.io/klog/v2"
+
+	"github.com/gzwillyy/components/log"
+	"github.com/gzwillyy/components/log/logtest"
+)
+
+func Test_BridgeVerbosityLevel(t *testing.T) {
+	o := log.NewBridgeOptions()
+
+	tests := []struct {
+		v       int
+		level   log.Level
+		enabled bool
+	}{
+		{v: 0, level: log.InfoLevel, enabled: true},
+		{v: 4, level: log.InfoLevel, enabled: true},
+		{v: 5, level: log.DebugLevel, enabled: true},
+		{v: 10, level: log.DebugLevel, enabled: true},
+		{v: 11, enabled: false},
+		{v: -1, enabled: false},
+	}
+	for _, tt := range tests {
+		level, ok := o.VerbosityLevel(tt.v)
+		assert.Equal(t, tt.enabled, ok, "V(%d)", tt.v)
+		if tt.enabled {
+			assert.Equal(t, tt.level, level, "V(%d)", tt.v)
+		}
+	}
+}
+
+func Test_BridgeValidate(t *testing.T) {
+	o := log.NewBridgeOptions()
+	assert.Empty(t, o.Validate())
+
+	o.MaxVerbosity = 2
+	o.StdLogLevel = "verbose"
+	assert.Len(t, o.Validate(), 2)
+}
+
+func Test_BridgeKlog(t *testing.T) {
+	rec := logtest.New(t)
+
+	klog.V(2).Info("from klog")
+	klog.V(6).Info("debug from klog")
+
+	// klog 的输出不包含 verbosity，映射为 Debug 级别的日志不会以 Info 级别输出
+	rec.Level(log.InfoLevel).Message("from klog").AssertCount(1)
+	rec.Message("debug from klog").AssertEmpty()
+}
+
+func Test_BridgeKlogV2(t *testing.T) {
+	rec := logtest.New(t)
+
+	klogv2.V(2).InfoS("from klog v2", "key", "value")
+	klogv2.V(6).Info("debug from klog v2")
+	klogv2.V(11).Info("dropped")
+	klogv2.ErrorS(nil, "error from klog v2")
+
+	rec.Level(log.InfoLevel).Message("from klog v2").FieldValue("key", "value").AssertCount(1)
+	rec.Level(log.DebugLevel).Message("debug from klog v2").AssertCount(1)
+	rec.Message("dropped").AssertEmpty()
+	rec.Level(log.ErrorLevel).Message("error from klog v2").AssertCount(1)
+}
+
+func Test_BridgeLogr(t *testing.T) {
+	rec := logtest.New(t)
+
+	logger := log.Logr().WithName("logr").WithValues("key", "value")
+	logger.V(1).Info("info")
+	logger.V(5).Info("debug")
+	logger.Error(assert.AnError, "failed")
+
+	rec.LoggerName("logr").Level(log.InfoLevel).Message("info").FieldValue("key", "value").AssertCount(1)
+	rec.Level(log.DebugLevel).Message("debug").FieldValue("v", 5).AssertCount(1)
+	rec.Level(log.ErrorLevel).Message("failed").FieldValue("error", assert.AnError.Error()).AssertCount(1)
+	for _, entry := range rec.All() {
+		assert.Equal(t, "bridge_test.go", filepath.Base(entry.Caller.File))
+	}
+}
+
+func Test_BridgeSlog(t *testing.T) {
+	rec := logtest.New(t)
+
+	ctx := log.ContextWithFields(context.Background(), log.String("requestID", "7a7b9f24"))
+	slog.InfoContext(ctx, "info", "key", "value")
+	slog.Debug("debug")
+	slog.Warn("warn")
+	slog.Error("error")
+	slog.Default().WithGroup("req").With("method", "GET").Info("grouped", slog.Group("resp", "status", 200))
+
+	rec.Level(log.InfoLevel).Message("info").FieldValue("key", "value").FieldValue("requestID", "7a7b9f24").AssertCount(1)
+	rec.Level(log.DebugLevel).Message("debug").AssertCount(1)
+	rec.Level(log.WarnLevel).Message("warn").AssertCount(1)
+	rec.Level(log.ErrorLevel).Message("error").AssertCount(1)
+
+	grouped := rec.Message("grouped").All()
+	if assert.Len(t, grouped, 1) {
+		assert.Equal(t, map[string]interface{}{
+			"req": map[string]interface{}{
+				"method": "GET",
+				"resp":   map[string]interface{}{"status": int64(200)},
+			},
+		}, grouped[0].ContextMap())
+	}
+	for _, entry := range rec.All() {
+		assert.Equal(t, "bridge_test.go", filepath.Base(entry.Caller.File))
+	}
+}
+
+func Test_BridgeStdLog(t *testing.T) {
+	rec := logtest.New(t)
+
+	stdlog.Print("from std log")
+
+	rec.Level(log.InfoLevel).Message("from std log").AssertCount(1)
+}
+
+func Test_CheckIntLevel(t *testing.T) {
+	logtest.New(t, logtest.WithLevel(log.InfoLevel))
+
+	assert.True(t, log.CheckIntLevel(4))
+	assert.False(t, log.CheckIntLevel(5))
+}
@@ -0,0 +1,108 @@
+package log
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// encoderConfig 返回 console 和 json 格式使用的编码配置.
+func (o *Options) encoderConfig() zapcore.EncoderConfig {
+	encodeLevel := zapcore.CapitalLevelEncoder
+	// when output to local path, with color is forbidden
+	if strings.EqualFold(o.Format, consoleFormat) && o.EnableColor {
+		encodeLevel = zapcore.CapitalColorLevelEncoder
+	}
+
+	return zapcore.EncoderConfig{
+		MessageKey:     "message",
+		LevelKey:       "level",
+		TimeKey:        "timestamp",
+		NameKey:        "logger",
+		CallerKey:      "caller",
+		StacktraceKey:  "stacktrace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    encodeLevel,
+		EncodeTime:     timeEncoder,
+		EncodeDuration: milliSecondsDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+		EncodeName:     zapcore.FullNameEncoder,
+	}
+}
+
+// buildZapLogger 根据 Options 创建 zap 记录器.
+// 依次组装编码器、输出（同步或异步）、脱敏和采样，extra 会追加在默认选项之后.
+// 返回的 stopAsync 用于在记录器被替换后停止异步写入，之后的日志直接写入输出.
+// 输出不会被关闭，因为派生的记录器和桥接的日志库可能仍在使用它们.
+func (o *Options) buildZapLogger(extra ...zap.Option) (logger *zap.Logger, stopAsync func(), err error) {
+	var zapLevel zapcore.Level
+	if err := zapLevel.UnmarshalText([]byte(o.Level)); err != nil {
+		zapLevel = zapcore.InfoLevel
+	}
+
+	newEncoder, ok := encoders[strings.ToLower(o.Format)]
+	if !ok {
+		return nil, nil, fmt.Errorf("not a valid log format: %q", o.Format)
+	}
+	enc, err := newEncoder(o.encoderConfig())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sink, closeSink, err := zap.Open(o.OutputPaths...)
+	if err != nil {
+		return nil, nil, err
+	}
+	errSink, closeErrSink, err := zap.Open(o.ErrorOutputPaths...)
+	if err != nil {
+		closeSink()
+
+		return nil, nil, err
+	}
+	closeOut := func() {
+		closeSink()
+		closeErrSink()
+	}
+	stopAsync = func() {}
+
+	level := zap.NewAtomicLevelAt(zapLevel)
+	var core zapcore.Core
+	if o.Async.Enabled {
+		writer := newAsyncWriter(sink, errSink, o.Async)
+		core = newAsyncCore(enc, writer, level)
+		stopAsync = func() { _ = writer.Close() }
+	} else {
+		core = zapcore.NewCore(enc, sink, level)
+	}
+
+	// 脱敏需要在采样之前完成（采样 Core 会直接写入底层 Core）
+	if !o.DisableRedaction {
+		r, err := newRedactor(o.RedactKeys, o.RedactPatterns)
+		if err != nil {
+			stopAsync()
+			closeOut()
+
+			return nil, nil, err
+		}
+		if r != nil {
+			core = r.wrapCore(core)
+		}
+	}
+	core = zapcore.NewSamplerWithOptions(core, time.Second, 100, 100)
+
+	zapOpts := []zap.Option{zap.ErrorOutput(errSink)}
+	if o.Development {
+		zapOpts = append(zapOpts, zap.Development())
+	}
+	if !o.DisableCaller {
+		zapOpts = append(zapOpts, zap.AddCaller())
+	}
+	if !o.DisableStacktrace {
+		zapOpts = append(zapOpts, zap.AddStacktrace(zapcore.PanicLevel))
+	}
+
+	return zap.New(core, append(zapOpts, extra...)...), stopAsync, nil
+}
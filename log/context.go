@@ -8,8 +8,12 @@ type key int
 
 const (
 	logContextKey key = iota
+	fieldsContextKey
 )
 
+// contextKeys 是 L 和 FromContext 会自动从 context 中读取的公共键.
+var contextKeys = []ContextKey{KeyRequestID, KeyUsername, KeyWatcherName}
+
 // WithContext 返回设置日志值的上下文副本.
 func WithContext(ctx context.Context) context.Context {
 	return std.WithContext(ctx)
@@ -20,13 +24,88 @@ func (l *zapLogger) WithContext(ctx context.Context) context.Context {
 }
 
 // FromContext 返回 ctx 上日志键的值.
+// 通过 ContextWithFields 保存在 ctx 上的字段会自动添加到返回的记录器中.
 func FromContext(ctx context.Context) Logger {
+	var logger Logger = WithName("Unknown-Context")
 	if ctx != nil {
-		logger := ctx.Value(logContextKey)
-		if logger != nil {
-			return logger.(Logger)
+		if l, ok := ctx.Value(logContextKey).(Logger); ok {
+			logger = l
+		}
+	}
+
+	if zl, ok := logger.(*zapLogger); ok {
+		return zl.withContextFields(ctx)
+	}
+
+	return logger
+}
+
+// ContextWithFields 返回一个附加了日志字段的上下文副本.
+// 多次调用会累加字段，键相同的字段以最后一次设置的值为准.
+// 通过 L(ctx) 或 FromContext(ctx) 获取的记录器会自动包含这些字段.
+func ContextWithFields(ctx context.Context, fields ...Field) context.Context {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if len(fields) == 0 {
+		return ctx
+	}
+
+	existing := FieldsFromContext(ctx)
+	merged := make([]Field, 0, len(existing)+len(fields))
+	for _, f := range existing {
+		if !containsKey(fields, f.Key) {
+			merged = append(merged, f)
+		}
+	}
+	for i, f := range fields {
+		// 同一次调用中重复的键只保留最后一个
+		if !containsKey(fields[i+1:], f.Key) {
+			merged = append(merged, f)
+		}
+	}
+
+	return context.WithValue(ctx, fieldsContextKey, merged)
+}
+
+// FieldsFromContext 返回通过 ContextWithFields 保存在 ctx 上的日志字段.
+func FieldsFromContext(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+	fields, _ := ctx.Value(fieldsContextKey).([]Field)
+
+	return fields
+}
+
+// contextFields 返回 ctx 上需要添加到日志中的全部字段，
+// 包括 ContextWithFields 保存的字段和通过公共键直接设置的值.
+func contextFields(ctx context.Context) []Field {
+	if ctx == nil {
+		return nil
+	}
+
+	stored := FieldsFromContext(ctx)
+	fields := make([]Field, len(stored), len(stored)+len(contextKeys))
+	copy(fields, stored)
+	for _, k := range contextKeys {
+		if containsKey(fields, k.String()) {
+			continue
+		}
+		if v := ctx.Value(k); v != nil {
+			fields = append(fields, Any(k.String(), v))
+		}
+	}
+
+	return fields
+}
+
+func containsKey(fields []Field, key string) bool {
+	for _, f := range fields {
+		if f.Key == key {
+			return true
 		}
 	}
 
-	return WithName("Unknown-Context")
+	return false
 }
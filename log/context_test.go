@@ -0,0 +1,36 @@
+package log_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/gzwillyy/components/log"
+)
+
+func Test_ContextWithFields(t *testing.T) {
+	ctx := log.ContextWithFields(context.Background(), log.String("a", "1"), log.String("b", "2"))
+	ctx = log.ContextWithFields(ctx, log.String("a", "3"))
+
+	fields := log.FieldsFromContext(ctx)
+	assert.Len(t, fields, 2)
+	assert.Equal(t, "b", fields[0].Key)
+	assert.Equal(t, "a", fields[1].Key)
+	assert.Equal(t, "3", fields[1].String)
+}
+
+func Test_FromContext(t *testing.T) {
+	core, logs := observer.New(zap.InfoLevel)
+	ctx := log.NewLogger(zap.New(core)).WithContext(context.Background())
+	ctx = log.ContextWithFields(ctx, log.String("foo", "bar"))
+	ctx = context.WithValue(ctx, log.KeyRequestID, "7a7b9f24")
+
+	log.FromContext(ctx).Info("Hello world!")
+
+	entries := logs.All()
+	assert.Len(t, entries, 1)
+	assert.Equal(t, map[string]interface{}{"foo": "bar", "requestID": "7a7b9f24"}, entries[0].ContextMap())
+}
@@ -1,8 +1,11 @@
 package log
 
 import (
+	"os"
 	"time"
 
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
 	"go.uber.org/zap/zapcore"
 )
 
@@ -13,3 +16,196 @@ func timeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 func milliSecondsDurationEncoder(d time.Duration, enc zapcore.PrimitiveArrayEncoder) {
 	enc.AppendFloat64(float64(d) / float64(time.Millisecond))
 }
+
+// ecsVersion 是输出的 Elastic Common Schema 版本.
+const ecsVersion = "1.6.0"
+
+// gelfVersion 是输出的 Graylog Extended Log Format 版本.
+const gelfVersion = "1.1"
+
+// encoders 是支持的日志格式及其编码器.
+var encoders = map[string]func(zapcore.EncoderConfig) (zapcore.Encoder, error){
+	consoleFormat: func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+		return zapcore.NewConsoleEncoder(cfg), nil
+	},
+	jsonFormat: func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+		return zapcore.NewJSONEncoder(cfg), nil
+	},
+	logfmtFormat: newLogfmtEncoder,
+	ecsFormat:    newECSEncoder,
+	gelfFormat:   newGELFEncoder,
+}
+
+func ecsTimeEncoder(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+	enc.AppendString(t.UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+}
+
+// ecsEncoder 按照 Elastic Common Schema 的字段名输出 JSON 日志.
+type ecsEncoder struct {
+	zapcore.Encoder
+}
+
+func newECSEncoder(_ zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		MessageKey:     "message",
+		LevelKey:       "log.level",
+		TimeKey:        "@timestamp",
+		NameKey:        "log.logger",
+		StacktraceKey:  "error.stack_trace",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    zapcore.LowercaseLevelEncoder,
+		EncodeTime:     ecsTimeEncoder,
+		EncodeDuration: zapcore.NanosDurationEncoder,
+		EncodeName:     zapcore.FullNameEncoder,
+	})
+	enc.AddString("ecs.version", ecsVersion)
+
+	return &ecsEncoder{Encoder: enc}, nil
+}
+
+func (enc *ecsEncoder) Clone() zapcore.Encoder {
+	return &ecsEncoder{Encoder: enc.Encoder.Clone()}
+}
+
+func (enc *ecsEncoder) EncodeEntry(ent zapcore.Entry, fields []Field) (*buffer.Buffer, error) {
+	mapped := make([]Field, 0, len(fields)+1)
+	if ent.Caller.Defined {
+		mapped = append(mapped, zap.Object("log.origin", ecsOrigin(ent.Caller)))
+	}
+	for _, f := range fields {
+		// ECS 中 error 是一个对象，错误信息使用 error.message 字段
+		if f.Type == zapcore.ErrorType && f.Key == "error" {
+			f.Key = "error.message"
+		}
+		mapped = append(mapped, f)
+	}
+
+	return enc.Encoder.EncodeEntry(ent, mapped)
+}
+
+// ecsOrigin 输出 ECS 的 log.origin 字段.
+type ecsOrigin zapcore.EntryCaller
+
+func (o ecsOrigin) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("file.name", zapcore.EntryCaller(o).TrimmedPath())
+	enc.AddInt("file.line", o.Line)
+	if o.Function != "" {
+		enc.AddString("function", o.Function)
+	}
+
+	return nil
+}
+
+// gelfLevelEncoder 将日志级别编码为 GELF 使用的 syslog 级别.
+func gelfLevelEncoder(l zapcore.Level, enc zapcore.PrimitiveArrayEncoder) {
+	switch l {
+	case zapcore.DebugLevel:
+		enc.AppendInt(7)
+	case zapcore.InfoLevel:
+		enc.AppendInt(6)
+	case zapcore.WarnLevel:
+		enc.AppendInt(4)
+	case zapcore.ErrorLevel:
+		enc.AppendInt(3)
+	case zapcore.DPanicLevel:
+		enc.AppendInt(2)
+	case zapcore.PanicLevel:
+		enc.AppendInt(1)
+	case zapcore.FatalLevel:
+		enc.AppendInt(0)
+	default:
+		enc.AppendInt(6)
+	}
+}
+
+// gelfEncoder 按照 GELF 1.1 输出 JSON 日志，附加字段的名称以下划线开头.
+type gelfEncoder struct {
+	zapcore.Encoder
+}
+
+func newGELFEncoder(_ zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{
+		MessageKey:     "short_message",
+		LevelKey:       "level",
+		TimeKey:        "timestamp",
+		NameKey:        "_logger",
+		CallerKey:      "_caller",
+		StacktraceKey:  "full_message",
+		LineEnding:     zapcore.DefaultLineEnding,
+		EncodeLevel:    gelfLevelEncoder,
+		EncodeTime:     zapcore.EpochTimeEncoder,
+		EncodeDuration: milliSecondsDurationEncoder,
+		EncodeCaller:   zapcore.ShortCallerEncoder,
+		EncodeName:     zapcore.FullNameEncoder,
+	})
+	host, err := os.Hostname()
+	if err != nil {
+		host = "unknown"
+	}
+	enc.AddString("version", gelfVersion)
+	enc.AddString("host", host)
+
+	return &gelfEncoder{Encoder: enc}, nil
+}
+
+// gelfKey 返回附加字段在 GELF 中的名称. GELF 不允许使用 _id 作为附加字段.
+func gelfKey(key string) string {
+	if key == "id" {
+		return "_id_"
+	}
+
+	return "_" + key
+}
+
+func (enc *gelfEncoder) Clone() zapcore.Encoder {
+	return &gelfEncoder{Encoder: enc.Encoder.Clone()}
+}
+
+func (enc *gelfEncoder) EncodeEntry(ent zapcore.Entry, fields []Field) (*buffer.Buffer, error) {
+	mapped := make([]Field, len(fields))
+	for i, f := range fields {
+		f.Key = gelfKey(f.Key)
+		mapped[i] = f
+	}
+
+	return enc.Encoder.EncodeEntry(ent, mapped)
+}
+
+func (enc *gelfEncoder) AddArray(k string, v zapcore.ArrayMarshaler) error {
+	return enc.Encoder.AddArray(gelfKey(k), v)
+}
+
+func (enc *gelfEncoder) AddObject(k string, v zapcore.ObjectMarshaler) error {
+	return enc.Encoder.AddObject(gelfKey(k), v)
+}
+
+func (enc *gelfEncoder) AddReflected(k string, v interface{}) error {
+	return enc.Encoder.AddReflected(gelfKey(k), v)
+}
+
+func (enc *gelfEncoder) AddBinary(k string, v []byte)     { enc.Encoder.AddBinary(gelfKey(k), v) }
+func (enc *gelfEncoder) AddByteString(k string, v []byte) { enc.Encoder.AddByteString(gelfKey(k), v) }
+func (enc *gelfEncoder) AddBool(k string, v bool)         { enc.Encoder.AddBool(gelfKey(k), v) }
+func (enc *gelfEncoder) AddComplex128(k string, v complex128) {
+	enc.Encoder.AddComplex128(gelfKey(k), v)
+}
+func (enc *gelfEncoder) AddComplex64(k string, v complex64) { enc.Encoder.AddComplex64(gelfKey(k), v) }
+func (enc *gelfEncoder) AddDuration(k string, v time.Duration) {
+	enc.Encoder.AddDuration(gelfKey(k), v)
+}
+func (enc *gelfEncoder) AddFloat64(k string, v float64) { enc.Encoder.AddFloat64(gelfKey(k), v) }
+func (enc *gelfEncoder) AddFloat32(k string, v float32) { enc.Encoder.AddFloat32(gelfKey(k), v) }
+func (enc *gelfEncoder) AddInt(k string, v int)         { enc.Encoder.AddInt(gelfKey(k), v) }
+func (enc *gelfEncoder) AddInt64(k string, v int64)     { enc.Encoder.AddInt64(gelfKey(k), v) }
+func (enc *gelfEncoder) AddInt32(k string, v int32)     { enc.Encoder.AddInt32(gelfKey(k), v) }
+func (enc *gelfEncoder) AddInt16(k string, v int16)     { enc.Encoder.AddInt16(gelfKey(k), v) }
+func (enc *gelfEncoder) AddInt8(k string, v int8)       { enc.Encoder.AddInt8(gelfKey(k), v) }
+func (enc *gelfEncoder) AddString(k, v string)          { enc.Encoder.AddString(gelfKey(k), v) }
+func (enc *gelfEncoder) AddTime(k string, v time.Time)  { enc.Encoder.AddTime(gelfKey(k), v) }
+func (enc *gelfEncoder) AddUint(k string, v uint)       { enc.Encoder.AddUint(gelfKey(k), v) }
+func (enc *gelfEncoder) AddUint64(k string, v uint64)   { enc.Encoder.AddUint64(gelfKey(k), v) }
+func (enc *gelfEncoder) AddUint32(k string, v uint32)   { enc.Encoder.AddUint32(gelfKey(k), v) }
+func (enc *gelfEncoder) AddUint16(k string, v uint16)   { enc.Encoder.AddUint16(gelfKey(k), v) }
+func (enc *gelfEncoder) AddUint8(k string, v uint8)     { enc.Encoder.AddUint8(gelfKey(k), v) }
+func (enc *gelfEncoder) AddUintptr(k string, v uintptr) { enc.Encoder.AddUintptr(gelfKey(k), v) }
+func (enc *gelfEncoder) OpenNamespace(k string)         { enc.Encoder.OpenNamespace(gelfKey(k)) }
@@ -0,0 +1,65 @@
+package log_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/goccy/go-json"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/gzwillyy/components/log"
+)
+
+func writeLog(t *testing.T, format string) string {
+	t.Helper()
+
+	output := filepath.Join(t.TempDir(), "test.log")
+	opts := log.NewOptions()
+	opts.Format = format
+	opts.OutputPaths = []string{output}
+	assert.Empty(t, opts.Validate())
+
+	logger := log.New(opts)
+	logger.WithValues("id", 1).Info("Hello world!", log.String("key", "two words"))
+	logger.Flush()
+
+	data, err := os.ReadFile(output)
+	assert.Nil(t, err)
+
+	return strings.TrimSpace(string(data))
+}
+
+func Test_LogfmtEncoder(t *testing.T) {
+	line := writeLog(t, "logfmt")
+
+	assert.True(t, strings.HasPrefix(line, "time="))
+	assert.Contains(t, line, " level=info ")
+	assert.Contains(t, line, ` msg="Hello world!" id=1 key="two words"`)
+}
+
+func Test_ECSEncoder(t *testing.T) {
+	var entry map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(writeLog(t, "ecs")), &entry))
+
+	assert.Equal(t, "info", entry["log.level"])
+	assert.Equal(t, "Hello world!", entry["message"])
+	assert.Equal(t, "1.6.0", entry["ecs.version"])
+	assert.Contains(t, entry, "@timestamp")
+	assert.Contains(t, entry["log.origin"], "file.name")
+	assert.Equal(t, "two words", entry["key"])
+}
+
+func Test_GELFEncoder(t *testing.T) {
+	var entry map[string]interface{}
+	assert.Nil(t, json.Unmarshal([]byte(writeLog(t, "gelf")), &entry))
+
+	assert.Equal(t, "1.1", entry["version"])
+	assert.Equal(t, "Hello world!", entry["short_message"])
+	assert.Equal(t, float64(6), entry["level"])
+	assert.IsType(t, float64(0), entry["timestamp"])
+	assert.Contains(t, entry, "host")
+	assert.Equal(t, float64(1), entry["_id_"])
+	assert.Equal(t, "two words", entry["_key"])
+}
@@ -47,6 +47,12 @@ func main() {
 	lv.Infof("Start to call pirntString function")
 	ctx := lv.WithContext(context.Background())
 	pirntString(ctx, "World")
+
+	// ContextWithFields使用
+	ctx = log.ContextWithFields(context.Background(), log.String(log.KeyRequestID.String(), "7a7b9f24"))
+	ctx = log.ContextWithFields(ctx, log.String(log.KeyUsername.String(), "admin"))
+	log.L(ctx).Info("Message printed with [ContextWithFields] fields")
+	pirntString(ctx, "Fields")
 }
 
 func pirntString(ctx context.Context, str string) {
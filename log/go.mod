@@ -3,11 +3,13 @@ module github.com/gzwillyy/components/log
 go 1.21.8
 
 require (
+	github.com/go-logr/logr v1.4.1
 	github.com/goccy/go-json v0.10.2
 	github.com/spf13/pflag v1.0.5
 	github.com/stretchr/testify v1.9.0
 	go.uber.org/zap v1.27.0
 	k8s.io/klog v1.0.0
+	k8s.io/klog/v2 v2.120.1
 )
 
 require (
@@ -3,12 +3,16 @@ package klog
 
 import (
 	"flag"
+	"strconv"
 
+	"github.com/go-logr/logr"
 	"go.uber.org/zap"
 	"k8s.io/klog"
+	klogv2 "k8s.io/klog/v2"
 )
 
-// InitLogger 通过 zap logger 初始化 klog.
+// InitLogger 通过 zap logger 初始化 klog. klog 的输出中不包含 V(n) 中的 n，
+// 除 Warning、Error 和 Fatal 外的日志都以 Info 级别输出.
 func InitLogger(zapLogger *zap.Logger) {
 	fs := flag.NewFlagSet("klog", flag.ExitOnError)
 	klog.InitFlags(fs)
@@ -21,6 +25,25 @@ func InitLogger(zapLogger *zap.Logger) {
 	_ = fs.Set("logtostderr", "false")
 }
 
+// SetLogger 将 klog/v2 的输出交给 logger 处理. logger 需要自己根据 verbosity 判断是否输出，
+// klog/v2 只负责把 V(n) 中的 n 传递给 logger.
+func SetLogger(logger logr.Logger) {
+	klogv2.SetLoggerWithOptions(logger, klogv2.ContextualLogger(true))
+}
+
+// SetVerbosity 分别设置 klog 和 klog/v2 的 -v 参数，V(n) 中 n 大于 -v 的日志不会输出.
+func SetVerbosity(v1, v2 int) {
+	fs := flag.NewFlagSet("klog", flag.ContinueOnError)
+	klog.InitFlags(fs)
+	_ = fs.Set("v", strconv.Itoa(v1))
+	// klog.InitFlags 会用 add_dir_header 的值覆盖 skip_headers，需要重新设置
+	_ = fs.Set("skip_headers", "true")
+
+	fsv2 := flag.NewFlagSet("klog/v2", flag.ContinueOnError)
+	klogv2.InitFlags(fsv2)
+	_ = fsv2.Set("v", strconv.Itoa(v2))
+}
+
 type infoLogger struct {
 	logger *zap.Logger
 }
@@ -8,8 +8,6 @@ import (
 
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
-
-	"github.com/gzwillyy/components/log/klog"
 )
 
 // InfoLogger 表示以特定的详细程度记录非错误消息的能力.
@@ -81,6 +79,9 @@ type zapLogger struct {
 	// 注意：这看起来与 zap.SugaredLogger 非常相似，但它满足了我们对多个详细级别的需求.
 	zapLogger *zap.Logger
 	infoLogger
+
+	// stopAsync 停止 New 和 Options.Build 启动的异步写入，在记录器被替换后调用.
+	stopAsync func()
 }
 
 // noopInfoLogger 是一个 logr.InfoLogger，它总是被禁用，什么都不做.
@@ -168,72 +169,64 @@ func handleFields(l *zap.Logger, args []interface{}, additional ...zap.Field) []
 }
 
 // New 通过 opts 新建记录器，可以通过命令参数自定义.
+// klog、klog/v2、slog 和标准库 log 会按照 opts.Bridge 重定向到新建的记录器.
 func New(opts *Options) *zapLogger {
 	if opts == nil {
 		opts = NewOptions()
 	}
 
-	var zapLevel zapcore.Level
-	if err := zapLevel.UnmarshalText([]byte(opts.Level)); err != nil {
-		zapLevel = zapcore.InfoLevel
-	}
-	encodeLevel := zapcore.CapitalLevelEncoder
-	// when output to local path, with color is forbidden
-	if opts.Format == consoleFormat && opts.EnableColor {
-		encodeLevel = zapcore.CapitalColorLevelEncoder
+	l, stopAsync, err := opts.buildZapLogger()
+	if err != nil {
+		panic(err)
 	}
+	installBridges(l, opts.Bridge)
 
-	encoderConfig := zapcore.EncoderConfig{
-		MessageKey:     "message",
-		LevelKey:       "level",
-		TimeKey:        "timestamp",
-		NameKey:        "logger",
-		CallerKey:      "caller",
-		StacktraceKey:  "stacktrace",
-		LineEnding:     zapcore.DefaultLineEnding,
-		EncodeLevel:    encodeLevel,
-		EncodeTime:     timeEncoder,
-		EncodeDuration: milliSecondsDurationEncoder,
-		EncodeCaller:   zapcore.ShortCallerEncoder,
-	}
+	logger := newZapLogger(l.WithOptions(zap.AddCallerSkip(1)), opts.Name)
+	logger.stopAsync = stopAsync
 
-	loggerConfig := &zap.Config{
-		Level:             zap.NewAtomicLevelAt(zapLevel),
-		Development:       opts.Development,
-		DisableCaller:     opts.DisableCaller,
-		DisableStacktrace: opts.DisableStacktrace,
-		Sampling: &zap.SamplingConfig{
-			Initial:    100,
-			Thereafter: 100,
-		},
-		Encoding:         opts.Format,
-		EncoderConfig:    encoderConfig,
-		OutputPaths:      opts.OutputPaths,
-		ErrorOutputPaths: opts.ErrorOutputPaths,
-	}
+	return logger
+}
 
-	var err error
-	l, err := loggerConfig.Build(zap.AddStacktrace(zapcore.PanicLevel), zap.AddCallerSkip(1))
-	if err != nil {
-		panic(err)
-	}
-	logger := &zapLogger{
-		zapLogger: l.Named(opts.Name),
+// newZapLogger 创建 zapLogger，l 的 caller skip 需要已经包含 zapLogger 方法的一层调用.
+func newZapLogger(l *zap.Logger, name string) *zapLogger {
+	return &zapLogger{
+		zapLogger: l.Named(name),
 		infoLogger: infoLogger{
 			log:   l,
 			level: zap.InfoLevel,
 		},
 	}
-	klog.InitLogger(l)
-	zap.RedirectStdLog(l)
-
-	return logger
 }
 
 func Init(opts *Options) {
 	mu.Lock()
 	defer mu.Unlock()
+	prev := std
 	std = New(opts)
+	// 确保之前的记录器中缓冲的日志都已经输出，然后停止它的异步写入
+	prev.close()
+}
+
+// ReplaceGlobals 使用给定的 zap 记录器替换全局记录器，同时将 klog、slog 和标准库 log 重定向到该记录器.
+// l 需要带有 zap.AddCallerSkip(1)，与 New 创建的记录器保持一致.
+// 返回的函数用于恢复之前的全局记录器，主要用于测试.
+func ReplaceGlobals(l *zap.Logger) func() {
+	prevBridgeLogger, o := currentBridge()
+
+	mu.Lock()
+	prev := std
+	std = newZapLogger(l, "")
+	mu.Unlock()
+
+	installBridges(l.WithOptions(zap.AddCallerSkip(-1)), o)
+
+	return func() {
+		mu.Lock()
+		std = prev
+		mu.Unlock()
+
+		installBridges(prevBridgeLogger, o)
+	}
 }
 
 var (
@@ -312,6 +305,14 @@ func (l *zapLogger) Flush() {
 	_ = l.zapLogger.Sync()
 }
 
+// close 刷新缓冲的日志，然后停止异步写入. 输出保持打开，派生的记录器在 close 之后仍然可以使用.
+func (l *zapLogger) close() {
+	l.Flush()
+	if l.stopAsync != nil {
+		l.stopAsync()
+	}
+}
+
 // NewLogger 创建一个新的logr.Logger，使用给定的Zap Logger进行日志记录.
 func NewLogger(l *zap.Logger) Logger {
 	return &zapLogger{
@@ -329,16 +330,12 @@ func ZapLogger() *zap.Logger {
 }
 
 // CheckIntLevel 用于其他日志包装器，如klog，如果启用了在指定级别记录消息，则会返回该日志包装器.
+// level 是 klog 风格的 verbosity，按照 BridgeOptions 映射为日志级别.
 func CheckIntLevel(level int32) bool {
-	var lvl zapcore.Level
-	if level < 5 {
-		lvl = zapcore.InfoLevel
-	} else {
-		lvl = zapcore.DebugLevel
-	}
-	checkEntry := std.zapLogger.Check(lvl, "")
+	_, o := currentBridge()
+	lvl, ok := o.VerbosityLevel(int(level))
 
-	return checkEntry != nil
+	return ok && std.zapLogger.Core().Enabled(lvl)
 }
 
 // Debug 调试方法输出调试级别日志.
@@ -504,21 +501,21 @@ func (l *zapLogger) Fatalw(msg string, keysAndValues ...interface{}) {
 }
 
 // L 具有指定上下文值的方法输出。
+// 通过 ContextWithFields 保存的字段以及 KeyRequestID 等公共键对应的值会被添加到日志中.
 func L(ctx context.Context) *zapLogger {
 	return std.L(ctx)
 }
 
 func (l *zapLogger) L(ctx context.Context) *zapLogger {
-	lg := l.clone()
+	return l.withContextFields(ctx)
+}
 
-	if requestID := ctx.Value(KeyRequestID); requestID != nil {
-		lg.zapLogger = lg.zapLogger.With(zap.Any(KeyRequestID, requestID))
-	}
-	if username := ctx.Value(KeyUsername); username != nil {
-		lg.zapLogger = lg.zapLogger.With(zap.Any(KeyUsername, username))
-	}
-	if watcherName := ctx.Value(KeyWatcherName); watcherName != nil {
-		lg.zapLogger = lg.zapLogger.With(zap.Any(KeyWatcherName, watcherName))
+// withContextFields 返回一个包含 ctx 上日志字段的记录器副本.
+func (l *zapLogger) withContextFields(ctx context.Context) *zapLogger {
+	lg := l.clone()
+	if fields := contextFields(ctx); len(fields) > 0 {
+		lg.zapLogger = lg.zapLogger.With(fields...)
+		lg.infoLogger.log = lg.zapLogger
 	}
 
 	return lg
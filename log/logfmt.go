@@ -0,0 +1,223 @@
+package log
+
+import (
+	"encoding/base64"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/goccy/go-json"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+)
+
+// logfmtTimeLayout 是 logfmt 格式的时间格式，即精确到毫秒的 RFC3339.
+const logfmtTimeLayout = "2006-01-02T15:04:05.000Z07:00"
+
+var logfmtPool = buffer.NewPool()
+
+// logfmtEncoder 将日志编码为 logfmt 格式，例如：
+// time=2020-12-05T08:12:02.324+08:00 level=info caller=example/example.go:43 msg="This is a info message" int_key=10
+// 嵌套的对象、数组和反射类型会被编码为 JSON 字符串.
+type logfmtEncoder struct {
+	buf       *buffer.Buffer
+	namespace string
+}
+
+func newLogfmtEncoder(_ zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	return &logfmtEncoder{buf: logfmtPool.Get()}, nil
+}
+
+func (enc *logfmtEncoder) Clone() zapcore.Encoder {
+	return enc.clone()
+}
+
+func (enc *logfmtEncoder) clone() *logfmtEncoder {
+	clone := &logfmtEncoder{buf: logfmtPool.Get(), namespace: enc.namespace}
+	_, _ = clone.buf.Write(enc.buf.Bytes())
+
+	return clone
+}
+
+func (enc *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []Field) (*buffer.Buffer, error) {
+	final := enc.clone()
+	defer final.buf.Free()
+	for i := range fields {
+		fields[i].AddTo(final)
+	}
+
+	line := logfmtPool.Get()
+	line.AppendString("time=")
+	line.AppendString(ent.Time.Format(logfmtTimeLayout))
+	line.AppendString(" level=")
+	line.AppendString(ent.Level.String())
+	if ent.LoggerName != "" {
+		line.AppendString(" logger=")
+		appendLogfmtString(line, ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		line.AppendString(" caller=")
+		appendLogfmtString(line, ent.Caller.TrimmedPath())
+	}
+	line.AppendString(" msg=")
+	appendLogfmtString(line, ent.Message)
+	if final.buf.Len() > 0 {
+		line.AppendByte(' ')
+		_, _ = line.Write(final.buf.Bytes())
+	}
+	if ent.Stack != "" {
+		line.AppendString(" stacktrace=")
+		appendLogfmtString(line, ent.Stack)
+	}
+	line.AppendString(zapcore.DefaultLineEnding)
+
+	return line, nil
+}
+
+func (enc *logfmtEncoder) addKey(key string) {
+	if enc.buf.Len() > 0 {
+		enc.buf.AppendByte(' ')
+	}
+	key = strings.Map(func(r rune) rune {
+		if r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError {
+			return '_'
+		}
+
+		return r
+	}, enc.namespace+key)
+	if key == "" {
+		key = "_"
+	}
+	enc.buf.AppendString(key)
+	enc.buf.AppendByte('=')
+}
+
+func (enc *logfmtEncoder) addJSON(key string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	enc.addKey(key)
+	appendLogfmtString(enc.buf, string(data))
+
+	return nil
+}
+
+func (enc *logfmtEncoder) AddArray(key string, arr zapcore.ArrayMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := m.AddArray(key, arr); err != nil {
+		return err
+	}
+
+	return enc.addJSON(key, m.Fields[key])
+}
+
+func (enc *logfmtEncoder) AddObject(key string, obj zapcore.ObjectMarshaler) error {
+	m := zapcore.NewMapObjectEncoder()
+	if err := obj.MarshalLogObject(m); err != nil {
+		return err
+	}
+
+	return enc.addJSON(key, m.Fields)
+}
+
+func (enc *logfmtEncoder) AddReflected(key string, obj interface{}) error {
+	return enc.addJSON(key, obj)
+}
+
+func (enc *logfmtEncoder) AddBinary(key string, val []byte) {
+	enc.AddString(key, base64.StdEncoding.EncodeToString(val))
+}
+
+func (enc *logfmtEncoder) AddByteString(key string, val []byte) {
+	enc.AddString(key, string(val))
+}
+
+func (enc *logfmtEncoder) AddBool(key string, val bool) {
+	enc.addKey(key)
+	enc.buf.AppendBool(val)
+}
+
+func (enc *logfmtEncoder) AddComplex128(key string, val complex128) {
+	enc.addKey(key)
+	enc.buf.AppendString(strconv.FormatComplex(val, 'g', -1, 128))
+}
+
+func (enc *logfmtEncoder) AddComplex64(key string, val complex64) {
+	enc.addKey(key)
+	enc.buf.AppendString(strconv.FormatComplex(complex128(val), 'g', -1, 64))
+}
+
+func (enc *logfmtEncoder) AddDuration(key string, val time.Duration) {
+	enc.addKey(key)
+	enc.buf.AppendString(val.String())
+}
+
+func (enc *logfmtEncoder) AddFloat64(key string, val float64) {
+	enc.addKey(key)
+	enc.buf.AppendFloat(val, 64)
+}
+
+func (enc *logfmtEncoder) AddFloat32(key string, val float32) {
+	enc.addKey(key)
+	enc.buf.AppendFloat(float64(val), 32)
+}
+
+func (enc *logfmtEncoder) AddInt64(key string, val int64) {
+	enc.addKey(key)
+	enc.buf.AppendInt(val)
+}
+
+func (enc *logfmtEncoder) AddUint64(key string, val uint64) {
+	enc.addKey(key)
+	enc.buf.AppendUint(val)
+}
+
+func (enc *logfmtEncoder) AddString(key, val string) {
+	enc.addKey(key)
+	appendLogfmtString(enc.buf, val)
+}
+
+func (enc *logfmtEncoder) AddTime(key string, val time.Time) {
+	enc.addKey(key)
+	enc.buf.AppendString(val.Format(logfmtTimeLayout))
+}
+
+// OpenNamespace 在 logfmt 中没有嵌套结构，之后的字段名会以 "key." 作为前缀.
+func (enc *logfmtEncoder) OpenNamespace(key string) {
+	enc.namespace += key + "."
+}
+
+func (enc *logfmtEncoder) AddInt(k string, v int)         { enc.AddInt64(k, int64(v)) }
+func (enc *logfmtEncoder) AddInt32(k string, v int32)     { enc.AddInt64(k, int64(v)) }
+func (enc *logfmtEncoder) AddInt16(k string, v int16)     { enc.AddInt64(k, int64(v)) }
+func (enc *logfmtEncoder) AddInt8(k string, v int8)       { enc.AddInt64(k, int64(v)) }
+func (enc *logfmtEncoder) AddUint(k string, v uint)       { enc.AddUint64(k, uint64(v)) }
+func (enc *logfmtEncoder) AddUint32(k string, v uint32)   { enc.AddUint64(k, uint64(v)) }
+func (enc *logfmtEncoder) AddUint16(k string, v uint16)   { enc.AddUint64(k, uint64(v)) }
+func (enc *logfmtEncoder) AddUint8(k string, v uint8)     { enc.AddUint64(k, uint64(v)) }
+func (enc *logfmtEncoder) AddUintptr(k string, v uintptr) { enc.AddUint64(k, uint64(v)) }
+
+// appendLogfmtString 写入一个 logfmt 值，包含空格、等号、引号或控制字符时使用双引号.
+func appendLogfmtString(buf *buffer.Buffer, s string) {
+	if !needsLogfmtQuote(s) {
+		buf.AppendString(s)
+
+		return
+	}
+	buf.AppendString(strconv.Quote(s))
+}
+
+func needsLogfmtQuote(s string) bool {
+	if s == "" {
+		return true
+	}
+	if !utf8.ValidString(s) {
+		return true
+	}
+
+	return strings.IndexFunc(s, func(r rune) bool {
+		return r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f
+	}) >= 0
+}
@@ -0,0 +1,65 @@
+package log
+
+import (
+	"github.com/go-logr/logr"
+	"go.uber.org/zap"
+)
+
+// logrSink 是使用 zap 记录日志的 logr.LogSink，V(n) 按照 BridgeOptions 映射为日志级别.
+type logrSink struct {
+	l      *zap.Logger
+	bridge BridgeOptions
+}
+
+// newLogr 创建一个写入 l 的 logr.Logger. l 的 caller skip 需要已经包含 logrSink 自身的一层调用.
+func newLogr(l *zap.Logger, o BridgeOptions) logr.Logger {
+	return logr.New(&logrSink{l: l, bridge: o})
+}
+
+// Logr 返回写入全局记录器的 logr.Logger，可以交给使用 logr 的第三方库，例如 controller-runtime.
+func Logr() logr.Logger { return std.Logr() }
+
+// Logr 返回写入该记录器的 logr.Logger.
+func (l *zapLogger) Logr() logr.Logger {
+	_, o := currentBridge()
+
+	return newLogr(l.zapLogger, o)
+}
+
+func (s *logrSink) Init(info logr.RuntimeInfo) {
+	s.l = s.l.WithOptions(zap.AddCallerSkip(info.CallDepth))
+}
+
+func (s *logrSink) Enabled(level int) bool {
+	lvl, ok := s.bridge.VerbosityLevel(level)
+
+	return ok && s.l.Core().Enabled(lvl)
+}
+
+func (s *logrSink) Info(level int, msg string, keysAndValues ...interface{}) {
+	lvl, ok := s.bridge.VerbosityLevel(level)
+	if !ok {
+		return
+	}
+	if ce := s.l.Check(lvl, msg); ce != nil {
+		ce.Write(handleFields(s.l, keysAndValues, zap.Int("v", level))...)
+	}
+}
+
+func (s *logrSink) Error(err error, msg string, keysAndValues ...interface{}) {
+	if ce := s.l.Check(zap.ErrorLevel, msg); ce != nil {
+		ce.Write(handleFields(s.l, keysAndValues, zap.Error(err))...)
+	}
+}
+
+func (s *logrSink) WithValues(keysAndValues ...interface{}) logr.LogSink {
+	return &logrSink{l: s.l.With(handleFields(s.l, keysAndValues)...), bridge: s.bridge}
+}
+
+func (s *logrSink) WithName(name string) logr.LogSink {
+	return &logrSink{l: s.l.Named(name), bridge: s.bridge}
+}
+
+func (s *logrSink) WithCallDepth(depth int) logr.LogSink {
+	return &logrSink{l: s.l.WithOptions(zap.AddCallerSkip(depth)), bridge: s.bridge}
+}
@@ -0,0 +1,217 @@
+// Package logtest 提供用于测试的日志记录器，可以捕获通过 log 包输出的日志并对其进行断言.
+//
+//	func TestSomething(t *testing.T) {
+//		rec := logtest.New(t)
+//
+//		doSomething()
+//
+//		rec.Level(log.ErrorLevel).Message("something failed").AssertCount(1)
+//	}
+package logtest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"go.uber.org/zap/zaptest/observer"
+
+	"github.com/gzwillyy/components/log"
+)
+
+// Entry 是捕获到的一条日志.
+type Entry = observer.LoggedEntry
+
+// Option 用于配置 Recorder.
+type Option func(*options)
+
+type options struct {
+	level zapcore.LevelEnabler
+}
+
+// WithLevel 设置 Recorder 捕获的最低日志级别，默认捕获全部级别.
+func WithLevel(level log.Level) Option {
+	return func(o *options) {
+		o.level = level
+	}
+}
+
+// Recorder 将日志记录在内存中.
+type Recorder struct {
+	*Entries
+
+	logger *zap.Logger
+}
+
+// New 创建一个 Recorder，并用它替换全局记录器. klog、slog 和标准库 log 的输出也会被重定向到 Recorder.
+// 测试结束时会通过 t.Cleanup 自动恢复之前的全局记录器.
+// 由于替换的是全局记录器，使用 New 的测试不能并行执行.
+func New(t testing.TB, opts ...Option) *Recorder {
+	t.Helper()
+
+	rec := NewRecorder(t, opts...)
+	t.Cleanup(log.ReplaceGlobals(rec.logger))
+
+	return rec
+}
+
+// NewRecorder 创建一个 Recorder，但不替换全局记录器.
+// 可以通过 Logger 或 Context 方法将记录器注入到被测试的代码中.
+func NewRecorder(t testing.TB, opts ...Option) *Recorder {
+	o := &options{level: zapcore.DebugLevel}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	core, logs := observer.New(o.level)
+	logger := zap.New(core,
+		zap.AddCaller(),
+		zap.AddCallerSkip(1),
+		// Fatal 日志不退出进程，而是 panic，以便测试可以捕获
+		zap.WithFatalHook(zapcore.WriteThenPanic),
+	)
+
+	return &Recorder{
+		Entries: &Entries{t: t, logs: logs},
+		logger:  logger,
+	}
+}
+
+// Logger 返回写入 Recorder 的记录器.
+func (r *Recorder) Logger() log.Logger {
+	return log.NewLogger(r.logger)
+}
+
+// Context 返回一个保存了 Recorder 记录器的上下文，log.FromContext 会从中取出该记录器.
+func (r *Recorder) Context(ctx context.Context) context.Context {
+	return r.Logger().WithContext(ctx)
+}
+
+// ZapLogger 返回写入 Recorder 的 zap 记录器.
+func (r *Recorder) ZapLogger() *zap.Logger {
+	return r.logger
+}
+
+// Reset 清空已捕获的日志.
+func (r *Recorder) Reset() {
+	r.logs.TakeAll()
+}
+
+// Entries 是一组捕获到的日志，可以继续过滤或断言.
+type Entries struct {
+	t    testing.TB
+	logs *observer.ObservedLogs
+}
+
+func (e *Entries) filter(fn func(Entry) bool) *Entries {
+	return &Entries{t: e.t, logs: e.logs.Filter(fn)}
+}
+
+// Level 返回指定级别的日志.
+func (e *Entries) Level(level log.Level) *Entries {
+	return &Entries{t: e.t, logs: e.logs.FilterLevelExact(level)}
+}
+
+// Message 返回消息与 msg 完全相同的日志.
+func (e *Entries) Message(msg string) *Entries {
+	return &Entries{t: e.t, logs: e.logs.FilterMessage(msg)}
+}
+
+// MessageContains 返回消息包含 substr 的日志.
+func (e *Entries) MessageContains(substr string) *Entries {
+	return &Entries{t: e.t, logs: e.logs.FilterMessageSnippet(substr)}
+}
+
+// LoggerName 返回记录器名称为 name 的日志.
+func (e *Entries) LoggerName(name string) *Entries {
+	return e.filter(func(entry Entry) bool {
+		return entry.LoggerName == name
+	})
+}
+
+// Field 返回包含指定字段的日志，包括通过 WithValues 或 context 添加的字段.
+func (e *Entries) Field(field log.Field) *Entries {
+	return &Entries{t: e.t, logs: e.logs.FilterField(field)}
+}
+
+// FieldKey 返回包含指定字段名的日志.
+func (e *Entries) FieldKey(key string) *Entries {
+	return &Entries{t: e.t, logs: e.logs.FilterFieldKey(key)}
+}
+
+// FieldValue 返回包含字段 key 且其值等于 value 的日志.
+// 与 Field 不同，FieldValue 不要求字段类型完全一致，适用于通过 Infow 等方法输出的字段.
+func (e *Entries) FieldValue(key string, value interface{}) *Entries {
+	return e.filter(func(entry Entry) bool {
+		v, ok := entry.ContextMap()[key]
+
+		return ok && assert.ObjectsAreEqualValues(value, v)
+	})
+}
+
+// All 返回全部日志.
+func (e *Entries) All() []Entry {
+	return e.logs.All()
+}
+
+// Len 返回日志的数量.
+func (e *Entries) Len() int {
+	return e.logs.Len()
+}
+
+// Messages 返回全部日志的消息.
+func (e *Entries) Messages() []string {
+	entries := e.logs.All()
+	messages := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		messages = append(messages, entry.Message)
+	}
+
+	return messages
+}
+
+// AssertCount 断言日志数量等于 n.
+func (e *Entries) AssertCount(n int) bool {
+	e.t.Helper()
+
+	if got := e.Len(); got != n {
+		e.t.Errorf("expected %d log entries, got %d:\n%s", n, got, e.dump())
+
+		return false
+	}
+
+	return true
+}
+
+// AssertEmpty 断言没有日志.
+func (e *Entries) AssertEmpty() bool {
+	e.t.Helper()
+
+	return e.AssertCount(0)
+}
+
+// AssertNotEmpty 断言至少有一条日志.
+func (e *Entries) AssertNotEmpty() bool {
+	e.t.Helper()
+
+	if e.Len() == 0 {
+		e.t.Errorf("expected at least one log entry, got none")
+
+		return false
+	}
+
+	return true
+}
+
+func (e *Entries) dump() string {
+	var b strings.Builder
+	for _, entry := range e.logs.All() {
+		fmt.Fprintf(&b, "\t%s\t%s\t%v\n", entry.Level.CapitalString(), entry.Message, entry.ContextMap())
+	}
+
+	return b.String()
+}
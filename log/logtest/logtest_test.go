@@ -0,0 +1,80 @@
+package logtest_test
+
+import (
+	"context"
+	stdlog "log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"k8s.io/klog"
+
+	"github.com/gzwillyy/components/log"
+	"github.com/gzwillyy/components/log/logtest"
+)
+
+func Test_Recorder(t *testing.T) {
+	rec := logtest.New(t)
+
+	log.Info("Hello world!", log.String("foo", "bar"))
+	log.Warnw("Hello warn!", "count", 2)
+	log.WithName("test").Error("Hello error!")
+
+	rec.AssertCount(3)
+	rec.Level(log.InfoLevel).Message("Hello world!").Field(log.String("foo", "bar")).AssertCount(1)
+	rec.FieldValue("count", 2).AssertCount(1)
+	rec.LoggerName("test").Level(log.ErrorLevel).AssertCount(1)
+	rec.MessageContains("Hello").AssertCount(3)
+	assert.Equal(t, []string{"Hello world!", "Hello warn!", "Hello error!"}, rec.Messages())
+
+	rec.Reset()
+	rec.AssertEmpty()
+}
+
+func Test_RecorderLevel(t *testing.T) {
+	rec := logtest.New(t, logtest.WithLevel(log.WarnLevel))
+
+	log.Debug("debug")
+	log.Info("info")
+	log.Warn("warn")
+
+	rec.AssertCount(1)
+}
+
+func Test_RecorderContext(t *testing.T) {
+	rec := logtest.New(t)
+
+	ctx := log.ContextWithFields(context.Background(), log.String(log.KeyRequestID.String(), "7a7b9f24"))
+	log.FromContext(ctx).Info("from context")
+	log.L(ctx).Info("from L")
+
+	rec.FieldValue(log.KeyRequestID.String(), "7a7b9f24").AssertCount(2)
+}
+
+func Test_RecorderRedirect(t *testing.T) {
+	rec := logtest.New(t)
+
+	klog.Info("from klog")
+	stdlog.Print("from std log")
+
+	rec.Message("from klog").AssertCount(1)
+	rec.Message("from std log").AssertNotEmpty()
+}
+
+func Test_RecorderRestore(t *testing.T) {
+	var rec *logtest.Recorder
+	t.Run("replace", func(t *testing.T) {
+		rec = logtest.New(t)
+	})
+
+	log.Info("after restore")
+	rec.AssertEmpty()
+}
+
+func Test_NewRecorder(t *testing.T) {
+	rec := logtest.NewRecorder(t)
+
+	log.FromContext(rec.Context(context.Background())).Info("injected")
+	rec.Logger().Info("logger")
+
+	assert.Equal(t, []string{"injected", "logger"}, rec.Messages())
+}
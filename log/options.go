@@ -20,21 +20,39 @@ const (
 	flagErrorOutputPaths  = "log.error-output-paths"
 	flagDevelopment       = "log.development"
 	flagName              = "log.name"
+	flagDisableRedaction  = "log.disable-redaction"
+	flagRedactKeys        = "log.redact-keys"
+	flagRedactPatterns    = "log.redact-patterns"
+	flagAsyncEnabled      = "log.async.enabled"
+	flagAsyncBuffer       = "log.async.buffer-size"
+	flagAsyncInterval     = "log.async.flush-interval"
+	flagAsyncOverflow     = "log.async.overflow-policy"
+	flagInfoVerbosity     = "log.bridge.info-verbosity"
+	flagMaxVerbosity      = "log.bridge.max-verbosity"
+	flagStdLogLevel       = "log.bridge.std-log-level"
 
 	consoleFormat = "console"
 	jsonFormat    = "json"
+	logfmtFormat  = "logfmt"
+	ecsFormat     = "ecs"
+	gelfFormat    = "gelf"
 )
 
 type Options struct {
-	OutputPaths       []string `json:"output-paths"       mapstructure:"output-paths"`       // 支持输出到多个输出，用逗号分开.支持输出到标准输出（stdout）和文件
-	ErrorOutputPaths  []string `json:"error-output-paths" mapstructure:"error-output-paths"` // zap内部(非业务)错误日志输出路径，多个输出，用逗号分开
-	Level             string   `json:"level"              mapstructure:"level"`              // 日志级别，优先级从低到高依次为：Debug , Info , Warn , Error , Dpanic , Panic , Fatal
-	Format            string   `json:"format"             mapstructure:"format"`             // 支持的日志输出格式，目前支持 Console 和 JSON 两种. Console 其实就是 Text 格式
-	DisableCaller     bool     `json:"disable-caller"     mapstructure:"disable-caller"`     // 是否开启 caller，如果开启会在日志中显示调用日志所在的文件、函数和行号
-	DisableStacktrace bool     `json:"disable-stacktrace" mapstructure:"disable-stacktrace"` // 是否在Panic及以上级别禁止打印堆栈信息
-	EnableColor       bool     `json:"enable-color"       mapstructure:"enable-color"`       // 是否开启颜色输出，true ，是；false，否
-	Development       bool     `json:"development"        mapstructure:"development"`        // 是否是开发模式.如果是开发模式，会对DPanicLevel进行堆栈跟踪
-	Name              string   `json:"name"               mapstructure:"name"`               // Logger 的名字
+	OutputPaths       []string      `json:"output-paths"       mapstructure:"output-paths"`       // 支持输出到多个输出，用逗号分开.支持输出到标准输出（stdout）和文件
+	ErrorOutputPaths  []string      `json:"error-output-paths" mapstructure:"error-output-paths"` // zap内部(非业务)错误日志输出路径，多个输出，用逗号分开
+	Level             string        `json:"level"              mapstructure:"level"`              // 日志级别，优先级从低到高依次为：Debug , Info , Warn , Error , Dpanic , Panic , Fatal
+	Format            string        `json:"format"             mapstructure:"format"`             // 支持的日志输出格式，目前支持 console、json、logfmt、ecs 和 gelf. console 其实就是 Text 格式
+	DisableCaller     bool          `json:"disable-caller"     mapstructure:"disable-caller"`     // 是否开启 caller，如果开启会在日志中显示调用日志所在的文件、函数和行号
+	DisableStacktrace bool          `json:"disable-stacktrace" mapstructure:"disable-stacktrace"` // 是否在Panic及以上级别禁止打印堆栈信息
+	EnableColor       bool          `json:"enable-color"       mapstructure:"enable-color"`       // 是否开启颜色输出，true ，是；false，否
+	Development       bool          `json:"development"        mapstructure:"development"`        // 是否是开发模式.如果是开发模式，会对DPanicLevel进行堆栈跟踪
+	Name              string        `json:"name"               mapstructure:"name"`               // Logger 的名字
+	DisableRedaction  bool          `json:"disable-redaction"  mapstructure:"disable-redaction"`  // 是否关闭敏感字段脱敏
+	RedactKeys        []string      `json:"redact-keys"        mapstructure:"redact-keys"`        // 需要脱敏的字段名，字段名包含其中任意一项（忽略大小写）即脱敏，包括嵌套的 map 和结构体
+	RedactPatterns    []string      `json:"redact-patterns"    mapstructure:"redact-patterns"`    // 需要脱敏的值的正则表达式，匹配的部分会被替换
+	Async             AsyncOptions  `json:"async"              mapstructure:"async"`              // 异步写日志的配置
+	Bridge            BridgeOptions `json:"bridge"             mapstructure:"bridge"`             // klog、logr、slog 和标准库 log 接入的配置
 }
 
 // NewOptions 创建一个带有默认参数的 Options 对象.
@@ -48,6 +66,11 @@ func NewOptions() *Options {
 		Development:       false,
 		OutputPaths:       []string{"stdout"},
 		ErrorOutputPaths:  []string{"stderr"},
+		DisableRedaction:  false,
+		RedactKeys:        append([]string(nil), DefaultRedactKeys...),
+		RedactPatterns:    append([]string(nil), DefaultRedactPatterns...),
+		Async:             NewAsyncOptions(),
+		Bridge:            NewBridgeOptions(),
 	}
 }
 
@@ -60,71 +83,50 @@ func (o *Options) Validate() []error {
 		errs = append(errs, err)
 	}
 
-	format := strings.ToLower(o.Format)
-	if format != consoleFormat && format != jsonFormat {
+	if _, ok := encoders[strings.ToLower(o.Format)]; !ok {
 		errs = append(errs, fmt.Errorf("not a valid log format: %q", o.Format))
 	}
 
+	if !o.DisableRedaction {
+		if _, err := newRedactor(o.RedactKeys, o.RedactPatterns); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	errs = append(errs, o.Async.Validate()...)
+	errs = append(errs, o.Bridge.Validate()...)
+
 	return errs
 }
 
-// Build 方法可以根据Options构建一个全局的Logger
+// Build 方法可以根据Options构建一个全局的Logger，同时替换 zap 的全局记录器.
+// 与 Init 相同，klog、slog 和标准库 log 会被重定向到该记录器.
 func (o Options) Build() error {
-	var zapLevel zapcore.Level
-	if err := zapLevel.UnmarshalText([]byte(o.Level)); err != nil {
-		zapLevel = zapcore.InfoLevel
-	}
-	encodeLevel := zapcore.CapitalLevelEncoder
-	if o.Format == consoleFormat && o.EnableColor {
-		encodeLevel = zapcore.CapitalColorLevelEncoder
-	}
-
-	zc := &zap.Config{
-		Level:             zap.NewAtomicLevelAt(zapLevel),
-		Development:       o.Development,
-		DisableCaller:     o.DisableCaller,
-		DisableStacktrace: o.DisableStacktrace,
-		Sampling: &zap.SamplingConfig{
-			Initial:    100,
-			Thereafter: 100,
-		},
-		Encoding: o.Format,
-		EncoderConfig: zapcore.EncoderConfig{
-			MessageKey:     "message",
-			LevelKey:       "level",
-			TimeKey:        "timestamp",
-			NameKey:        "logger",
-			CallerKey:      "caller",
-			StacktraceKey:  "stacktrace",
-			LineEnding:     zapcore.DefaultLineEnding,
-			EncodeLevel:    encodeLevel,
-			EncodeTime:     timeEncoder,
-			EncodeDuration: milliSecondsDurationEncoder,
-			EncodeCaller:   zapcore.ShortCallerEncoder,
-			EncodeName:     zapcore.FullNameEncoder,
-		},
-		OutputPaths:      o.OutputPaths,
-		ErrorOutputPaths: o.ErrorOutputPaths,
-	}
-
-	logger, err := zc.Build(zap.AddStacktrace(zapcore.PanicLevel))
+	logger, stopAsync, err := o.buildZapLogger()
 	if err != nil {
 		return err
 	}
-	zap.RedirectStdLog(logger.Named(o.Name))
+	installBridges(logger, o.Bridge)
 	zap.ReplaceGlobals(logger)
 
+	mu.Lock()
+	prev := std
+	std = newZapLogger(logger.WithOptions(zap.AddCallerSkip(1)), o.Name)
+	std.stopAsync = stopAsync
+	mu.Unlock()
+	prev.close()
+
 	return nil
 }
 
 // AddFlags 方法可以将 Options 的各个字段追加到传入的 pflag.FlagSet变量中
-func (o Options) AddFlags(fs *pflag.FlagSet) {
+func (o *Options) AddFlags(fs *pflag.FlagSet) {
 	//  定义命令行参数绑定到对应的变量
 	fs.StringVar(&o.Level, flagLevel, o.Level, "Minimum log output `LEVEL`.")
 	fs.BoolVar(&o.DisableCaller, flagDisableCaller, o.DisableCaller, "Disable output of caller information in the log.")
 	fs.BoolVar(&o.DisableStacktrace, flagDisableStacktrace,
 		o.DisableStacktrace, "Disable the log to record a stack trace for all messages at or above panic level.")
-	fs.StringVar(&o.Format, flagFormat, o.Format, "Log output `FORMAT`, support plain or json format.")
+	fs.StringVar(&o.Format, flagFormat, o.Format, "Log output `FORMAT`, support console, json, logfmt, ecs or gelf format.")
 	fs.BoolVar(&o.EnableColor, flagEnableColor, o.EnableColor, "Enable output ansi colors in plain format logs.")
 	fs.StringSliceVar(&o.OutputPaths, flagOutputPaths, o.OutputPaths, "Output paths of log.")
 	fs.StringSliceVar(&o.ErrorOutputPaths, flagErrorOutputPaths, o.ErrorOutputPaths, "Error output paths of log.")
@@ -136,6 +138,25 @@ func (o Options) AddFlags(fs *pflag.FlagSet) {
 			"the behavior of DPanicLevel and takes stacktraces more liberally.",
 	)
 	fs.StringVar(&o.Name, flagName, o.Name, "The name of the logger.")
+	fs.BoolVar(&o.DisableRedaction, flagDisableRedaction, o.DisableRedaction, "Disable masking of sensitive fields in the log.")
+	fs.StringSliceVar(&o.RedactKeys, flagRedactKeys, o.RedactKeys,
+		"Field names to be masked in the log, matched case-insensitively as substrings, including nested maps and structs.")
+	fs.StringSliceVar(&o.RedactPatterns, flagRedactPatterns, o.RedactPatterns,
+		"Regular expressions of field values to be masked in the log.")
+	fs.BoolVar(&o.Async.Enabled, flagAsyncEnabled, o.Async.Enabled,
+		"Write logs asynchronously through a bounded buffer instead of writing to the outputs on every call.")
+	fs.IntVar(&o.Async.BufferSize, flagAsyncBuffer, o.Async.BufferSize,
+		"Maximum number of log entries held in the async buffer.")
+	fs.DurationVar(&o.Async.FlushInterval, flagAsyncInterval, o.Async.FlushInterval,
+		"Interval at which buffered async log entries are flushed to the outputs.")
+	fs.StringVar(&o.Async.OverflowPolicy, flagAsyncOverflow, o.Async.OverflowPolicy,
+		"Policy applied when the async buffer is full, support block, drop-oldest or drop-debug-first.")
+	fs.IntVar(&o.Bridge.InfoVerbosity, flagInfoVerbosity, o.Bridge.InfoVerbosity,
+		"Highest klog/logr verbosity logged at info level, higher verbosities are logged at debug level.")
+	fs.IntVar(&o.Bridge.MaxVerbosity, flagMaxVerbosity, o.Bridge.MaxVerbosity,
+		"Highest klog/logr verbosity that is logged at all.")
+	fs.StringVar(&o.Bridge.StdLogLevel, flagStdLogLevel, o.Bridge.StdLogLevel,
+		"`LEVEL` used for messages written through the standard library log package.")
 }
 
 // String 方法可以将 Options 的值以 JSON 格式字符串返回
@@ -0,0 +1,259 @@
+package log
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/goccy/go-json"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// RedactedValue 是敏感字段被脱敏后输出的值.
+const RedactedValue = "******"
+
+// DefaultRedactKeys 是默认需要脱敏的字段名，字段名包含其中任意一项（忽略大小写）即会被脱敏.
+var DefaultRedactKeys = []string{"password", "secret", "token", "authorization"}
+
+// DefaultRedactPatterns 是默认需要脱敏的值的正则表达式，依次匹配 JWT 和 bcrypt 哈希.
+var DefaultRedactPatterns = []string{
+	`eyJ[A-Za-z0-9_-]*\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`,
+	`\$2[abxy]?\$[0-9]{2}\$[./A-Za-z0-9]{53}`,
+}
+
+// redactor 根据字段名和值的正则表达式对日志字段进行脱敏.
+type redactor struct {
+	keys     []string
+	patterns []*regexp.Regexp
+}
+
+// newRedactor 创建一个脱敏器. 没有任何规则时返回 nil.
+func newRedactor(keys, patterns []string) (*redactor, error) {
+	if len(keys) == 0 && len(patterns) == 0 {
+		return nil, nil
+	}
+
+	r := &redactor{keys: make([]string, 0, len(keys))}
+	for _, k := range keys {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			r.keys = append(r.keys, k)
+		}
+	}
+	for _, p := range patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("not a valid redact value pattern %q: %w", p, err)
+		}
+		r.patterns = append(r.patterns, re)
+	}
+
+	return r, nil
+}
+
+// wrapCore 返回一个在写入前对字段进行脱敏的 Core.
+func (r *redactor) wrapCore(core zapcore.Core) zapcore.Core {
+	return &redactCore{Core: core, redactor: r}
+}
+
+func (r *redactor) matchKey(key string) bool {
+	key = strings.ToLower(key)
+	for _, k := range r.keys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// redactString 用 RedactedValue 替换 s 中匹配值规则的部分.
+func (r *redactor) redactString(s string) (string, bool) {
+	changed := false
+	for _, re := range r.patterns {
+		if re.MatchString(s) {
+			s = re.ReplaceAllLiteralString(s, RedactedValue)
+			changed = true
+		}
+	}
+
+	return s, changed
+}
+
+// redactFields 返回脱敏后的字段. 没有字段需要脱敏时直接返回原切片.
+func (r *redactor) redactFields(fields []Field) []Field {
+	var out []Field
+	for i, f := range fields {
+		nf, changed := r.redactField(f)
+		if !changed {
+			if out != nil {
+				out = append(out, f)
+			}
+
+			continue
+		}
+		if out == nil {
+			out = make([]Field, i, len(fields))
+			copy(out, fields[:i])
+		}
+		out = append(out, nf)
+	}
+	if out == nil {
+		return fields
+	}
+
+	return out
+}
+
+func (r *redactor) redactField(f Field) (Field, bool) {
+	if f.Type == zapcore.NamespaceType || f.Type == zapcore.SkipType {
+		return f, false
+	}
+	if r.matchKey(f.Key) {
+		return zap.String(f.Key, RedactedValue), true
+	}
+
+	switch f.Type {
+	case zapcore.StringType:
+		if s, changed := r.redactString(f.String); changed {
+			return zap.String(f.Key, s), true
+		}
+	case zapcore.ByteStringType:
+		if s, changed := r.redactString(string(f.Interface.([]byte))); changed {
+			return zap.String(f.Key, s), true
+		}
+	case zapcore.StringerType:
+		if s, changed := r.redactString(fmt.Sprint(f.Interface)); changed {
+			return zap.String(f.Key, s), true
+		}
+	case zapcore.ErrorType:
+		if err, ok := f.Interface.(error); ok && err != nil {
+			if s, changed := r.redactString(err.Error()); changed {
+				return zap.String(f.Key, s), true
+			}
+		}
+	case zapcore.ReflectType:
+		if v, ok := toGeneric(f.Interface); ok {
+			if nv, changed := r.redactValue(v); changed {
+				return zap.Any(f.Key, nv), true
+			}
+		}
+	case zapcore.ObjectMarshalerType, zapcore.ArrayMarshalerType, zapcore.InlineMarshalerType:
+		enc := zapcore.NewMapObjectEncoder()
+		f.AddTo(enc)
+		if f.Type == zapcore.InlineMarshalerType {
+			if nv, changed := r.redactValue(enc.Fields); changed {
+				return zap.Inline(redactedObject(nv.(map[string]interface{}))), true
+			}
+
+			break
+		}
+		if nv, changed := r.redactValue(enc.Fields[f.Key]); changed {
+			return zap.Any(f.Key, nv), true
+		}
+	}
+
+	return f, false
+}
+
+// redactValue 递归地对 map、slice 和字符串进行脱敏.
+func (r *redactor) redactValue(v interface{}) (interface{}, bool) {
+	switch val := v.(type) {
+	case string:
+		return r.redactString(val)
+	case map[string]interface{}:
+		changed := false
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			if r.matchKey(k) {
+				out[k] = RedactedValue
+				changed = true
+
+				continue
+			}
+			nv, c := r.redactValue(item)
+			out[k] = nv
+			changed = changed || c
+		}
+
+		return out, changed
+	case []interface{}:
+		changed := false
+		out := make([]interface{}, len(val))
+		for i, item := range val {
+			nv, c := r.redactValue(item)
+			out[i] = nv
+			changed = changed || c
+		}
+
+		return out, changed
+	default:
+		return v, false
+	}
+}
+
+// toGeneric 将任意值（结构体、map 等）转换为由 map[string]interface{}、[]interface{} 和基本类型组成的通用结构.
+func toGeneric(v interface{}) (interface{}, bool) {
+	switch val := v.(type) {
+	case nil:
+		return nil, false
+	case string:
+		return val, true
+	case map[string]interface{}, []interface{}:
+		return val, true
+	}
+
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, false
+	}
+	var out interface{}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	if err := dec.Decode(&out); err != nil {
+		return nil, false
+	}
+
+	return out, true
+}
+
+// redactedObject 将脱敏后的 map 作为内联对象输出.
+type redactedObject map[string]interface{}
+
+func (o redactedObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for k, v := range o {
+		zap.Any(k, v).AddTo(enc)
+	}
+
+	return nil
+}
+
+// redactCore 在将日志条目交给底层 Core 编码之前进行脱敏.
+type redactCore struct {
+	zapcore.Core
+	redactor *redactor
+}
+
+func (c *redactCore) With(fields []Field) zapcore.Core {
+	return &redactCore{
+		Core:     c.Core.With(c.redactor.redactFields(fields)),
+		redactor: c.redactor,
+	}
+}
+
+func (c *redactCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+
+	return ce
+}
+
+func (c *redactCore) Write(ent zapcore.Entry, fields []Field) error {
+	if msg, changed := c.redactor.redactString(ent.Message); changed {
+		ent.Message = msg
+	}
+
+	return c.Core.Write(ent, c.redactor.redactFields(fields))
+}
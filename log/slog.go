@@ -0,0 +1,168 @@
+package log
+
+import (
+	"context"
+	"log/slog"
+	"runtime"
+
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// slogHandler 是使用 zap 记录日志的 slog.Handler.
+type slogHandler struct {
+	l *zap.Logger
+	// groups 是还没有输出任何字段的分组，只有在分组中出现字段时才会创建对应的命名空间
+	groups []string
+}
+
+func newSlogHandler(l *zap.Logger) slog.Handler {
+	return &slogHandler{l: l}
+}
+
+// Slog 返回写入全局记录器的 slog.Logger.
+func Slog() *slog.Logger { return std.Slog() }
+
+// Slog 返回写入该记录器的 slog.Logger.
+func (l *zapLogger) Slog() *slog.Logger {
+	return slog.New(newSlogHandler(l.zapLogger))
+}
+
+// SlogHandler 返回写入全局记录器的 slog.Handler.
+func SlogHandler() slog.Handler {
+	return newSlogHandler(std.zapLogger)
+}
+
+// slogLevel 将 slog 的级别映射为日志级别，介于两个级别之间的值向下取整.
+func slogLevel(level slog.Level) Level {
+	switch {
+	case level >= slog.LevelError:
+		return zapcore.ErrorLevel
+	case level >= slog.LevelWarn:
+		return zapcore.WarnLevel
+	case level >= slog.LevelInfo:
+		return zapcore.InfoLevel
+	default:
+		return zapcore.DebugLevel
+	}
+}
+
+func (h *slogHandler) Enabled(_ context.Context, level slog.Level) bool {
+	return h.l.Core().Enabled(slogLevel(level))
+}
+
+func (h *slogHandler) Handle(ctx context.Context, r slog.Record) error {
+	ce := h.l.Check(slogLevel(r.Level), r.Message)
+	if ce == nil {
+		return nil
+	}
+	if !r.Time.IsZero() {
+		ce.Time = r.Time
+	}
+	// slog 已经记录了调用位置，不依赖 caller skip
+	if ce.Caller.Defined && r.PC != 0 {
+		frame, _ := runtime.CallersFrames([]uintptr{r.PC}).Next()
+		ce.Caller = zapcore.NewEntryCaller(frame.PC, frame.File, frame.Line, true)
+		ce.Caller.Function = frame.Function
+	}
+
+	fields := contextFields(ctx)
+	attrs := make([]Field, 0, r.NumAttrs())
+	r.Attrs(func(a slog.Attr) bool {
+		if f, ok := slogField(a); ok {
+			attrs = append(attrs, f)
+		}
+
+		return true
+	})
+	if len(attrs) > 0 {
+		fields = append(fields, h.namespaces()...)
+		fields = append(fields, attrs...)
+	}
+	ce.Write(fields...)
+
+	return nil
+}
+
+func (h *slogHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	fields := make([]Field, 0, len(attrs))
+	for _, a := range attrs {
+		if f, ok := slogField(a); ok {
+			fields = append(fields, f)
+		}
+	}
+	if len(fields) == 0 {
+		return h
+	}
+
+	return &slogHandler{l: h.l.With(append(h.namespaces(), fields...)...)}
+}
+
+func (h *slogHandler) WithGroup(name string) slog.Handler {
+	if name == "" {
+		return h
+	}
+	groups := make([]string, len(h.groups), len(h.groups)+1)
+	copy(groups, h.groups)
+
+	return &slogHandler{l: h.l, groups: append(groups, name)}
+}
+
+func (h *slogHandler) namespaces() []Field {
+	fields := make([]Field, 0, len(h.groups))
+	for _, g := range h.groups {
+		fields = append(fields, zap.Namespace(g))
+	}
+
+	return fields
+}
+
+// slogField 将 slog.Attr 转换为字段，空的 Attr 和空的分组会被忽略.
+func slogField(a slog.Attr) (Field, bool) {
+	a.Value = a.Value.Resolve()
+	if a.Equal(slog.Attr{}) {
+		return Field{}, false
+	}
+
+	switch a.Value.Kind() {
+	case slog.KindBool:
+		return zap.Bool(a.Key, a.Value.Bool()), true
+	case slog.KindDuration:
+		return zap.Duration(a.Key, a.Value.Duration()), true
+	case slog.KindFloat64:
+		return zap.Float64(a.Key, a.Value.Float64()), true
+	case slog.KindInt64:
+		return zap.Int64(a.Key, a.Value.Int64()), true
+	case slog.KindString:
+		return zap.String(a.Key, a.Value.String()), true
+	case slog.KindTime:
+		return zap.Time(a.Key, a.Value.Time()), true
+	case slog.KindUint64:
+		return zap.Uint64(a.Key, a.Value.Uint64()), true
+	case slog.KindGroup:
+		group := a.Value.Group()
+		if len(group) == 0 {
+			return Field{}, false
+		}
+		// 没有名字的分组中的字段直接放在当前层级
+		if a.Key == "" {
+			return zap.Inline(slogGroup(group)), true
+		}
+
+		return zap.Object(a.Key, slogGroup(group)), true
+	default:
+		return zap.Any(a.Key, a.Value.Any()), true
+	}
+}
+
+type slogGroup []slog.Attr
+
+func (g slogGroup) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	for _, a := range g {
+		if f, ok := slogField(a); ok {
+			f.AddTo(enc)
+		}
+	}
+
+	return nil
+}
@@ -5,11 +5,17 @@ import (
 	"go.uber.org/zap/zapcore"
 )
 
-// 定义公共日志字段.
+// ContextKey 是日志包在 context 中存取值时使用的键类型，避免与其他包的字符串键冲突.
+type ContextKey string
+
+// String 返回键对应的日志字段名.
+func (k ContextKey) String() string { return string(k) }
+
+// 定义公共日志字段. 既可以作为 context 键，也可以通过 String 方法作为日志字段名.
 const (
-	KeyRequestID   string = "requestID"
-	KeyUsername    string = "username"
-	KeyWatcherName string = "watcher"
+	KeyRequestID   ContextKey = "requestID"
+	KeyUsername    ContextKey = "username"
+	KeyWatcherName ContextKey = "watcher"
 )
 
 // Field 是底层日志框架中字段结构的别名.
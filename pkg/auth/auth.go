@@ -1,40 +1,46 @@
-// Package auth 加密和比较密码字符串
+// Package auth 加密和比较密码字符串，提供组合强度校验、历史密码检查和哈希的密码策略，
+// 签发和验证 JWT，使用 secretID/secretKey 为请求签名和验证签名，并提供相应的 gin 中间件.
 package auth
 
 import (
 	"time"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v5"
 	"golang.org/x/crypto/bcrypt"
 )
 
-// Encrypt 使用 bcrypt 加密纯文本.
+// Encrypt 使用 bcrypt 加密纯文本. 需要选择算法和参数时使用 PasswordHasher.
 func Encrypt(source string) (string, error) {
 	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(source), bcrypt.DefaultCost)
 	return string(hashedBytes), err
 }
 
-// Compare 比较加密文本和明文是否相同.
+// Compare 比较加密文本和明文是否相同，加密文本可以是 bcrypt、argon2id 或 scrypt 哈希.
 func Compare(hashedPassword, password string) error {
-	return bcrypt.CompareHashAndPassword([]byte(hashedPassword), []byte(password))
+	_, err := compareHasher.Verify(hashedPassword, password)
+
+	return err
 }
 
-// Sign 根据 secretID、secretKey、iss 和 aud 签发一个 jwt token.
+// compareHasher 用于 Compare，它的配置只影响 needsRehash.
+var compareHasher = &PasswordHasher{config: DefaultHasherConfig()}
+
+// Sign 根据 secretID、secretKey、iss 和 aud 签发一个有效期为 1 分钟的 HS256 token，
+// secretID 作为 token 头中的 kid. 签发失败时返回空字符串.
+//
+// Deprecated: 使用 TokenService.Sign，它支持更多的算法和密钥轮换，并会返回签发错误.
 func Sign(secretID string, secretKey string, iss, aud string) string {
-	claims := jwt.MapClaims{
-		"exp": time.Now().Add(time.Minute).Unix(),
-		"iat": time.Now().Unix(),
-		"nbf": time.Now().Add(0).Unix(),
-		"aud": aud,
-		"iss": iss,
+	key, err := NewHMACKey(secretID, jwt.SigningMethodHS256.Alg(), []byte(secretKey))
+	if err != nil {
+		return ""
+	}
+	keys, err := NewKeySet(key)
+	if err != nil {
+		return ""
 	}
 
-	// create a new token
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	token.Header["kid"] = secretID
-
-	// Sign the token with the specified secret.
-	tokenString, _ := token.SignedString([]byte(secretKey))
+	config := TokenConfig{Issuer: iss, Audience: []string{aud}, TTL: time.Minute}
+	tokenString, _ := NewTokenService(keys, config).Sign(&Claims{})
 
 	return tokenString
 }
@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"net/http"
+
+	"github.com/gzwillyy/components/errors"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+)
+
+// auth 包返回的错误码，它们使用 errors.MustRegister 注册，core.WriteResponse 会返回对应的 HTTP 状态码.
+const (
+	// ErrUnauthorized - 401: Authentication is required.
+	ErrUnauthorized int = iota + 100912
+
+	// ErrTokenInvalid - 401: Token invalid.
+	ErrTokenInvalid
+
+	// ErrTokenExpired - 401: Token expired.
+	ErrTokenExpired
+
+	// ErrSignatureInvalid - 401: Signature is invalid.
+	ErrSignatureInvalid
+
+	// ErrPasswordIncorrect - 401: Password was incorrect.
+	ErrPasswordIncorrect
+)
+
+// 100917 是 authorizer 包的 ErrForbidden.
+const (
+	// ErrRequestTooLarge - 413: Request body too large.
+	ErrRequestTooLarge int = iota + 100918
+)
+
+func init() {
+	register(ErrUnauthorized, http.StatusUnauthorized, "Authentication is required")
+	register(ErrTokenInvalid, http.StatusUnauthorized, "Token invalid")
+	register(ErrTokenExpired, http.StatusUnauthorized, "Token expired")
+	register(ErrSignatureInvalid, http.StatusUnauthorized, "Signature is invalid")
+	register(ErrPasswordIncorrect, http.StatusUnauthorized, "Password was incorrect")
+	register(ErrRequestTooLarge, http.StatusRequestEntityTooLarge, "Request body too large")
+}
+
+// register 注册 auth 包的错误码.
+func register(code int, httpStatus int, message string) {
+	errors.MustRegister(&metav1.ErrCode{C: code, HTTP: httpStatus, Ext: message})
+}
@@ -0,0 +1,389 @@
+package auth
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gzwillyy/components/errors"
+	"github.com/gzwillyy/components/pkg/util/clock"
+)
+
+// HMAC 签名使用的请求头和格式.
+const (
+	// HMACAlgorithm 是 Authorization 头中签名方案的名称.
+	HMACAlgorithm = "HMAC-SHA256"
+
+	// HeaderDate 是签名时间的请求头，格式为 HMACTimeFormat.
+	HeaderDate = "X-Date"
+
+	// HeaderNonce 是一次性随机数的请求头，用于防止重放.
+	HeaderNonce = "X-Nonce"
+
+	// HeaderContentSHA256 是请求体 SHA256 摘要的请求头，十六进制编码.
+	HeaderContentSHA256 = "X-Content-SHA256"
+
+	// HMACTimeFormat 是 X-Date 头的时间格式.
+	HMACTimeFormat = "20060102T150405Z"
+
+	// DefaultMaxBodySize 是 HMACVerifier 默认读取的请求体的最大字节数.
+	DefaultMaxBodySize = 10 << 20
+)
+
+// defaultSignedHeaders 是总是参与签名的请求头.
+var defaultSignedHeaders = []string{"host", strings.ToLower(HeaderContentSHA256), strings.ToLower(HeaderDate), strings.ToLower(HeaderNonce)}
+
+// HMACSigner 使用 secretID 和 secretKey 为请求签名，secretID 和 secretKey 可以通过
+// idutil.NewSecretID 和 idutil.NewSecretKey 生成. 签名写入 Authorization 头：
+//
+//	Authorization: HMAC-SHA256 Credential=<secretID>, SignedHeaders=host;x-content-sha256;x-date;x-nonce, Signature=<hex>
+type HMACSigner struct {
+	SecretID  string
+	SecretKey string
+
+	// SignedHeaders 是除 Host、X-Content-SHA256、X-Date 和 X-Nonce 外参与签名的请求头，
+	// 例如 Content-Type.
+	SignedHeaders []string
+
+	// Clock 用于获取签名时间，为 nil 时使用系统时钟.
+	Clock clock.PassiveClock
+}
+
+// Sign 为 req 签名，读取的请求体会被还原.
+func (s *HMACSigner) Sign(req *http.Request) error {
+	body, err := readBody(req)
+	if err != nil {
+		return err
+	}
+	nonce, err := newNonce()
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set(HeaderDate, now(s.Clock).UTC().Format(HMACTimeFormat))
+	req.Header.Set(HeaderNonce, nonce)
+	req.Header.Set(HeaderContentSHA256, hashHex(body))
+
+	signedHeaders := normalizeHeaders(append(append([]string(nil), s.SignedHeaders...), defaultSignedHeaders...))
+	signature := hmacSignature(s.SecretKey, CanonicalRequest(req, signedHeaders))
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s, SignedHeaders=%s, Signature=%s",
+		HMACAlgorithm, s.SecretID, strings.Join(signedHeaders, ";"), signature))
+
+	return nil
+}
+
+// HMACTransport 是为每个请求签名的 http.RoundTripper.
+type HMACTransport struct {
+	Signer *HMACSigner
+
+	// Base 是发送签名后请求的 RoundTripper，为 nil 时使用 http.DefaultTransport.
+	Base http.RoundTripper
+}
+
+// RoundTrip 实现 http.RoundTripper，它为请求的副本签名，不修改原始请求.
+func (t *HMACTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	signed := req.Clone(req.Context())
+	if req.Body != nil && req.GetBody != nil {
+		body, err := req.GetBody()
+		if err != nil {
+			return nil, err
+		}
+		signed.Body = body
+	}
+	if err := t.Signer.Sign(signed); err != nil {
+		return nil, err
+	}
+
+	base := t.Base
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return base.RoundTrip(signed)
+}
+
+// SecretStore 根据 secretID 查找 secretKey.
+type SecretStore interface {
+	// SecretKey 返回 secretID 对应的 secretKey，secretID 不存在时返回错误.
+	SecretKey(ctx context.Context, secretID string) (string, error)
+}
+
+// StaticSecrets 是保存在内存中的 secretID 到 secretKey 的映射.
+type StaticSecrets map[string]string
+
+// SecretKey 实现 SecretStore.
+func (s StaticSecrets) SecretKey(_ context.Context, secretID string) (string, error) {
+	key, ok := s[secretID]
+	if !ok {
+		return "", fmt.Errorf("unknown secret ID %q", secretID)
+	}
+
+	return key, nil
+}
+
+// HMACVerifier 验证 HMACSigner 签名的请求.
+type HMACVerifier struct {
+	Secrets SecretStore
+
+	// Nonces 记录用过的 X-Nonce，为 nil 时不检查重放.
+	Nonces NonceCache
+
+	// MaxSkew 是签名时间与当前时间允许的最大偏差，超出的请求被拒绝. 为 0 时使用 5 分钟.
+	MaxSkew time.Duration
+
+	// MaxBodySize 是计算摘要时读取的请求体的最大字节数，超出的请求以 ErrRequestTooLarge 拒绝.
+	// 为 0 时使用 DefaultMaxBodySize.
+	MaxBodySize int64
+
+	// Clock 用于获取当前时间，为 nil 时使用系统时钟.
+	Clock clock.PassiveClock
+}
+
+// Verify 验证请求的签名、请求体摘要、签名时间和 X-Nonce，返回请求的 secretID.
+// 请求体在检查 Authorization 头和签名时间之后才被读取，读取的请求体会被还原.
+// 签名无效时返回 ErrSignatureInvalid 错误码，请求体超过 MaxBodySize 时返回 ErrRequestTooLarge 错误码.
+func (v *HMACVerifier) Verify(req *http.Request) (string, error) {
+	auth, err := parseHMACAuthorization(req.Header.Get("Authorization"))
+	if err != nil {
+		return "", err
+	}
+	for _, h := range defaultSignedHeaders {
+		if !containsString(auth.signedHeaders, h) {
+			return "", errors.WithCode(ErrSignatureInvalid, "header %s must be signed", h)
+		}
+	}
+
+	signedAt, err := time.Parse(HMACTimeFormat, req.Header.Get(HeaderDate))
+	if err != nil {
+		return "", errors.WrapC(err, ErrSignatureInvalid, "invalid %s header", HeaderDate)
+	}
+	maxSkew := v.MaxSkew
+	if maxSkew == 0 {
+		maxSkew = 5 * time.Minute
+	}
+	current := now(v.Clock)
+	if skew := current.Sub(signedAt); skew > maxSkew || skew < -maxSkew {
+		return "", errors.WithCode(ErrSignatureInvalid, "request signed at %s is outside the allowed clock skew", signedAt)
+	}
+
+	maxBodySize := v.MaxBodySize
+	if maxBodySize == 0 {
+		maxBodySize = DefaultMaxBodySize
+	}
+	if req.Body != nil && req.Body != http.NoBody {
+		req.Body = http.MaxBytesReader(nil, req.Body, maxBodySize)
+	}
+	body, err := readBody(req)
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return "", errors.WithCode(ErrRequestTooLarge, "request body exceeds %d bytes", maxBodySize)
+		}
+
+		return "", err
+	}
+	if !hmac.Equal([]byte(req.Header.Get(HeaderContentSHA256)), []byte(hashHex(body))) {
+		return "", errors.WithCode(ErrSignatureInvalid, "the request body doesn't match %s", HeaderContentSHA256)
+	}
+
+	secretKey, err := v.Secrets.SecretKey(req.Context(), auth.secretID)
+	if err != nil {
+		return "", errors.WrapC(err, ErrSignatureInvalid, "invalid credential")
+	}
+	expected := hmacSignature(secretKey, CanonicalRequest(req, auth.signedHeaders))
+	if !hmac.Equal([]byte(auth.signature), []byte(expected)) {
+		return "", errors.WithCode(ErrSignatureInvalid, "signature mismatch")
+	}
+
+	// 签名通过后才记录 nonce，伪造的请求不能占用 nonce
+	if v.Nonces != nil {
+		nonce := req.Header.Get(HeaderNonce)
+		if nonce == "" || !v.Nonces.Add(auth.secretID+":"+nonce, signedAt.Add(maxSkew)) {
+			return "", errors.WithCode(ErrSignatureInvalid, "the request has already been received")
+		}
+	}
+
+	return auth.secretID, nil
+}
+
+// CanonicalRequest 返回参与签名的规范请求，各部分以换行分隔：
+// 请求方法、URL 编码的路径、按键排序的查询参数、小写名称排序的 signedHeaders 及其值、
+// 以分号连接的 signedHeaders、X-Date、X-Nonce 和 X-Content-SHA256.
+func CanonicalRequest(req *http.Request, signedHeaders []string) string {
+	path := req.URL.EscapedPath()
+	if path == "" {
+		path = "/"
+	}
+
+	var headers strings.Builder
+	for _, h := range signedHeaders {
+		headers.WriteString(h)
+		headers.WriteByte(':')
+		headers.WriteString(headerValue(req, h))
+		headers.WriteByte('\n')
+	}
+
+	return strings.Join([]string{
+		req.Method,
+		path,
+		canonicalQuery(req.URL.Query()),
+		headers.String(),
+		strings.Join(signedHeaders, ";"),
+		req.Header.Get(HeaderDate),
+		req.Header.Get(HeaderNonce),
+		req.Header.Get(HeaderContentSHA256),
+	}, "\n")
+}
+
+// canonicalQuery 返回按键和值排序的查询参数.
+func canonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, k := range keys {
+		values := append([]string(nil), query[k]...)
+		sort.Strings(values)
+		for _, v := range values {
+			parts = append(parts, url.QueryEscape(k)+"="+url.QueryEscape(v))
+		}
+	}
+
+	return strings.Join(parts, "&")
+}
+
+// headerValue 返回参与签名的请求头的值，多个值以逗号连接.
+func headerValue(req *http.Request, name string) string {
+	if name == "host" {
+		if req.Host != "" {
+			return req.Host
+		}
+
+		return req.URL.Host
+	}
+
+	values := make([]string, 0, len(req.Header.Values(name)))
+	for _, v := range req.Header.Values(name) {
+		values = append(values, strings.TrimSpace(v))
+	}
+
+	return strings.Join(values, ",")
+}
+
+// normalizeHeaders 返回去重并排序的小写请求头名称.
+func normalizeHeaders(headers []string) []string {
+	seen := map[string]bool{}
+	result := make([]string, 0, len(headers))
+	for _, h := range headers {
+		h = strings.ToLower(strings.TrimSpace(h))
+		if h != "" && !seen[h] {
+			seen[h] = true
+			result = append(result, h)
+		}
+	}
+	sort.Strings(result)
+
+	return result
+}
+
+// hmacAuthorization 是解析后的 Authorization 头.
+type hmacAuthorization struct {
+	secretID      string
+	signedHeaders []string
+	signature     string
+}
+
+// parseHMACAuthorization 解析 HMACSigner 写入的 Authorization 头.
+func parseHMACAuthorization(header string) (*hmacAuthorization, error) {
+	params, ok := strings.CutPrefix(header, HMACAlgorithm+" ")
+	if !ok {
+		return nil, errors.WithCode(ErrUnauthorized, "missing %s signature in the Authorization header", HMACAlgorithm)
+	}
+
+	auth := &hmacAuthorization{}
+	for _, param := range strings.Split(params, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(param), "=")
+		switch key {
+		case "Credential":
+			auth.secretID = value
+		case "SignedHeaders":
+			auth.signedHeaders = normalizeHeaders(strings.Split(value, ";"))
+		case "Signature":
+			auth.signature = value
+		}
+	}
+	if auth.secretID == "" || auth.signature == "" || len(auth.signedHeaders) == 0 {
+		return nil, errors.WithCode(ErrSignatureInvalid, "malformed %s Authorization header", HMACAlgorithm)
+	}
+
+	return auth, nil
+}
+
+// readBody 读取并还原请求体.
+func readBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+
+	body, err := io.ReadAll(req.Body)
+	_ = req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+
+	return body, nil
+}
+
+func hmacSignature(secretKey, canonicalRequest string) string {
+	mac := hmac.New(sha256.New, []byte(secretKey))
+	mac.Write([]byte(canonicalRequest))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+func newNonce() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(b), nil
+}
+
+func now(c clock.PassiveClock) time.Time {
+	if c == nil {
+		return time.Now()
+	}
+
+	return c.Now()
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
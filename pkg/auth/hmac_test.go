@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gzwillyy/components/errors"
+	"github.com/gzwillyy/components/pkg/util/clock"
+)
+
+func TestCanonicalRequest(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "http://iam.api/v1/secrets/a%20b?b=2&a=3&a=1&c=x+y", nil)
+	req.Header.Set("Content-Type", " application/json ")
+	req.Header.Set(HeaderDate, "20240401T120000Z")
+	req.Header.Set(HeaderNonce, "n1")
+	req.Header.Set(HeaderContentSHA256, "abc")
+
+	want := strings.Join([]string{
+		"POST",
+		"/v1/secrets/a%20b",
+		"a=1&a=3&b=2&c=x+y",
+		"content-type:application/json\nhost:iam.api\n",
+		"content-type;host",
+		"20240401T120000Z",
+		"n1",
+		"abc",
+	}, "\n")
+	if got := CanonicalRequest(req, []string{"content-type", "host"}); got != want {
+		t.Errorf("got\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestHMACSignVerify(t *testing.T) {
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(now)
+	signer := &HMACSigner{SecretID: "id1", SecretKey: "key1", SignedHeaders: []string{"Content-Type"}, Clock: fakeClock}
+	verifier := &HMACVerifier{
+		Secrets: StaticSecrets{"id1": "key1"},
+		Nonces:  NewMemoryNonceCache(fakeClock),
+		MaxSkew: time.Minute,
+		Clock:   fakeClock,
+	}
+
+	newRequest := func(body string) *http.Request {
+		req := httptest.NewRequest(http.MethodPut, "http://iam.api/v1/secrets/colin?dryRun=All", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if err := signer.Sign(req); err != nil {
+			t.Fatal(err)
+		}
+
+		return req
+	}
+
+	req := newRequest(`{"description":"key"}`)
+	secretID, err := verifier.Verify(req)
+	if err != nil || secretID != "id1" {
+		t.Fatalf("verify: %q %v", secretID, err)
+	}
+	if body, _ := io.ReadAll(req.Body); string(body) != `{"description":"key"}` {
+		t.Errorf("expected the body to be restored, got %q", body)
+	}
+	if _, err := verifier.Verify(req); !errors.IsCode(err, ErrSignatureInvalid) {
+		t.Errorf("expected a replayed request to be rejected, got %v", err)
+	}
+
+	tests := []struct {
+		name   string
+		modify func(req *http.Request)
+	}{
+		{"body", func(req *http.Request) { req.Body = io.NopCloser(strings.NewReader(`{"description":"other"}`)) }},
+		{"query", func(req *http.Request) { req.URL.RawQuery = "dryRun=None" }},
+		{"path", func(req *http.Request) { req.URL.Path = "/v1/secrets/lisa" }},
+		{"method", func(req *http.Request) { req.Method = http.MethodDelete }},
+		{"signed header", func(req *http.Request) { req.Header.Set("Content-Type", "text/plain") }},
+		{"unknown secret ID", func(req *http.Request) {
+			req.Header.Set("Authorization", strings.Replace(req.Header.Get("Authorization"), "id1", "id2", 1))
+		}},
+		{"unsigned nonce", func(req *http.Request) {
+			req.Header.Set("Authorization", strings.Replace(req.Header.Get("Authorization"), ";x-nonce", "", 1))
+		}},
+		{"expired", func(req *http.Request) { fakeClock.SetTime(now.Add(2 * time.Minute)) }},
+	}
+	for _, tt := range tests {
+		fakeClock.SetTime(now)
+		req := newRequest(`{"description":"key"}`)
+		tt.modify(req)
+		if _, err := verifier.Verify(req); !errors.IsCode(err, ErrSignatureInvalid) {
+			t.Errorf("%s: expected ErrSignatureInvalid, got %v", tt.name, err)
+		}
+	}
+
+	fakeClock.SetTime(now)
+	req = httptest.NewRequest(http.MethodGet, "/", strings.NewReader("body"))
+	if _, err := verifier.Verify(req); !errors.IsCode(err, ErrUnauthorized) {
+		t.Errorf("expected ErrUnauthorized without a signature, got %v", err)
+	}
+	if body, _ := io.ReadAll(req.Body); string(body) != "body" {
+		t.Errorf("expected the body not to be read without a signature, got %q", body)
+	}
+
+	verifier.MaxBodySize = 8
+	if _, err := verifier.Verify(newRequest(`{"description":"key"}`)); !errors.IsCode(err, ErrRequestTooLarge) {
+		t.Errorf("expected ErrRequestTooLarge for a large body, got %v", err)
+	}
+	if _, err := verifier.Verify(newRequest(`{}`)); err != nil {
+		t.Errorf("expected a small body to be accepted, got %v", err)
+	}
+}
+
+func TestMemoryNonceCache(t *testing.T) {
+	now := time.Now()
+	fakeClock := clock.NewFakeClock(now)
+	cache := NewMemoryNonceCache(fakeClock)
+
+	if !cache.Add("n1", now.Add(time.Minute)) || cache.Add("n1", now.Add(time.Minute)) {
+		t.Errorf("expected a nonce to be accepted once")
+	}
+	fakeClock.SetTime(now.Add(2 * time.Minute))
+	if !cache.Add("n1", now.Add(3*time.Minute)) {
+		t.Errorf("expected an expired nonce to be accepted again")
+	}
+}
+
+func TestHMACTransportAndMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	verifier := &HMACVerifier{Secrets: StaticSecrets{"id1": "key1"}, Nonces: NewMemoryNonceCache(nil)}
+	engine.POST("/v1/secrets", HMACAuth(verifier), func(c *gin.Context) {
+		secretID, _ := SecretIDFromContext(c.Request.Context())
+		body, _ := io.ReadAll(c.Request.Body)
+		c.String(http.StatusOK, c.GetString(SecretIDKey)+"/"+secretID+"/"+string(body))
+	})
+	server := httptest.NewServer(engine)
+	defer server.Close()
+
+	client := &http.Client{Transport: &HMACTransport{Signer: &HMACSigner{SecretID: "id1", SecretKey: "key1"}}}
+	for i := 0; i < 2; i++ {
+		resp, err := client.Post(server.URL+"/v1/secrets?b=1&a=2", "application/json", strings.NewReader(`{"name":"colin"}`))
+		if err != nil {
+			t.Fatal(err)
+		}
+		body, _ := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusOK || string(body) != `id1/id1/{"name":"colin"}` {
+			t.Errorf("unexpected response %d %s", resp.StatusCode, body)
+		}
+	}
+
+	client.Transport = &HMACTransport{Signer: &HMACSigner{SecretID: "id1", SecretKey: "wrong"}}
+	resp, err := client.Post(server.URL+"/v1/secrets", "application/json", strings.NewReader(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusUnauthorized {
+		t.Errorf("expected 401 for a wrong secret key, got %d", resp.StatusCode)
+	}
+}
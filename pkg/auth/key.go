@@ -0,0 +1,206 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// Key 是用于签发和验证 JWT 的密钥，通过 ID 在 token 头的 kid 中标识.
+// 只有公钥的 Key 只能用于验证.
+type Key struct {
+	// ID 是密钥的标识，签发的 token 头中的 kid 为该值.
+	ID string
+
+	// Algorithm 是密钥使用的签名算法，例如 HS256、RS256、ES256 和 EdDSA.
+	Algorithm string
+
+	signKey   interface{}
+	verifyKey interface{}
+}
+
+// NewHMACKey 返回使用 HS256、HS384 或 HS512 算法的对称密钥.
+func NewHMACKey(id, alg string, secret []byte) (*Key, error) {
+	if _, ok := jwt.GetSigningMethod(alg).(*jwt.SigningMethodHMAC); !ok {
+		return nil, fmt.Errorf("algorithm %q is not a HMAC algorithm", alg)
+	}
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("the secret of key %q is empty", id)
+	}
+
+	return &Key{ID: id, Algorithm: alg, signKey: secret, verifyKey: secret}, nil
+}
+
+// NewPrivateKey 返回可以签发 token 的非对称密钥，private 可以是
+// *rsa.PrivateKey（RS*、PS*）、*ecdsa.PrivateKey（ES*）或 ed25519.PrivateKey（EdDSA）.
+func NewPrivateKey(id, alg string, private crypto.Signer) (*Key, error) {
+	if err := checkKeyType(alg, private.Public()); err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	return &Key{ID: id, Algorithm: alg, signKey: private, verifyKey: private.Public()}, nil
+}
+
+// NewPublicKey 返回只能验证 token 的非对称密钥，用于验证其他服务签发的 token.
+func NewPublicKey(id, alg string, public crypto.PublicKey) (*Key, error) {
+	if err := checkKeyType(alg, public); err != nil {
+		return nil, fmt.Errorf("key %q: %w", id, err)
+	}
+
+	return &Key{ID: id, Algorithm: alg, verifyKey: public}, nil
+}
+
+// CanSign 判断密钥是否可以签发 token.
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// PublicKey 返回非对称密钥的公钥，对称密钥返回 nil.
+func (k *Key) PublicKey() crypto.PublicKey {
+	if _, ok := k.verifyKey.([]byte); ok {
+		return nil
+	}
+
+	return k.verifyKey
+}
+
+// checkKeyType 检查公钥的类型是否与算法匹配，ECDSA 算法还要求曲线匹配.
+func checkKeyType(alg string, public crypto.PublicKey) error {
+	var ok bool
+	switch method := jwt.GetSigningMethod(alg).(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok = public.(*rsa.PublicKey)
+	case *jwt.SigningMethodECDSA:
+		var key *ecdsa.PublicKey
+		if key, ok = public.(*ecdsa.PublicKey); ok && key.Curve.Params().BitSize != method.CurveBits {
+			return fmt.Errorf("algorithm %s requires a %d bit curve, got %s", alg, method.CurveBits, key.Curve.Params().Name)
+		}
+	case *jwt.SigningMethodEd25519:
+		_, ok = public.(ed25519.PublicKey)
+	default:
+		return fmt.Errorf("unsupported asymmetric algorithm %q", alg)
+	}
+	if !ok {
+		return fmt.Errorf("algorithm %s can't be used with a %T key", alg, public)
+	}
+
+	return nil
+}
+
+// KeySet 是按 kid 查找的密钥集合，其中一个可签发的密钥是当前的签名密钥.
+// 轮换密钥时新密钥成为签名密钥，旧密钥保留到用它签发的 token 全部过期后再移除，
+// 这期间两者签发的 token 都可以通过验证. KeySet 可以并发使用.
+type KeySet struct {
+	mu      sync.RWMutex
+	keys    map[string]*Key
+	current string
+}
+
+// NewKeySet 返回包含 keys 的密钥集合，第一个可签发的密钥是签名密钥.
+func NewKeySet(keys ...*Key) (*KeySet, error) {
+	s := &KeySet{keys: map[string]*Key{}}
+	for _, key := range keys {
+		if err := s.Add(key); err != nil {
+			return nil, err
+		}
+	}
+
+	return s, nil
+}
+
+// Add 添加一个密钥，集合中还没有签名密钥时可签发的密钥成为签名密钥.
+func (s *KeySet) Add(key *Key) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.addLocked(key); err != nil {
+		return err
+	}
+	if s.current == "" && key.CanSign() {
+		s.current = key.ID
+	}
+
+	return nil
+}
+
+// Rotate 添加 key 并将它作为签名密钥，之前的签名密钥仍可用于验证.
+func (s *KeySet) Rotate(key *Key) error {
+	if !key.CanSign() {
+		return fmt.Errorf("key %q can't sign tokens", key.ID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.addLocked(key); err != nil {
+		return err
+	}
+	s.current = key.ID
+
+	return nil
+}
+
+// Remove 移除 ID 为 id 的密钥，签名密钥不能被移除.
+func (s *KeySet) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id == s.current {
+		return fmt.Errorf("key %q is the signing key, rotate it before removing it", id)
+	}
+	delete(s.keys, id)
+
+	return nil
+}
+
+// Lookup 返回 ID 为 id 的密钥.
+func (s *KeySet) Lookup(id string) (*Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[id]
+
+	return key, ok
+}
+
+// SigningKey 返回当前的签名密钥.
+func (s *KeySet) SigningKey() (*Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[s.current]
+
+	return key, ok
+}
+
+// Keys 返回集合中按 ID 排序的所有密钥.
+func (s *KeySet) Keys() []*Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]*Key, 0, len(s.keys))
+	for _, key := range s.keys {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].ID < keys[j].ID })
+
+	return keys
+}
+
+func (s *KeySet) addLocked(key *Key) error {
+	if key.ID == "" {
+		return fmt.Errorf("key ID must not be empty")
+	}
+	if _, ok := s.keys[key.ID]; ok {
+		return fmt.Errorf("key %q already exists", key.ID)
+	}
+	s.keys[key.ID] = key
+
+	return nil
+}
@@ -0,0 +1,112 @@
+package auth
+
+import (
+	"context"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gzwillyy/components/errors"
+	"github.com/gzwillyy/components/log"
+	"github.com/gzwillyy/components/pkg/core"
+)
+
+// ClaimsKey 是通过验证的 token 声明在 gin.Context 中的键.
+const ClaimsKey = "JWTClaims"
+
+// SecretIDKey 是通过 HMAC 签名验证的请求的 secretID 在 gin.Context 中的键.
+const SecretIDKey = "SecretID"
+
+type (
+	claimsContextKey   struct{}
+	secretIDContextKey struct{}
+)
+
+// JWTAuth 返回一个中间件，它验证请求头 Authorization 中的 Bearer token.
+// 验证失败时返回 401 响应并中止请求；验证通过时将声明保存到 gin.Context 的 ClaimsKey
+// 和请求上下文中，并将 sub 作为 log.KeyUsername 写入请求上下文.
+func JWTAuth(s *TokenService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		token, ok := bearerToken(c.GetHeader("Authorization"))
+		if !ok {
+			core.WriteResponse(c, errors.WithCode(ErrUnauthorized, "missing bearer token in the Authorization header"), nil)
+			c.Abort()
+
+			return
+		}
+
+		claims, err := s.Verify(token)
+		if err != nil {
+			core.WriteResponse(c, err, nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Set(ClaimsKey, claims)
+		ctx := WithClaims(c.Request.Context(), claims)
+		ctx = context.WithValue(ctx, log.KeyUsername, claims.Subject)
+		c.Request = c.Request.WithContext(ctx)
+		c.Next()
+	}
+}
+
+// WithClaims 返回保存了 claims 的上下文.
+func WithClaims(ctx context.Context, claims *Claims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext 返回 JWTAuth 保存在请求上下文中的声明.
+func ClaimsFromContext(ctx context.Context) (*Claims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*Claims)
+
+	return claims, ok
+}
+
+// GetClaims 返回 JWTAuth 保存在 gin.Context 中的声明.
+func GetClaims(c *gin.Context) (*Claims, bool) {
+	claims, ok := c.Get(ClaimsKey)
+	if !ok {
+		return nil, false
+	}
+	cl, ok := claims.(*Claims)
+
+	return cl, ok
+}
+
+// HMACAuth 返回一个中间件，它使用 v 验证 HMACSigner 签名的请求.
+// 验证失败时返回 401 响应并中止请求；验证通过时将 secretID 保存到 gin.Context 的 SecretIDKey
+// 和请求上下文中.
+func HMACAuth(v *HMACVerifier) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		secretID, err := v.Verify(c.Request)
+		if err != nil {
+			core.WriteResponse(c, err, nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Set(SecretIDKey, secretID)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), secretIDContextKey{}, secretID))
+		c.Next()
+	}
+}
+
+// SecretIDFromContext 返回 HMACAuth 保存在请求上下文中的 secretID.
+func SecretIDFromContext(ctx context.Context) (string, bool) {
+	secretID, ok := ctx.Value(secretIDContextKey{}).(string)
+
+	return secretID, ok
+}
+
+// bearerToken 从 Authorization 头中解析 Bearer token.
+func bearerToken(header string) (string, bool) {
+	const prefix = "Bearer "
+	if len(header) <= len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return "", false
+	}
+	token := strings.TrimSpace(header[len(prefix):])
+
+	return token, token != ""
+}
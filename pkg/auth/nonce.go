@@ -0,0 +1,55 @@
+package auth
+
+import (
+	"sync"
+	"time"
+
+	"github.com/gzwillyy/components/pkg/util/clock"
+)
+
+// NonceCache 记录用过的 nonce，用于拒绝重放的请求.
+type NonceCache interface {
+	// Add 记录 nonce 直到 expires，nonce 已经被记录且未过期时返回 false.
+	Add(nonce string, expires time.Time) bool
+}
+
+// memoryNonceCache 是保存在内存中的 NonceCache，多实例部署时应使用共享的实现，例如基于 Redis.
+type memoryNonceCache struct {
+	mu        sync.Mutex
+	clock     clock.PassiveClock
+	nonces    map[string]time.Time
+	lastPurge time.Time
+}
+
+// NewMemoryNonceCache 返回保存在内存中的 NonceCache，过期的 nonce 会被定期清除.
+// c 为 nil 时使用系统时钟.
+func NewMemoryNonceCache(c clock.PassiveClock) NonceCache {
+	if c == nil {
+		c = clock.RealClock{}
+	}
+
+	return &memoryNonceCache{clock: c, nonces: map[string]time.Time{}}
+}
+
+// Add 实现 NonceCache.
+func (c *memoryNonceCache) Add(nonce string, expires time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := c.clock.Now()
+	if now.Sub(c.lastPurge) > time.Minute {
+		for n, exp := range c.nonces {
+			if !exp.After(now) {
+				delete(c.nonces, n)
+			}
+		}
+		c.lastPurge = now
+	}
+
+	if exp, ok := c.nonces[nonce]; ok && exp.After(now) {
+		return false
+	}
+	c.nonces[nonce] = expires
+
+	return true
+}
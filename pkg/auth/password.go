@@ -0,0 +1,298 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"math/bits"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/gzwillyy/components/errors"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/validation"
+)
+
+// HashAlgorithm 是密码哈希算法.
+type HashAlgorithm string
+
+// 支持的密码哈希算法.
+const (
+	Bcrypt   HashAlgorithm = "bcrypt"
+	Argon2id HashAlgorithm = "argon2id"
+	Scrypt   HashAlgorithm = "scrypt"
+)
+
+// Argon2Params 是 argon2id 的参数.
+type Argon2Params struct {
+	// Time 是迭代次数.
+	Time uint32
+	// Memory 是使用的内存，单位 KiB.
+	Memory uint32
+	// Threads 是并行度.
+	Threads uint8
+	// KeyLen 是哈希值的字节数.
+	KeyLen uint32
+}
+
+// ScryptParams 是 scrypt 的参数.
+type ScryptParams struct {
+	// N 是 CPU/内存开销，必须是大于 1 的 2 的幂.
+	N int
+	// R 是块大小.
+	R int
+	// P 是并行度.
+	P int
+	// KeyLen 是哈希值的字节数.
+	KeyLen int
+}
+
+// HasherConfig 是 PasswordHasher 的配置，只有 Algorithm 对应算法的参数会被使用.
+type HasherConfig struct {
+	Algorithm  HashAlgorithm
+	BcryptCost int
+	Argon2     Argon2Params
+	Scrypt     ScryptParams
+}
+
+// DefaultHasherConfig 返回默认的配置，使用 OWASP 推荐参数的 argon2id.
+func DefaultHasherConfig() HasherConfig {
+	return HasherConfig{
+		Algorithm:  Argon2id,
+		BcryptCost: bcrypt.DefaultCost,
+		Argon2:     Argon2Params{Time: 3, Memory: 64 * 1024, Threads: 2, KeyLen: 32},
+		Scrypt:     ScryptParams{N: 1 << 15, R: 8, P: 1, KeyLen: 32},
+	}
+}
+
+// saltLen 是 argon2id 和 scrypt 使用的盐的字节数.
+const saltLen = 16
+
+// PasswordHasher 使用配置的算法和参数计算密码哈希，并可以验证任一支持的算法计算的哈希.
+// argon2id 和 scrypt 的哈希使用 PHC 字符串格式，参数和盐保存在哈希中：
+//
+//	$argon2id$v=19$m=65536,t=3,p=2$<salt>$<hash>
+//	$scrypt$ln=15,r=8,p=1$<salt>$<hash>
+type PasswordHasher struct {
+	config HasherConfig
+}
+
+// NewPasswordHasher 返回使用 config 的 PasswordHasher，配置的参数无效时返回错误.
+func NewPasswordHasher(config HasherConfig) (*PasswordHasher, error) {
+	switch config.Algorithm {
+	case Bcrypt:
+		if config.BcryptCost < bcrypt.MinCost || config.BcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+		}
+	case Argon2id:
+		p := config.Argon2
+		if p.Time == 0 || p.Memory == 0 || p.Threads == 0 || p.KeyLen == 0 {
+			return nil, fmt.Errorf("argon2id parameters must be positive")
+		}
+	case Scrypt:
+		p := config.Scrypt
+		if p.N <= 1 || p.N&(p.N-1) != 0 || p.R <= 0 || p.P <= 0 || p.KeyLen <= 0 {
+			return nil, fmt.Errorf("invalid scrypt parameters")
+		}
+	default:
+		return nil, fmt.Errorf("unsupported hash algorithm %q", config.Algorithm)
+	}
+
+	return &PasswordHasher{config: config}, nil
+}
+
+// Hash 返回 password 的哈希.
+func (h *PasswordHasher) Hash(password string) (string, error) {
+	if h.config.Algorithm == Bcrypt {
+		hash, err := bcrypt.GenerateFromPassword([]byte(password), h.config.BcryptCost)
+
+		return string(hash), err
+	}
+
+	salt := make([]byte, saltLen)
+	if _, err := rand.Read(salt); err != nil {
+		return "", err
+	}
+	if h.config.Algorithm == Argon2id {
+		p := h.config.Argon2
+		key := argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)
+
+		return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+			argon2.Version, p.Memory, p.Time, p.Threads, encodeBase64(salt), encodeBase64(key)), nil
+	}
+
+	p := h.config.Scrypt
+	key, err := scrypt.Key([]byte(password), salt, p.N, p.R, p.P, p.KeyLen)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("$scrypt$ln=%d,r=%d,p=%d$%s$%s",
+		bits.TrailingZeros(uint(p.N)), p.R, p.P, encodeBase64(salt), encodeBase64(key)), nil
+}
+
+// Verify 验证 password 是否与 hash 匹配，不匹配时返回 ErrPasswordIncorrect 错误码.
+// hash 使用的算法或参数与配置不同时 needsRehash 为 true，
+// 调用者应在验证通过后使用 Hash 重新计算并保存哈希.
+func (h *PasswordHasher) Verify(hash, password string) (needsRehash bool, err error) {
+	switch {
+	case strings.HasPrefix(hash, "$2"):
+		err = bcrypt.CompareHashAndPassword([]byte(hash), []byte(password))
+		if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+			return false, errors.WrapC(err, ErrPasswordIncorrect, "password mismatch")
+		}
+		if err != nil {
+			return false, err
+		}
+		cost, _ := bcrypt.Cost([]byte(hash))
+
+		return h.config.Algorithm != Bcrypt || cost != h.config.BcryptCost, nil
+	case strings.HasPrefix(hash, "$argon2id$"):
+		return h.verifyArgon2id(hash, password)
+	case strings.HasPrefix(hash, "$scrypt$"):
+		return h.verifyScrypt(hash, password)
+	default:
+		return false, fmt.Errorf("unknown password hash format")
+	}
+}
+
+func (h *PasswordHasher) verifyArgon2id(hash, password string) (bool, error) {
+	var (
+		version int
+		p       Argon2Params
+	)
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return false, fmt.Errorf("malformed argon2id hash")
+	}
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return false, fmt.Errorf("unsupported argon2id version %q", parts[2])
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &p.Memory, &p.Time, &p.Threads); err != nil {
+		return false, fmt.Errorf("malformed argon2id parameters: %w", err)
+	}
+	salt, key, err := decodeSaltAndKey(parts[4], parts[5])
+	if err != nil {
+		return false, err
+	}
+	p.KeyLen = uint32(len(key))
+
+	if !equalKeys(key, argon2.IDKey([]byte(password), salt, p.Time, p.Memory, p.Threads, p.KeyLen)) {
+		return false, errors.WithCode(ErrPasswordIncorrect, "password mismatch")
+	}
+
+	return h.config.Algorithm != Argon2id || p != h.config.Argon2, nil
+}
+
+func (h *PasswordHasher) verifyScrypt(hash, password string) (bool, error) {
+	var (
+		ln int
+		p  ScryptParams
+	)
+	parts := strings.Split(hash, "$")
+	if len(parts) != 5 {
+		return false, fmt.Errorf("malformed scrypt hash")
+	}
+	if _, err := fmt.Sscanf(parts[2], "ln=%d,r=%d,p=%d", &ln, &p.R, &p.P); err != nil || ln <= 0 || ln >= 63 {
+		return false, fmt.Errorf("malformed scrypt parameters %q", parts[2])
+	}
+	salt, key, err := decodeSaltAndKey(parts[3], parts[4])
+	if err != nil {
+		return false, err
+	}
+	p.N, p.KeyLen = 1<<ln, len(key)
+
+	derived, err := scrypt.Key([]byte(password), salt, p.N, p.R, p.P, p.KeyLen)
+	if err != nil {
+		return false, err
+	}
+	if !equalKeys(key, derived) {
+		return false, errors.WithCode(ErrPasswordIncorrect, "password mismatch")
+	}
+
+	return h.config.Algorithm != Scrypt || p != h.config.Scrypt, nil
+}
+
+// PasswordPolicy 组合了密码强度校验、历史密码检查和密码哈希.
+type PasswordPolicy struct {
+	// Hasher 计算和验证密码哈希.
+	Hasher *PasswordHasher
+
+	// HistorySize 是不能重复使用的历史密码个数，为 0 时只检查当前密码.
+	HistorySize int
+
+	// Validate 校验密码强度，为 nil 时使用 validation.IsValidPassword.
+	Validate func(password string) error
+}
+
+// NewPasswordPolicy 返回使用 hasher 并记住 historySize 个历史密码的 PasswordPolicy.
+func NewPasswordPolicy(hasher *PasswordHasher, historySize int) *PasswordPolicy {
+	return &PasswordPolicy{Hasher: hasher, HistorySize: historySize, Validate: validation.IsValidPassword}
+}
+
+// Change 将密码修改为 password. current 是当前密码的哈希，history 是历史密码的哈希，最近的在前.
+// password 强度不足或与当前及历史密码相同时返回 ErrValidation 错误码.
+// 返回新密码的哈希和包含 current 的新历史，历史最多保留 HistorySize 个.
+func (p *PasswordPolicy) Change(password, current string, history []string) (string, []string, error) {
+	validate := p.Validate
+	if validate == nil {
+		validate = validation.IsValidPassword
+	}
+	if err := validate(password); err != nil {
+		return "", nil, errors.WrapC(err, metav1.ErrValidation, "password is too weak")
+	}
+
+	for _, old := range append([]string{current}, history...) {
+		if old == "" {
+			continue
+		}
+		if _, err := p.Hasher.Verify(old, password); err == nil {
+			return "", nil, errors.WithCode(metav1.ErrValidation, "password has been used recently")
+		}
+	}
+
+	hash, err := p.Hasher.Hash(password)
+	if err != nil {
+		return "", nil, err
+	}
+
+	newHistory := history
+	if current != "" {
+		newHistory = append([]string{current}, history...)
+	}
+	if len(newHistory) > p.HistorySize {
+		newHistory = newHistory[:p.HistorySize]
+	}
+
+	return hash, newHistory, nil
+}
+
+// Verify 验证 password 是否与 hash 匹配，参见 PasswordHasher.Verify.
+func (p *PasswordPolicy) Verify(hash, password string) (needsRehash bool, err error) {
+	return p.Hasher.Verify(hash, password)
+}
+
+func encodeBase64(b []byte) string {
+	return base64.RawStdEncoding.EncodeToString(b)
+}
+
+func decodeSaltAndKey(encodedSalt, encodedKey string) ([]byte, []byte, error) {
+	salt, err := base64.RawStdEncoding.DecodeString(encodedSalt)
+	if err != nil {
+		return nil, nil, fmt.Errorf("malformed salt: %w", err)
+	}
+	key, err := base64.RawStdEncoding.DecodeString(encodedKey)
+	if err != nil || len(key) == 0 {
+		return nil, nil, fmt.Errorf("malformed hash")
+	}
+
+	return salt, key, nil
+}
+
+func equalKeys(a, b []byte) bool {
+	return subtle.ConstantTimeCompare(a, b) == 1
+}
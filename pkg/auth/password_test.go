@@ -0,0 +1,133 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+
+	"github.com/gzwillyy/components/errors"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+)
+
+// fastConfig returns a config with cheap parameters to keep the tests fast.
+func fastConfig(alg HashAlgorithm) HasherConfig {
+	return HasherConfig{
+		Algorithm:  alg,
+		BcryptCost: bcrypt.MinCost,
+		Argon2:     Argon2Params{Time: 1, Memory: 1024, Threads: 1, KeyLen: 16},
+		Scrypt:     ScryptParams{N: 1 << 10, R: 8, P: 1, KeyLen: 16},
+	}
+}
+
+func mustHasher(t *testing.T, config HasherConfig) *PasswordHasher {
+	t.Helper()
+
+	h, err := NewPasswordHasher(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+func TestPasswordHasher(t *testing.T) {
+	prefixes := map[HashAlgorithm]string{Bcrypt: "$2a$04$", Argon2id: "$argon2id$v=19$m=1024,t=1,p=1$", Scrypt: "$scrypt$ln=10,r=8,p=1$"}
+	for alg, prefix := range prefixes {
+		h := mustHasher(t, fastConfig(alg))
+		hash, err := h.Hash("Secret@123")
+		if err != nil {
+			t.Fatalf("%s: %v", alg, err)
+		}
+		if !strings.HasPrefix(hash, prefix) {
+			t.Errorf("%s: unexpected hash %s", alg, hash)
+		}
+		if again, _ := h.Hash("Secret@123"); again == hash {
+			t.Errorf("%s: expected a random salt", alg)
+		}
+
+		if needsRehash, err := h.Verify(hash, "Secret@123"); err != nil || needsRehash {
+			t.Errorf("%s: verify: %v %v", alg, needsRehash, err)
+		}
+		if _, err := h.Verify(hash, "Secret@124"); !errors.IsCode(err, ErrPasswordIncorrect) {
+			t.Errorf("%s: expected ErrPasswordIncorrect, got %v", alg, err)
+		}
+		if err := Compare(hash, "Secret@123"); err != nil {
+			t.Errorf("%s: compare: %v", alg, err)
+		}
+	}
+
+	if _, err := mustHasher(t, fastConfig(Bcrypt)).Verify("plain", "plain"); err == nil {
+		t.Errorf("expected an error for an unknown hash format")
+	}
+	if _, err := NewPasswordHasher(HasherConfig{Algorithm: "md5"}); err == nil {
+		t.Errorf("expected an error for an unsupported algorithm")
+	}
+	if _, err := NewPasswordHasher(HasherConfig{Algorithm: Scrypt, Scrypt: ScryptParams{N: 1000, R: 8, P: 1, KeyLen: 16}}); err == nil {
+		t.Errorf("expected an error for N not being a power of 2")
+	}
+}
+
+func TestPasswordHasherNeedsRehash(t *testing.T) {
+	bcryptHash, _ := mustHasher(t, fastConfig(Bcrypt)).Hash("Secret@123")
+	argonHash, _ := mustHasher(t, fastConfig(Argon2id)).Hash("Secret@123")
+
+	stronger := fastConfig(Bcrypt)
+	stronger.BcryptCost = bcrypt.MinCost + 1
+	weakerArgon := fastConfig(Argon2id)
+	weakerArgon.Argon2.Time = 2
+
+	tests := []struct {
+		name   string
+		config HasherConfig
+		hash   string
+		want   bool
+	}{
+		{"same bcrypt cost", fastConfig(Bcrypt), bcryptHash, false},
+		{"outdated bcrypt cost", stronger, bcryptHash, true},
+		{"outdated algorithm", fastConfig(Argon2id), bcryptHash, true},
+		{"same argon2id parameters", fastConfig(Argon2id), argonHash, false},
+		{"outdated argon2id parameters", weakerArgon, argonHash, true},
+		{"argon2id to scrypt", fastConfig(Scrypt), argonHash, true},
+	}
+	for _, tt := range tests {
+		needsRehash, err := mustHasher(t, tt.config).Verify(tt.hash, "Secret@123")
+		if err != nil || needsRehash != tt.want {
+			t.Errorf("%s: got %v %v, want %v", tt.name, needsRehash, err, tt.want)
+		}
+	}
+}
+
+func TestPasswordPolicy(t *testing.T) {
+	policy := NewPasswordPolicy(mustHasher(t, fastConfig(Argon2id)), 2)
+
+	if _, _, err := policy.Change("weak", "", nil); !errors.IsCode(err, metav1.ErrValidation) {
+		t.Errorf("expected ErrValidation for a weak password, got %v", err)
+	}
+
+	current, history, err := policy.Change("Secret@1", "", nil)
+	if err != nil || len(history) != 0 {
+		t.Fatalf("change: %v %v", history, err)
+	}
+	for i, password := range []string{"Secret@2", "Secret@3", "Secret@4"} {
+		if current, history, err = policy.Change(password, current, history); err != nil {
+			t.Fatalf("change %d: %v", i, err)
+		}
+	}
+	if len(history) != 2 {
+		t.Fatalf("expected 2 history entries, got %d", len(history))
+	}
+
+	for _, reused := range []string{"Secret@4", "Secret@3", "Secret@2"} {
+		if _, _, err := policy.Change(reused, current, history); !errors.IsCode(err, metav1.ErrValidation) {
+			t.Errorf("expected %s to be rejected as recently used, got %v", reused, err)
+		}
+	}
+	if _, _, err := policy.Change("Secret@1", current, history); err != nil {
+		t.Errorf("expected a password older than the history to be accepted, got %v", err)
+	}
+
+	if needsRehash, err := policy.Verify(current, "Secret@4"); err != nil || needsRehash {
+		t.Errorf("verify: %v %v", needsRehash, err)
+	}
+}
@@ -0,0 +1,164 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/gzwillyy/components/errors"
+	"github.com/gzwillyy/components/pkg/util/clock"
+)
+
+// Claims 是 TokenService 签发和验证的 JWT 声明.
+type Claims struct {
+	jwt.RegisteredClaims
+
+	// Extra 保存应用自定义的声明.
+	Extra map[string]interface{} `json:"ext,omitempty"`
+}
+
+// TokenConfig 是 TokenService 的配置.
+type TokenConfig struct {
+	// Issuer 是签发 token 的 iss，验证时 token 的 iss 必须与它相同，为空时不检查.
+	Issuer string
+
+	// Audience 是签发 token 的 aud，验证时 token 的 aud 必须包含其中之一，为空时不检查.
+	Audience []string
+
+	// TTL 是签发的 token 的有效期，为 0 时 token 不会过期.
+	TTL time.Duration
+
+	// Leeway 是验证 exp、nbf 和 iat 时允许的时钟偏差.
+	Leeway time.Duration
+
+	// RequireExpiration 要求被验证的 token 设置了 exp.
+	RequireExpiration bool
+
+	// Clock 用于获取当前时间，为 nil 时使用系统时钟，测试时可以使用 clock.FakeClock.
+	Clock clock.PassiveClock
+}
+
+// DefaultTokenConfig 返回默认的配置：token 有效期为 2 小时，允许 1 分钟的时钟偏差，要求设置 exp.
+func DefaultTokenConfig() TokenConfig {
+	return TokenConfig{
+		TTL:               2 * time.Hour,
+		Leeway:            time.Minute,
+		RequireExpiration: true,
+	}
+}
+
+// TokenService 使用 KeySet 中的密钥签发和验证 JWT.
+// 签发时使用签名密钥并将它的 ID 写入 kid，验证时按 kid 查找密钥，
+// 并要求 token 的算法与密钥的算法相同.
+type TokenService struct {
+	keys   *KeySet
+	config TokenConfig
+}
+
+// NewTokenService 返回使用 keys 签发和验证 token 的 TokenService.
+func NewTokenService(keys *KeySet, config TokenConfig) *TokenService {
+	if config.Clock == nil {
+		config.Clock = clock.RealClock{}
+	}
+
+	return &TokenService{keys: keys, config: config}
+}
+
+// Keys 返回 TokenService 使用的密钥集合，可用于轮换密钥.
+func (s *TokenService) Keys() *KeySet {
+	return s.keys
+}
+
+// Sign 使用签名密钥签发包含 claims 的 token.
+// claims 中未设置的 iss、aud、iat、nbf 和 exp 根据配置和当前时间补全.
+func (s *TokenService) Sign(claims *Claims) (string, error) {
+	key, ok := s.keys.SigningKey()
+	if !ok {
+		return "", fmt.Errorf("no signing key")
+	}
+
+	c := *claims
+	now := s.config.Clock.Now()
+	if c.Issuer == "" {
+		c.Issuer = s.config.Issuer
+	}
+	if len(c.Audience) == 0 {
+		c.Audience = s.config.Audience
+	}
+	if c.IssuedAt == nil {
+		c.IssuedAt = jwt.NewNumericDate(now)
+	}
+	if c.NotBefore == nil {
+		c.NotBefore = jwt.NewNumericDate(now)
+	}
+	if c.ExpiresAt == nil && s.config.TTL > 0 {
+		c.ExpiresAt = jwt.NewNumericDate(now.Add(s.config.TTL))
+	}
+
+	token := jwt.NewWithClaims(jwt.GetSigningMethod(key.Algorithm), &c)
+	token.Header["kid"] = key.ID
+
+	return token.SignedString(key.signKey)
+}
+
+// Verify 验证 token 的签名以及 exp、nbf、iat、iss 和 aud 声明，返回 token 中的声明.
+// 过期的 token 返回 ErrTokenExpired 错误码，其他无效的 token 返回 ErrTokenInvalid 错误码.
+func (s *TokenService) Verify(token string) (*Claims, error) {
+	opts := []jwt.ParserOption{
+		jwt.WithLeeway(s.config.Leeway),
+		jwt.WithTimeFunc(s.config.Clock.Now),
+		jwt.WithIssuedAt(),
+	}
+	if s.config.Issuer != "" {
+		opts = append(opts, jwt.WithIssuer(s.config.Issuer))
+	}
+	if s.config.RequireExpiration {
+		opts = append(opts, jwt.WithExpirationRequired())
+	}
+
+	claims := &Claims{}
+	if _, err := jwt.ParseWithClaims(token, claims, s.keyFunc, opts...); err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, errors.WrapC(err, ErrTokenExpired, "token expired")
+		}
+
+		return nil, errors.WrapC(err, ErrTokenInvalid, "invalid token")
+	}
+	if !s.acceptsAudience(claims.Audience) {
+		return nil, errors.WithCode(ErrTokenInvalid, "token audience %v is not accepted", []string(claims.Audience))
+	}
+
+	return claims, nil
+}
+
+// keyFunc 按 kid 查找验证 token 的密钥.
+func (s *TokenService) keyFunc(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	key, ok := s.keys.Lookup(kid)
+	if !ok {
+		return nil, fmt.Errorf("unknown key %q", kid)
+	}
+	// 算法必须与密钥一致，防止用公钥作为 HMAC 密钥伪造 token
+	if token.Method.Alg() != key.Algorithm {
+		return nil, fmt.Errorf("key %q requires algorithm %s, got %s", kid, key.Algorithm, token.Method.Alg())
+	}
+
+	return key.verifyKey, nil
+}
+
+// acceptsAudience 判断 token 的 aud 是否包含配置的 Audience 之一.
+func (s *TokenService) acceptsAudience(audience jwt.ClaimStrings) bool {
+	if len(s.config.Audience) == 0 {
+		return true
+	}
+	for _, want := range s.config.Audience {
+		for _, aud := range audience {
+			if aud == want {
+				return true
+			}
+		}
+	}
+
+	return false
+}
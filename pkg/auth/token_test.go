@@ -0,0 +1,287 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+
+	"github.com/gzwillyy/components/errors"
+	"github.com/gzwillyy/components/log"
+	"github.com/gzwillyy/components/pkg/util/clock"
+)
+
+func mustKeySet(t *testing.T, keys ...*Key) *KeySet {
+	t.Helper()
+
+	set, err := NewKeySet(keys...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return set
+}
+
+func mustHMACKey(t *testing.T, id string) *Key {
+	t.Helper()
+
+	key, err := NewHMACKey(id, "HS256", []byte("secret-"+id))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return key
+}
+
+func TestTokenServiceAlgorithms(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	ecKey384, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	_, edKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	tests := []struct {
+		alg     string
+		private crypto.Signer
+	}{
+		{"RS256", rsaKey},
+		{"RS512", rsaKey},
+		{"PS256", rsaKey},
+		{"ES256", ecKey},
+		{"ES384", ecKey384},
+		{"EdDSA", edKey},
+	}
+	for _, tt := range tests {
+		key, err := NewPrivateKey("k1", tt.alg, tt.private)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.alg, err)
+		}
+		s := NewTokenService(mustKeySet(t, key), DefaultTokenConfig())
+		token, err := s.Sign(&Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "colin"}})
+		if err != nil {
+			t.Fatalf("%s: sign: %v", tt.alg, err)
+		}
+
+		// a service holding only the public key verifies the token
+		public, err := NewPublicKey("k1", tt.alg, tt.private.Public())
+		if err != nil {
+			t.Fatalf("%s: %v", tt.alg, err)
+		}
+		claims, err := NewTokenService(mustKeySet(t, public), DefaultTokenConfig()).Verify(token)
+		if err != nil || claims.Subject != "colin" {
+			t.Errorf("%s: verify: %v %+v", tt.alg, err, claims)
+		}
+	}
+
+	for _, alg := range []string{"HS256", "HS384", "HS512"} {
+		key, err := NewHMACKey("k1", alg, []byte("secret"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		s := NewTokenService(mustKeySet(t, key), DefaultTokenConfig())
+		token, _ := s.Sign(&Claims{Extra: map[string]interface{}{"role": "admin"}})
+		if claims, err := s.Verify(token); err != nil || claims.Extra["role"] != "admin" {
+			t.Errorf("%s: verify: %v %+v", alg, err, claims)
+		}
+	}
+
+	if _, err := NewPrivateKey("k1", "ES256", ecKey384); err == nil {
+		t.Errorf("expected an error for a curve not matching the algorithm")
+	}
+	if _, err := NewPrivateKey("k1", "RS256", ecKey); err == nil {
+		t.Errorf("expected an error for a key type not matching the algorithm")
+	}
+	if _, err := NewHMACKey("k1", "RS256", []byte("secret")); err == nil {
+		t.Errorf("expected an error for a HMAC key with an asymmetric algorithm")
+	}
+}
+
+func TestTokenServiceValidation(t *testing.T) {
+	now := time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC)
+	fakeClock := clock.NewFakeClock(now)
+	config := TokenConfig{
+		Issuer:            "iam-apiserver",
+		Audience:          []string{"iam.api"},
+		TTL:               time.Hour,
+		Leeway:            time.Minute,
+		RequireExpiration: true,
+		Clock:             fakeClock,
+	}
+	s := NewTokenService(mustKeySet(t, mustHMACKey(t, "k1")), config)
+
+	token, err := s.Sign(&Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "colin"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := s.Verify(token)
+	if err != nil {
+		t.Fatalf("verify: %v", err)
+	}
+	if claims.Issuer != "iam-apiserver" || !claims.ExpiresAt.Equal(now.Add(time.Hour)) || !claims.IssuedAt.Equal(now) {
+		t.Errorf("unexpected claims %+v", claims.RegisteredClaims)
+	}
+
+	// expired tokens are accepted within the leeway
+	fakeClock.SetTime(now.Add(time.Hour + 30*time.Second))
+	if _, err := s.Verify(token); err != nil {
+		t.Errorf("expected the token to be valid within the leeway, got %v", err)
+	}
+	fakeClock.SetTime(now.Add(time.Hour + 2*time.Minute))
+	if _, err := s.Verify(token); !errors.IsCode(err, ErrTokenExpired) {
+		t.Errorf("expected ErrTokenExpired, got %v", err)
+	}
+	fakeClock.SetTime(now)
+
+	sign := func(c jwt.RegisteredClaims) string {
+		token, err := s.Sign(&Claims{RegisteredClaims: c})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		return token
+	}
+	other := NewTokenService(mustKeySet(t, mustHMACKey(t, "k2")), config)
+	otherToken, _ := other.Sign(&Claims{})
+	forged, _ := NewTokenService(mustKeySet(t, &Key{ID: "k1", Algorithm: "HS256", signKey: []byte("forged")}), config).Sign(&Claims{})
+	noExpiry := NewTokenService(s.Keys(), TokenConfig{Clock: fakeClock})
+	noExpiryToken, _ := noExpiry.Sign(&Claims{})
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{"not yet valid", sign(jwt.RegisteredClaims{NotBefore: jwt.NewNumericDate(now.Add(2 * time.Minute))})},
+		{"issued in the future", sign(jwt.RegisteredClaims{IssuedAt: jwt.NewNumericDate(now.Add(2 * time.Minute))})},
+		{"wrong issuer", sign(jwt.RegisteredClaims{Issuer: "someone"})},
+		{"wrong audience", sign(jwt.RegisteredClaims{Audience: jwt.ClaimStrings{"other.api"}})},
+		{"unknown key", otherToken},
+		{"invalid signature", forged},
+		{"no expiration", noExpiryToken},
+		{"garbage", "not.a.token"},
+	}
+	for _, tt := range tests {
+		if _, err := s.Verify(tt.token); !errors.IsCode(err, ErrTokenInvalid) {
+			t.Errorf("%s: expected ErrTokenInvalid, got %v", tt.name, err)
+		}
+	}
+
+	// tokens within the leeway of nbf are accepted
+	if _, err := s.Verify(sign(jwt.RegisteredClaims{NotBefore: jwt.NewNumericDate(now.Add(30 * time.Second))})); err != nil {
+		t.Errorf("expected the token to be valid within the leeway, got %v", err)
+	}
+}
+
+func TestTokenServiceAlgorithmConfusion(t *testing.T) {
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	public, _ := NewPublicKey("k1", "RS256", &rsaKey.PublicKey)
+	s := NewTokenService(mustKeySet(t, public), DefaultTokenConfig())
+
+	// a HMAC token signed with the key ID of the RSA key is rejected
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{
+		RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))},
+	})
+	token.Header["kid"] = "k1"
+	signed, _ := token.SignedString([]byte("secret"))
+	if _, err := s.Verify(signed); !errors.IsCode(err, ErrTokenInvalid) {
+		t.Errorf("expected ErrTokenInvalid, got %v", err)
+	}
+
+	if _, err := s.Sign(&Claims{}); err == nil {
+		t.Errorf("expected an error signing without a signing key")
+	}
+}
+
+func TestKeyRotation(t *testing.T) {
+	keys := mustKeySet(t, mustHMACKey(t, "k1"))
+	s := NewTokenService(keys, DefaultTokenConfig())
+	old, _ := s.Sign(&Claims{})
+
+	if err := keys.Rotate(mustHMACKey(t, "k2")); err != nil {
+		t.Fatal(err)
+	}
+	rotated, _ := s.Sign(&Claims{})
+	token, _, _ := jwt.NewParser().ParseUnverified(rotated, &Claims{})
+	if token.Header["kid"] != "k2" {
+		t.Errorf("expected tokens to be signed with k2, got %v", token.Header["kid"])
+	}
+	for _, token := range []string{old, rotated} {
+		if _, err := s.Verify(token); err != nil {
+			t.Errorf("expected token to be valid after rotation, got %v", err)
+		}
+	}
+
+	if err := keys.Remove("k2"); err == nil {
+		t.Errorf("expected an error removing the signing key")
+	}
+	if err := keys.Remove("k1"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Verify(old); !errors.IsCode(err, ErrTokenInvalid) {
+		t.Errorf("expected tokens of removed keys to be rejected, got %v", err)
+	}
+	if err := keys.Add(mustHMACKey(t, "k2")); err == nil {
+		t.Errorf("expected an error adding a duplicate key")
+	}
+}
+
+func TestSign(t *testing.T) {
+	token := Sign("secret-id", "secret-key", "iam-apiserver", "iam.api")
+
+	keys := mustKeySet(t, &Key{ID: "secret-id", Algorithm: "HS256", verifyKey: []byte("secret-key")})
+	s := NewTokenService(keys, TokenConfig{Issuer: "iam-apiserver", Audience: []string{"iam.api"}})
+	claims, err := s.Verify(token)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if d := claims.ExpiresAt.Sub(claims.IssuedAt.Time); d != time.Minute {
+		t.Errorf("expected the token to expire after a minute, got %v", d)
+	}
+}
+
+func TestJWTAuth(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	s := NewTokenService(mustKeySet(t, mustHMACKey(t, "k1")), DefaultTokenConfig())
+
+	engine := gin.New()
+	engine.GET("/", JWTAuth(s), func(c *gin.Context) {
+		claims, _ := GetClaims(c)
+		fromContext, _ := ClaimsFromContext(c.Request.Context())
+		username, _ := c.Request.Context().Value(log.KeyUsername).(string)
+		if claims != fromContext || username != claims.Subject {
+			t.Errorf("expected the claims in the gin and request context")
+		}
+		c.String(http.StatusOK, claims.Subject)
+	})
+
+	token, _ := s.Sign(&Claims{RegisteredClaims: jwt.RegisteredClaims{Subject: "colin"}})
+	tests := []struct {
+		header string
+		status int
+		body   string
+	}{
+		{"Bearer " + token, http.StatusOK, "colin"},
+		{"bearer " + token, http.StatusOK, "colin"},
+		{"", http.StatusUnauthorized, ""},
+		{"Basic Y29saW46cGFzcw==", http.StatusUnauthorized, ""},
+		{"Bearer invalid", http.StatusUnauthorized, ""},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		if tt.header != "" {
+			req.Header.Set("Authorization", tt.header)
+		}
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		if w.Code != tt.status || (tt.body != "" && w.Body.String() != tt.body) {
+			t.Errorf("%q: got %d %s", tt.header, w.Code, w.Body.String())
+		}
+	}
+}
@@ -0,0 +1,181 @@
+package authorizer
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/gzwillyy/components/pkg/labels"
+	"github.com/gzwillyy/components/pkg/selection"
+)
+
+// Attribute names of the ABAC expressions.
+const (
+	AttributeSubject  = "subject.name"
+	AttributeGroup    = "subject.group"
+	AttributeVerb     = "verb"
+	AttributeAPIGroup = "apiGroup"
+	AttributeVersion  = "version"
+	AttributeResource = "resource"
+	AttributeName     = "name"
+)
+
+// Effect is the decision of a matching ABAC rule.
+type Effect string
+
+const (
+	// EffectAllow allows the matched requests.
+	EffectAllow Effect = "Allow"
+
+	// EffectDeny denies the matched requests, deny rules take precedence over allow rules.
+	EffectDeny Effect = "Deny"
+)
+
+// ABACRule applies its effect to the requests whose attributes match the expression. The
+// expression has the syntax of a label selector evaluated against the attributes of the request,
+// e.g. "verb in (get,list),resource=secrets,subject.group=developers". Values aren't restricted to
+// label values, "subject.name=alice@example.com" is valid. Requirements on subject.group apply to
+// all groups of the subject: "=", "in" and "subject.group" match if one of the groups matches,
+// "!=", "notin" and "!subject.group" only if none does.
+type ABACRule struct {
+	Name       string `yaml:"name" json:"name"`
+	Effect     Effect `yaml:"effect" json:"effect"`
+	Expression string `yaml:"expression" json:"expression"`
+}
+
+// ABACPolicy is the list of rules of an ABAC authorizer.
+//
+//	rules:
+//	- name: developers-read
+//	  effect: Allow
+//	  expression: subject.group=developers,verb in (get,list)
+//	- name: protect-admin
+//	  effect: Deny
+//	  expression: resource=users,name=admin,verb in (update,patch,delete)
+type ABACPolicy struct {
+	Rules []ABACRule `yaml:"rules" json:"rules"`
+}
+
+type abacRule struct {
+	ABACRule
+	requirements labels.Requirements
+}
+
+// ABAC decides requests with attribute expressions. Deny rules are evaluated first, it has no
+// opinion on requests no rule matches.
+type ABAC struct {
+	deny  []abacRule
+	allow []abacRule
+}
+
+var _ Authorizer = &ABAC{}
+
+// NewABAC returns an ABAC authorizer for policy, the expressions are parsed once.
+func NewABAC(policy ABACPolicy) (*ABAC, error) {
+	a := &ABAC{}
+	for _, rule := range policy.Rules {
+		selector, err := labels.ParseAnyValues(rule.Expression)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: invalid expression: %w", rule.Name, err)
+		}
+		requirements, _ := selector.Requirements()
+
+		switch rule.Effect {
+		case EffectAllow:
+			a.allow = append(a.allow, abacRule{ABACRule: rule, requirements: requirements})
+		case EffectDeny:
+			a.deny = append(a.deny, abacRule{ABACRule: rule, requirements: requirements})
+		default:
+			return nil, fmt.Errorf("rule %q: unknown effect %q", rule.Name, rule.Effect)
+		}
+	}
+
+	return a, nil
+}
+
+// LoadABAC reads an ABACPolicy in YAML from reader and returns its authorizer.
+func LoadABAC(reader io.Reader) (*ABAC, error) {
+	var policy ABACPolicy
+	decoder := yaml.NewDecoder(reader)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&policy); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid ABAC policy: %w", err)
+	}
+
+	return NewABAC(policy)
+}
+
+// Authorize implements Authorizer.
+func (a *ABAC) Authorize(_ context.Context, attrs Attributes) (Decision, string, error) {
+	set := attributeSet(attrs)
+	for _, rule := range a.deny {
+		if rule.matches(set, attrs.Subject.Groups) {
+			return DecisionDeny, fmt.Sprintf("denied by ABAC rule %q", rule.Name), nil
+		}
+	}
+	for _, rule := range a.allow {
+		if rule.matches(set, attrs.Subject.Groups) {
+			return DecisionAllow, fmt.Sprintf("allowed by ABAC rule %q", rule.Name), nil
+		}
+	}
+
+	return DecisionNoOpinion, "no ABAC rule matches the request", nil
+}
+
+// attributeSet returns the attributes of the request except the groups of the subject.
+func attributeSet(attrs Attributes) labels.Set {
+	set := labels.Set{
+		AttributeSubject:  attrs.Subject.Name,
+		AttributeVerb:     attrs.Verb,
+		AttributeAPIGroup: attrs.Resource.Group,
+		AttributeVersion:  attrs.Resource.Version,
+		AttributeResource: attrs.Resource.Resource,
+	}
+	if attrs.Name != "" {
+		set[AttributeName] = attrs.Name
+	}
+
+	return set
+}
+
+// matches reports whether all requirements of the rule match the attributes set and the groups.
+func (r *abacRule) matches(set labels.Set, groups []string) bool {
+	for i := range r.requirements {
+		req := &r.requirements[i]
+		if req.Key() != AttributeGroup {
+			if !req.Matches(set) {
+				return false
+			}
+
+			continue
+		}
+		if !matchesGroups(req, groups) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// matchesGroups evaluates a requirement on subject.group over all groups. Negative requirements
+// must hold for every group, the others for one of them.
+func matchesGroups(req *labels.Requirement, groups []string) bool {
+	if len(groups) == 0 {
+		return req.Matches(labels.Set{})
+	}
+
+	negative := false
+	switch req.Operator() {
+	case selection.NotIn, selection.NotEquals, selection.DoesNotExist:
+		negative = true
+	}
+	for _, group := range groups {
+		if req.Matches(labels.Set{AttributeGroup: group}) != negative {
+			return !negative
+		}
+	}
+
+	return negative
+}
@@ -0,0 +1,129 @@
+// Package authorizer decides whether a subject may perform a verb on a resource. It provides RBAC
+// and ABAC authorizers loaded from YAML, a gin middleware protecting the routes of a resource and
+// audit hooks observing the decisions.
+package authorizer
+
+import (
+	"context"
+
+	"github.com/gzwillyy/components/pkg/scheme"
+)
+
+// Decision is the result of an authorization.
+type Decision int
+
+const (
+	// DecisionNoOpinion means the authorizer has no rule for the request, other authorizers may
+	// decide. A request nobody allows is denied.
+	DecisionNoOpinion Decision = iota
+
+	// DecisionAllow means the request is allowed.
+	DecisionAllow
+
+	// DecisionDeny means the request is denied, other authorizers aren't asked.
+	DecisionDeny
+)
+
+// String returns the name of the decision.
+func (d Decision) String() string {
+	switch d {
+	case DecisionAllow:
+		return "Allow"
+	case DecisionDeny:
+		return "Deny"
+	default:
+		return "NoOpinion"
+	}
+}
+
+// Verbs of the requests served by rest.Resource.
+const (
+	VerbCreate = "create"
+	VerbGet    = "get"
+	VerbList   = "list"
+	VerbUpdate = "update"
+	VerbPatch  = "patch"
+	VerbDelete = "delete"
+)
+
+// Subject is the authenticated user a request is made by.
+type Subject struct {
+	// Name is the user name.
+	Name string
+
+	// Groups are the groups the user belongs to.
+	Groups []string
+}
+
+// Attributes describe a request to authorize.
+type Attributes struct {
+	Subject Subject
+
+	// Verb is the operation, e.g. get, list, create, update, patch or delete.
+	Verb string
+
+	// Resource is the resource the request operates on.
+	Resource scheme.GroupVersionResource
+
+	// Name is the name of the object, empty for create and list.
+	Name string
+}
+
+// Authorizer decides whether a request is allowed. The reason explains the decision, it is
+// returned to the client for denied requests and recorded by audit hooks.
+type Authorizer interface {
+	Authorize(ctx context.Context, attrs Attributes) (decision Decision, reason string, err error)
+}
+
+// AuthorizerFunc is a function implementing Authorizer.
+type AuthorizerFunc func(ctx context.Context, attrs Attributes) (Decision, string, error)
+
+// Authorize implements Authorizer.
+func (f AuthorizerFunc) Authorize(ctx context.Context, attrs Attributes) (Decision, string, error) {
+	return f(ctx, attrs)
+}
+
+// Union returns an authorizer asking authorizers in order, the first allow or deny decision is
+// returned. Errors are returned together with a no opinion decision if no other authorizer
+// decides.
+func Union(authorizers ...Authorizer) Authorizer {
+	return AuthorizerFunc(func(ctx context.Context, attrs Attributes) (Decision, string, error) {
+		var firstErr error
+		for _, a := range authorizers {
+			decision, reason, err := a.Authorize(ctx, attrs)
+			if err != nil && firstErr == nil {
+				firstErr = err
+			}
+			if decision != DecisionNoOpinion {
+				return decision, reason, nil
+			}
+		}
+
+		return DecisionNoOpinion, "no rule allows the request", firstErr
+	})
+}
+
+type subjectContextKey struct{}
+
+// WithSubject returns a context carrying subject, the middleware authorizes requests for it.
+func WithSubject(ctx context.Context, subject Subject) context.Context {
+	return context.WithValue(ctx, subjectContextKey{}, subject)
+}
+
+// SubjectFromContext returns the subject stored with WithSubject.
+func SubjectFromContext(ctx context.Context) (Subject, bool) {
+	subject, ok := ctx.Value(subjectContextKey{}).(Subject)
+
+	return subject, ok
+}
+
+// matches reports whether list contains value or the wildcard "*".
+func matches(list []string, value string) bool {
+	for _, item := range list {
+		if item == "*" || item == value {
+			return true
+		}
+	}
+
+	return false
+}
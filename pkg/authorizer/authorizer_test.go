@@ -0,0 +1,243 @@
+package authorizer
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gzwillyy/components/log"
+	"github.com/gzwillyy/components/pkg/core"
+	"github.com/gzwillyy/components/pkg/json"
+	"github.com/gzwillyy/components/pkg/scheme"
+)
+
+var secrets = scheme.GroupVersionResource{Group: "iam.api", Version: "v1", Resource: "secrets"}
+
+const rbacPolicy = `
+roles:
+- name: secret-reader
+  rules:
+  - apiGroups: ["iam.api"]
+    resources: ["secrets"]
+    verbs: ["get", "list"]
+- name: colin-editor
+  rules:
+  - apiGroups: ["*"]
+    resources: ["secrets"]
+    verbs: ["update", "patch"]
+    resourceNames: ["colin"]
+bindings:
+- name: developers-read
+  role: secret-reader
+  subjects:
+  - kind: Group
+    name: developers
+- name: colin-edit
+  role: colin-editor
+  subjects:
+  - kind: User
+    name: colin
+`
+
+func attrs(user string, groups []string, verb, name string) Attributes {
+	return Attributes{Subject: Subject{Name: user, Groups: groups}, Verb: verb, Resource: secrets, Name: name}
+}
+
+func TestRBAC(t *testing.T) {
+	a, err := LoadRBAC(strings.NewReader(rbacPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		attrs Attributes
+		want  Decision
+	}{
+		{"group member lists", attrs("lisa", []string{"developers"}, VerbList, ""), DecisionAllow},
+		{"group member gets", attrs("lisa", []string{"ops", "developers"}, VerbGet, "colin"), DecisionAllow},
+		{"group member deletes", attrs("lisa", []string{"developers"}, VerbDelete, "colin"), DecisionNoOpinion},
+		{"user updates named object", attrs("colin", nil, VerbUpdate, "colin"), DecisionAllow},
+		{"user updates other object", attrs("colin", nil, VerbUpdate, "lisa"), DecisionNoOpinion},
+		{"user creates", attrs("colin", nil, VerbCreate, ""), DecisionNoOpinion},
+		{"other group", attrs("tom", []string{"ops"}, VerbGet, "colin"), DecisionNoOpinion},
+		{"other resource", Attributes{Subject: Subject{Groups: []string{"developers"}}, Verb: VerbGet,
+			Resource: scheme.GroupVersionResource{Group: "iam.api", Version: "v1", Resource: "users"}}, DecisionNoOpinion},
+	}
+	for _, tt := range tests {
+		decision, reason, err := a.Authorize(context.Background(), tt.attrs)
+		if err != nil || decision != tt.want {
+			t.Errorf("%s: got %s (%s) %v, want %s", tt.name, decision, reason, err, tt.want)
+		}
+	}
+
+	invalid := []string{
+		"bindings:\n- name: b\n  role: missing\n",
+		"roles:\n- name: r\n- name: r\n",
+		"roles:\n- name: r\nbindings:\n- name: b\n  role: r\n  subjects:\n  - kind: Robot\n    name: x\n",
+		"roles:\n- name: r\n  permissions: []\n",
+		"roles:\n- name: r\nbindings:\n- name: b\n  role: r\n  subjects:\n  - kind: User\n",
+		"roles:\n- name: r\nbindings:\n- name: b\n  role: r\n  subjects:\n  - kind: Group\n    name: \"\"\n",
+	}
+	for _, policy := range invalid {
+		if _, err := LoadRBAC(strings.NewReader(policy)); err == nil {
+			t.Errorf("expected an error for policy %q", policy)
+		}
+	}
+}
+
+func TestABAC(t *testing.T) {
+	a, err := LoadABAC(strings.NewReader(`
+rules:
+- name: developers-read
+  effect: Allow
+  expression: subject.group=developers,verb in (get,list)
+- name: admin-all
+  effect: Allow
+  expression: subject.name=admin
+- name: protect-root
+  effect: Deny
+  expression: resource=secrets,name=root,verb!=get
+- name: public-read-non-admins
+  effect: Allow
+  expression: name=public,verb=get,subject.group notin (admins)
+- name: email-subject
+  effect: Allow
+  expression: subject.name=alice@example.com,subject.group in (system:readers)
+`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name  string
+		attrs Attributes
+		want  Decision
+	}{
+		{"group member reads", attrs("lisa", []string{"ops", "developers"}, VerbGet, "colin"), DecisionAllow},
+		{"group member writes", attrs("lisa", []string{"developers"}, VerbUpdate, "colin"), DecisionNoOpinion},
+		{"admin writes", attrs("admin", nil, VerbDelete, "colin"), DecisionAllow},
+		{"admin deletes protected object", attrs("admin", nil, VerbDelete, "root"), DecisionDeny},
+		{"admin reads protected object", attrs("admin", nil, VerbGet, "root"), DecisionAllow},
+		{"no group", attrs("lisa", nil, VerbGet, "colin"), DecisionNoOpinion},
+		{"not in group", attrs("lisa", []string{"ops"}, VerbGet, "public"), DecisionAllow},
+		{"one of the groups excluded", attrs("lisa", []string{"ops", "admins"}, VerbGet, "public"), DecisionNoOpinion},
+		{"any values", attrs("alice@example.com", []string{"ops", "system:readers"}, VerbUpdate, "colin"), DecisionAllow},
+	}
+	for _, tt := range tests {
+		decision, reason, err := a.Authorize(context.Background(), tt.attrs)
+		if err != nil || decision != tt.want {
+			t.Errorf("%s: got %s (%s) %v, want %s", tt.name, decision, reason, err, tt.want)
+		}
+	}
+
+	if _, err := NewABAC(ABACPolicy{Rules: []ABACRule{{Name: "r", Effect: "Maybe", Expression: "verb=get"}}}); err == nil {
+		t.Errorf("expected an error for an unknown effect")
+	}
+	if _, err := NewABAC(ABACPolicy{Rules: []ABACRule{{Name: "r", Effect: EffectAllow, Expression: "verb in (get"}}}); err == nil {
+		t.Errorf("expected an error for an invalid expression")
+	}
+}
+
+func TestUnion(t *testing.T) {
+	deny := AuthorizerFunc(func(context.Context, Attributes) (Decision, string, error) {
+		return DecisionDeny, "denied", nil
+	})
+	noOpinion := AuthorizerFunc(func(context.Context, Attributes) (Decision, string, error) {
+		return DecisionNoOpinion, "", fmt.Errorf("unavailable")
+	})
+	allow := AuthorizerFunc(func(context.Context, Attributes) (Decision, string, error) {
+		return DecisionAllow, "allowed", nil
+	})
+
+	if decision, _, err := Union(noOpinion, allow, deny).Authorize(context.Background(), Attributes{}); decision != DecisionAllow || err != nil {
+		t.Errorf("expected the first decision to win, got %s %v", decision, err)
+	}
+	if decision, _, _ := Union(deny, allow).Authorize(context.Background(), Attributes{}); decision != DecisionDeny {
+		t.Errorf("expected deny, got %s", decision)
+	}
+	if decision, _, err := Union(noOpinion).Authorize(context.Background(), Attributes{}); decision != DecisionNoOpinion || err == nil {
+		t.Errorf("expected no opinion and the error, got %s %v", decision, err)
+	}
+}
+
+func TestMiddleware(t *testing.T) {
+	rbac, err := LoadRBAC(strings.NewReader(rbacPolicy))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var audited []string
+	audit := func(_ context.Context, attrs Attributes, decision Decision, _ string, _ error) {
+		audited = append(audited, fmt.Sprintf("%s %s %s %s", attrs.Subject.Name, attrs.Verb, attrs.Name, decision))
+	}
+
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(func(c *gin.Context) {
+		ctx := c.Request.Context()
+		if user := c.GetHeader("X-User"); user != "" {
+			ctx = context.WithValue(ctx, log.KeyUsername, user)
+		}
+		if group := c.GetHeader("X-Group"); group != "" {
+			ctx = WithSubject(ctx, Subject{Name: c.GetHeader("X-User"), Groups: []string{group}})
+		}
+		c.Request = c.Request.WithContext(ctx)
+	})
+	group := engine.Group("/v1", Middleware(WithAudit(rbac, audit, LogAudit), secrets))
+	ok := func(c *gin.Context) { c.Status(http.StatusOK) }
+	group.GET("/secrets", ok)
+	group.GET("/secrets/:name", ok)
+	group.PUT("/secrets/:name", ok)
+	group.DELETE("/secrets/:name", ok)
+
+	tests := []struct {
+		method, path, user, group string
+		status                    int
+	}{
+		{http.MethodGet, "/v1/secrets", "lisa", "developers", http.StatusOK},
+		{http.MethodGet, "/v1/secrets/colin", "lisa", "developers", http.StatusOK},
+		{http.MethodDelete, "/v1/secrets/colin", "lisa", "developers", http.StatusForbidden},
+		{http.MethodPut, "/v1/secrets/colin", "colin", "", http.StatusOK},
+		{http.MethodPut, "/v1/secrets/lisa", "colin", "", http.StatusForbidden},
+		{http.MethodGet, "/v1/secrets", "", "", http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		req := httptest.NewRequest(tt.method, tt.path, nil)
+		req.Header.Set("X-User", tt.user)
+		req.Header.Set("X-Group", tt.group)
+		w := httptest.NewRecorder()
+		engine.ServeHTTP(w, req)
+		if w.Code != tt.status {
+			t.Errorf("%s %s as %q: got %d, want %d", tt.method, tt.path, tt.user, w.Code, tt.status)
+		}
+		if w.Code == http.StatusForbidden {
+			var resp core.ErrResponse
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil || resp.Code != ErrForbidden {
+				t.Errorf("%s %s: expected code ErrForbidden, got %+v", tt.method, tt.path, resp)
+			}
+		}
+	}
+
+	want := []string{
+		"lisa list  Allow", "lisa get colin Allow", "lisa delete colin NoOpinion",
+		"colin update colin Allow", "colin update lisa NoOpinion", " list  NoOpinion",
+	}
+	if strings.Join(audited, "|") != strings.Join(want, "|") {
+		t.Errorf("unexpected audit records %q", audited)
+	}
+
+	failing := gin.New()
+	failing.GET("/", Middleware(AuthorizerFunc(func(context.Context, Attributes) (Decision, string, error) {
+		return DecisionNoOpinion, "", fmt.Errorf("policy store unavailable")
+	}), secrets), ok)
+	w := httptest.NewRecorder()
+	failing.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 for an authorizer error, got %d", w.Code)
+	}
+}
@@ -0,0 +1,19 @@
+package authorizer
+
+import (
+	"net/http"
+
+	"github.com/gzwillyy/components/errors"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+)
+
+// Error codes returned by the authorizer package. They are registered with errors.MustRegister, so
+// core.WriteResponse maps them to the listed HTTP status.
+const (
+	// ErrForbidden - 403: Permission denied.
+	ErrForbidden int = iota + 100917
+)
+
+func init() {
+	errors.MustRegister(&metav1.ErrCode{C: ErrForbidden, HTTP: http.StatusForbidden, Ext: "Permission denied"})
+}
@@ -0,0 +1,120 @@
+package authorizer
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gzwillyy/components/errors"
+	"github.com/gzwillyy/components/log"
+	"github.com/gzwillyy/components/pkg/core"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/scheme"
+)
+
+// AuditHook observes the decisions of an authorizer. err is the error of the authorizer, if any.
+type AuditHook func(ctx context.Context, attrs Attributes, decision Decision, reason string, err error)
+
+// WithAudit returns an authorizer calling the hooks with every decision of a.
+func WithAudit(a Authorizer, hooks ...AuditHook) Authorizer {
+	return AuthorizerFunc(func(ctx context.Context, attrs Attributes) (Decision, string, error) {
+		decision, reason, err := a.Authorize(ctx, attrs)
+		for _, hook := range hooks {
+			hook(ctx, attrs, decision, reason, err)
+		}
+
+		return decision, reason, err
+	})
+}
+
+// LogAudit is an AuditHook logging the decisions with the logger of the context. Allowed requests
+// are logged at info level, others at warn level.
+func LogAudit(ctx context.Context, attrs Attributes, decision Decision, reason string, err error) {
+	keysAndValues := []interface{}{
+		"subject", attrs.Subject.Name,
+		"groups", attrs.Subject.Groups,
+		"verb", attrs.Verb,
+		"resource", attrs.Resource.String(),
+		"name", attrs.Name,
+		"decision", decision.String(),
+		"reason", reason,
+	}
+	if err != nil {
+		keysAndValues = append(keysAndValues, "error", err.Error())
+	}
+
+	if decision == DecisionAllow {
+		log.L(ctx).Infow("Authorization decision", keysAndValues...)
+	} else {
+		log.L(ctx).Warnw("Authorization decision", keysAndValues...)
+	}
+}
+
+// Middleware returns a gin middleware authorizing the requests of the routes installed by
+// rest.Resource for resource. The subject is read from the request context, see WithSubject, and
+// defaults to the user name stored under log.KeyUsername, e.g. by auth.JWTAuth. The verb is derived
+// from the HTTP method and the name from the ":name" route parameter. Requests that aren't allowed
+// are aborted with ErrForbidden, errors of the authorizer with ErrInternal.
+//
+//	group := engine.Group("/v1/secrets", authorizer.Middleware(a, gvr))
+func Middleware(a Authorizer, resource scheme.GroupVersionResource) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := c.Request.Context()
+		attrs := Attributes{
+			Subject:  requestSubject(ctx),
+			Verb:     requestVerb(c),
+			Resource: resource,
+			Name:     c.Param("name"),
+		}
+
+		decision, reason, err := a.Authorize(ctx, attrs)
+		if err != nil {
+			core.WriteResponse(c, errors.WrapC(err, metav1.ErrInternal, "authorization failed"), nil)
+			c.Abort()
+
+			return
+		}
+		if decision != DecisionAllow {
+			core.WriteResponse(c, errors.WithCode(ErrForbidden, "%q cannot %s %s %q: %s",
+				attrs.Subject.Name, attrs.Verb, resource.Resource, attrs.Name, reason), nil)
+			c.Abort()
+
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// requestSubject returns the subject of the request context.
+func requestSubject(ctx context.Context) Subject {
+	if subject, ok := SubjectFromContext(ctx); ok {
+		return subject
+	}
+	username, _ := ctx.Value(log.KeyUsername).(string)
+
+	return Subject{Name: username}
+}
+
+// requestVerb returns the verb of the request served by rest.Resource.
+func requestVerb(c *gin.Context) string {
+	switch c.Request.Method {
+	case http.MethodPost:
+		return VerbCreate
+	case http.MethodGet, http.MethodHead:
+		if c.Param("name") == "" {
+			return VerbList
+		}
+
+		return VerbGet
+	case http.MethodPut:
+		return VerbUpdate
+	case http.MethodPatch:
+		return VerbPatch
+	case http.MethodDelete:
+		return VerbDelete
+	default:
+		return c.Request.Method
+	}
+}
@@ -0,0 +1,166 @@
+package authorizer
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SubjectKind is the kind of subject a role is bound to.
+type SubjectKind string
+
+const (
+	// UserKind binds a role to the user with the name of the subject.
+	UserKind SubjectKind = "User"
+
+	// GroupKind binds a role to the members of the group with the name of the subject.
+	GroupKind SubjectKind = "Group"
+)
+
+// PolicyRule allows the verbs on the resources of the API groups. "*" matches everything.
+type PolicyRule struct {
+	APIGroups []string `yaml:"apiGroups" json:"apiGroups"`
+	Resources []string `yaml:"resources" json:"resources"`
+	Verbs     []string `yaml:"verbs" json:"verbs"`
+
+	// ResourceNames restricts the rule to the objects with these names, empty means all objects.
+	// Create and list requests have no name and are never matched by rules with names.
+	ResourceNames []string `yaml:"resourceNames,omitempty" json:"resourceNames,omitempty"`
+}
+
+// Role is a named set of rules.
+type Role struct {
+	Name  string       `yaml:"name" json:"name"`
+	Rules []PolicyRule `yaml:"rules" json:"rules"`
+}
+
+// RoleSubject is a user or a group a role is bound to.
+type RoleSubject struct {
+	Kind SubjectKind `yaml:"kind" json:"kind"`
+	Name string      `yaml:"name" json:"name"`
+}
+
+// RoleBinding grants the rules of a role to subjects.
+type RoleBinding struct {
+	Name     string        `yaml:"name" json:"name"`
+	Role     string        `yaml:"role" json:"role"`
+	Subjects []RoleSubject `yaml:"subjects" json:"subjects"`
+}
+
+// RBACPolicy is the set of roles and bindings of an RBAC authorizer.
+//
+//	roles:
+//	- name: secret-reader
+//	  rules:
+//	  - apiGroups: ["iam.api"]
+//	    resources: ["secrets"]
+//	    verbs: ["get", "list"]
+//	bindings:
+//	- name: read-secrets
+//	  role: secret-reader
+//	  subjects:
+//	  - kind: Group
+//	    name: developers
+type RBACPolicy struct {
+	Roles    []Role        `yaml:"roles" json:"roles"`
+	Bindings []RoleBinding `yaml:"bindings" json:"bindings"`
+}
+
+// RBAC allows the requests matched by a rule of a role bound to the subject. It has no opinion on
+// other requests, it never denies.
+type RBAC struct {
+	roles    map[string]*Role
+	bindings []RoleBinding
+}
+
+var _ Authorizer = &RBAC{}
+
+// NewRBAC returns an RBAC authorizer for policy. Bindings must refer to defined roles.
+func NewRBAC(policy RBACPolicy) (*RBAC, error) {
+	r := &RBAC{roles: map[string]*Role{}}
+	for i := range policy.Roles {
+		role := &policy.Roles[i]
+		if _, ok := r.roles[role.Name]; ok {
+			return nil, fmt.Errorf("duplicate role %q", role.Name)
+		}
+		r.roles[role.Name] = role
+	}
+
+	for _, binding := range policy.Bindings {
+		if _, ok := r.roles[binding.Role]; !ok {
+			return nil, fmt.Errorf("binding %q refers to unknown role %q", binding.Name, binding.Role)
+		}
+		for _, subject := range binding.Subjects {
+			if subject.Kind != UserKind && subject.Kind != GroupKind {
+				return nil, fmt.Errorf("binding %q: unknown subject kind %q", binding.Name, subject.Kind)
+			}
+			// unauthenticated requests have no subject name, they must not match a binding
+			if subject.Name == "" {
+				return nil, fmt.Errorf("binding %q: subject of kind %q has no name", binding.Name, subject.Kind)
+			}
+		}
+	}
+	r.bindings = policy.Bindings
+
+	return r, nil
+}
+
+// LoadRBAC reads an RBACPolicy in YAML from reader and returns its authorizer.
+func LoadRBAC(reader io.Reader) (*RBAC, error) {
+	var policy RBACPolicy
+	decoder := yaml.NewDecoder(reader)
+	decoder.KnownFields(true)
+	if err := decoder.Decode(&policy); err != nil && err != io.EOF {
+		return nil, fmt.Errorf("invalid RBAC policy: %w", err)
+	}
+
+	return NewRBAC(policy)
+}
+
+// Authorize implements Authorizer.
+func (r *RBAC) Authorize(_ context.Context, attrs Attributes) (Decision, string, error) {
+	for _, binding := range r.bindings {
+		if !bound(binding, attrs.Subject) {
+			continue
+		}
+		for _, rule := range r.roles[binding.Role].Rules {
+			if ruleMatches(rule, attrs) {
+				return DecisionAllow, fmt.Sprintf("allowed by role %q of binding %q", binding.Role, binding.Name), nil
+			}
+		}
+	}
+
+	return DecisionNoOpinion, "no RBAC rule allows the request", nil
+}
+
+// bound reports whether binding binds subject.
+func bound(binding RoleBinding, subject Subject) bool {
+	for _, s := range binding.Subjects {
+		switch s.Kind {
+		case UserKind:
+			if s.Name == subject.Name {
+				return true
+			}
+		case GroupKind:
+			for _, group := range subject.Groups {
+				if s.Name == group {
+					return true
+				}
+			}
+		}
+	}
+
+	return false
+}
+
+// ruleMatches reports whether rule matches the request.
+func ruleMatches(rule PolicyRule, attrs Attributes) bool {
+	if !matches(rule.APIGroups, attrs.Resource.Group) || !matches(rule.Resources, attrs.Resource.Resource) ||
+		!matches(rule.Verbs, attrs.Verb) {
+		return false
+	}
+
+	return len(rule.ResourceNames) == 0 || (attrs.Name != "" && matches(rule.ResourceNames, attrs.Name))
+}
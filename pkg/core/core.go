@@ -1,7 +1,9 @@
 package core
 
 import (
+	"mime"
 	"net/http"
+	"strings"
 
 	"github.com/gin-gonic/gin"
 	"github.com/gzwillyy/components/errors"
@@ -23,12 +25,26 @@ type ErrResponse struct {
 	Reference string `json:"reference,omitempty"`
 }
 
+// TableConvertor 将响应数据转换为表格形式，includeObject 表示表格的行中是否包含完整的对象.
+// 无法转换时返回的错误应当带有错误码，例如 406 Not Acceptable.
+type TableConvertor func(data interface{}, includeObject bool) (interface{}, error)
+
+var tableConvertor TableConvertor
+
+// SetTableConvertor 设置 WriteResponse 响应 as=Table 请求时使用的转换函数，例如 printers.ConvertToTable.
+// 需要在处理请求之前调用. 未设置时忽略 as=Table 参数，响应数据以原始形式返回.
+func SetTableConvertor(convert TableConvertor) {
+	tableConvertor = convert
+}
+
 // WriteResponse 将错误或响应数据写入 http 响应主体.
 // 它使用 errors.ParseCoder 将任何错误解析为 errors.Coder
 // errors.Coder 包含错误代码、用户安全错误消息 和 http 状态代码.
+// 如果请求的 Accept 头带有 as=Table 参数（例如 application/json;as=Table），
+// 响应数据将通过 SetTableConvertor 设置的函数转换为表格后返回.
 func WriteResponse(c *gin.Context, err error, data interface{}) {
 	if err != nil {
-		log.Errorf("%#+v", err)
+		log.L(c.Request.Context()).Errorf("%#+v", err)
 		coder := errors.ParseCoder(err)
 		c.JSON(coder.HTTPStatus(), ErrResponse{
 			Code:      coder.Code(),
@@ -39,5 +55,30 @@ func WriteResponse(c *gin.Context, err error, data interface{}) {
 		return
 	}
 
+	if data != nil && tableConvertor != nil && wantsTable(c.GetHeader("Accept")) {
+		table, err := tableConvertor(data, c.Query("includeObject") == "true")
+		if err != nil {
+			WriteResponse(c, err, nil)
+
+			return
+		}
+		data = table
+	}
+
 	c.JSON(http.StatusOK, data)
 }
+
+// wantsTable 判断 Accept 头是否请求以表格的形式返回数据.
+func wantsTable(accept string) bool {
+	for _, part := range strings.Split(accept, ",") {
+		_, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		if params["as"] == "Table" && params["q"] != "0" {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,63 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gzwillyy/components/pkg/json"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/printers"
+)
+
+type secret struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Expires int64 `json:"expires" table:""`
+}
+
+func writeResponse(accept string, data interface{}) *httptest.ResponseRecorder {
+	gin.SetMode(gin.TestMode)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodGet, "/v1/secrets/colin", nil)
+	c.Request.Header.Set("Accept", accept)
+	WriteResponse(c, nil, data)
+
+	return w
+}
+
+func TestWriteResponseTable(t *testing.T) {
+	obj := &secret{ObjectMeta: metav1.ObjectMeta{Name: "colin"}, Expires: 3600}
+
+	// 未设置转换函数时忽略 as=Table
+	w := writeResponse("application/json;as=Table", obj)
+	if w.Code != http.StatusOK || strings.Contains(w.Body.String(), "columnDefinitions") {
+		t.Errorf("table returned without a table convertor: %s", w.Body.String())
+	}
+
+	SetTableConvertor(printers.ConvertToTable)
+	defer SetTableConvertor(nil)
+
+	w = writeResponse("application/json;as=Table, application/json", obj)
+	var table metav1.Table
+	if err := json.Unmarshal(w.Body.Bytes(), &table); err != nil {
+		t.Fatal(err)
+	}
+	if table.Kind != "Table" || len(table.Rows) != 1 || table.Rows[0].Cells[0] != "colin" {
+		t.Errorf("unexpected table %s", w.Body.String())
+	}
+
+	w = writeResponse("application/json", obj)
+	if strings.Contains(w.Body.String(), "columnDefinitions") {
+		t.Errorf("table returned without as=Table: %s", w.Body.String())
+	}
+
+	w = writeResponse("application/json;as=Table", map[string]string{"name": "colin"})
+	if w.Code != http.StatusNotAcceptable {
+		t.Errorf("got status %d, want %d", w.Code, http.StatusNotAcceptable)
+	}
+}
@@ -0,0 +1,154 @@
+package core
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// CORSConfig 定义跨域资源共享中间件的配置.
+type CORSConfig struct {
+	// AllowOrigins 是允许跨域访问的源，例如 https://example.com.
+	// "*" 允许所有源，https://*.example.com 允许 example.com 的所有子域名.
+	AllowOrigins []string
+
+	// AllowMethods 是预检请求允许的 HTTP 方法.
+	AllowMethods []string
+
+	// AllowHeaders 是预检请求允许的请求头，为空时允许预检请求中声明的所有请求头.
+	AllowHeaders []string
+
+	// ExposeHeaders 是允许浏览器读取的响应头.
+	ExposeHeaders []string
+
+	// AllowCredentials 表示是否允许请求携带 cookie 等凭证，不能与 AllowOrigins 中的 "*" 同时使用.
+	AllowCredentials bool
+
+	// MaxAge 是预检请求结果的缓存时间，0 表示不设置.
+	MaxAge time.Duration
+}
+
+// DefaultCORSConfig 返回允许所有源以常用方法访问的默认配置.
+func DefaultCORSConfig() CORSConfig {
+	return CORSConfig{
+		AllowOrigins: []string{"*"},
+		AllowMethods: []string{
+			http.MethodGet, http.MethodPost, http.MethodPut, http.MethodPatch, http.MethodDelete, http.MethodHead,
+		},
+		AllowHeaders:  []string{"Origin", "Content-Type", "Accept", "Authorization", XRequestIDKey},
+		ExposeHeaders: []string{XRequestIDKey},
+		MaxAge:        12 * time.Hour,
+	}
+}
+
+// Validate 验证 CORS 配置.
+// 允许所有源时不能允许凭证，否则任何网站都可以携带用户的凭证发起跨域请求并读取响应.
+func (conf CORSConfig) Validate() error {
+	if conf.AllowCredentials && conf.allowsAnyOrigin() {
+		return errors.New(`cors: AllowCredentials can't be used with the "*" origin, list the allowed origins instead`)
+	}
+
+	return nil
+}
+
+// CORS 返回一个按 conf 处理跨域请求的中间件，conf 无效时 panic.
+// 来自允许的源的请求会得到相应的 Access-Control-* 响应头，预检请求直接以 204 响应.
+// 来自其他源的预检请求以 403 响应，普通请求照常处理但不带跨域响应头，由浏览器拒绝.
+func CORS(conf CORSConfig) gin.HandlerFunc {
+	if err := conf.Validate(); err != nil {
+		panic(err)
+	}
+	allowMethods := strings.Join(conf.AllowMethods, ", ")
+	allowHeaders := strings.Join(conf.AllowHeaders, ", ")
+	exposeHeaders := strings.Join(conf.ExposeHeaders, ", ")
+
+	return func(c *gin.Context) {
+		origin := c.GetHeader("Origin")
+		if origin == "" {
+			c.Next()
+
+			return
+		}
+
+		preflight := c.Request.Method == http.MethodOptions && c.GetHeader("Access-Control-Request-Method") != ""
+		header := c.Writer.Header()
+		header.Add("Vary", "Origin")
+		if !conf.allowsOrigin(origin) {
+			if preflight {
+				c.AbortWithStatus(http.StatusForbidden)
+
+				return
+			}
+			c.Next()
+
+			return
+		}
+
+		// Validate 保证允许所有源时不允许凭证，"*" 永远不会回显请求的源
+		if conf.allowsAnyOrigin() {
+			header.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			header.Set("Access-Control-Allow-Origin", origin)
+		}
+		if conf.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			if exposeHeaders != "" {
+				header.Set("Access-Control-Expose-Headers", exposeHeaders)
+			}
+			c.Next()
+
+			return
+		}
+
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		header.Set("Access-Control-Allow-Methods", allowMethods)
+		if allowHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", allowHeaders)
+		} else if requested := c.GetHeader("Access-Control-Request-Headers"); requested != "" {
+			header.Set("Access-Control-Allow-Headers", requested)
+		}
+		if conf.MaxAge > 0 {
+			header.Set("Access-Control-Max-Age", strconv.FormatInt(int64(conf.MaxAge/time.Second), 10))
+		}
+		c.AbortWithStatus(http.StatusNoContent)
+	}
+}
+
+// allowsAnyOrigin 判断配置是否允许所有源.
+func (conf CORSConfig) allowsAnyOrigin() bool {
+	for _, o := range conf.AllowOrigins {
+		if o == "*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// allowsOrigin 判断是否允许来自 origin 的跨域请求.
+func (conf CORSConfig) allowsOrigin(origin string) bool {
+	origin = strings.ToLower(origin)
+	for _, o := range conf.AllowOrigins {
+		o = strings.ToLower(o)
+		if o == "*" || o == origin {
+			return true
+		}
+
+		// https://*.example.com 匹配 https://api.example.com，不匹配 https://example.com
+		if prefix, suffix, ok := strings.Cut(o, "*"); ok &&
+			len(origin) > len(prefix)+len(suffix) &&
+			strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
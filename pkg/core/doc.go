@@ -1,2 +1,3 @@
-// Package core 包core实现了apimachinery使用的一些核心功能.
+// Package core 包core实现了apimachinery使用的一些核心功能,
+// 包括统一的响应写入以及请求 ID、访问日志、panic 恢复、CORS 和安全响应头等 gin 中间件.
 package core
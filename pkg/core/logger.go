@@ -0,0 +1,67 @@
+package core
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gzwillyy/components/log"
+)
+
+// LoggerConfig 定义访问日志中间件的配置.
+type LoggerConfig struct {
+	// SkipPaths 中的请求路径不记录访问日志，例如健康检查接口.
+	SkipPaths []string
+}
+
+// Logger 返回一个使用默认配置记录访问日志的中间件.
+func Logger() gin.HandlerFunc {
+	return LoggerWithConfig(LoggerConfig{})
+}
+
+// LoggerWithConfig 返回一个通过 log 包记录结构化访问日志的中间件.
+// 日志通过 log.L(c.Request.Context()) 输出，因此与 RequestID 一起使用时带有请求 ID.
+// 状态码为 5xx 的请求以错误级别记录，4xx 以警告级别记录，其余以信息级别记录.
+func LoggerWithConfig(conf LoggerConfig) gin.HandlerFunc {
+	skip := make(map[string]struct{}, len(conf.SkipPaths))
+	for _, path := range conf.SkipPaths {
+		skip[path] = struct{}{}
+	}
+
+	return func(c *gin.Context) {
+		start := time.Now()
+		path := c.Request.URL.Path
+		query := c.Request.URL.RawQuery
+
+		c.Next()
+
+		if _, ok := skip[path]; ok {
+			return
+		}
+
+		status := c.Writer.Status()
+		keysAndValues := []interface{}{
+			"method", c.Request.Method,
+			"path", path,
+			"query", query,
+			"status", status,
+			"latency", time.Since(start),
+			"clientIP", c.ClientIP(),
+			"userAgent", c.Request.UserAgent(),
+			"bodySize", c.Writer.Size(),
+		}
+		if len(c.Errors) > 0 {
+			keysAndValues = append(keysAndValues, "errors", c.Errors.ByType(gin.ErrorTypePrivate).String())
+		}
+
+		logger := log.L(c.Request.Context())
+		switch {
+		case status >= http.StatusInternalServerError:
+			logger.Errorw("HTTP request", keysAndValues...)
+		case status >= http.StatusBadRequest:
+			logger.Warnw("HTTP request", keysAndValues...)
+		default:
+			logger.Infow("HTTP request", keysAndValues...)
+		}
+	}
+}
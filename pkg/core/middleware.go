@@ -0,0 +1,18 @@
+package core
+
+import "github.com/gin-gonic/gin"
+
+// DefaultMiddlewares 返回推荐的中间件链：请求 ID、访问日志、panic 恢复和安全响应头.
+// 访问日志位于 panic 恢复之前，因此发生 panic 的请求也会以 500 状态码记录.
+//
+//	engine := gin.New()
+//	engine.Use(core.DefaultMiddlewares()...)
+//	engine.Use(core.CORS(core.DefaultCORSConfig()))
+func DefaultMiddlewares() []gin.HandlerFunc {
+	return []gin.HandlerFunc{
+		RequestID(),
+		Logger(),
+		Recovery(),
+		Secure(DefaultSecureConfig()),
+	}
+}
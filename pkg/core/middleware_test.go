@@ -0,0 +1,195 @@
+package core
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gzwillyy/components/errors"
+	"github.com/gzwillyy/components/log"
+	"github.com/gzwillyy/components/log/logtest"
+
+	"github.com/gzwillyy/components/pkg/json"
+	utilruntime "github.com/gzwillyy/components/pkg/util/runtime"
+)
+
+func newEngine(middlewares ...gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	engine := gin.New()
+	engine.Use(middlewares...)
+	engine.GET("/ok", func(c *gin.Context) {
+		log.L(c.Request.Context()).Info("handled")
+		c.String(http.StatusOK, RequestIDFromContext(c.Request.Context()))
+	})
+	engine.GET("/panic", func(c *gin.Context) {
+		panic("boom")
+	})
+
+	return engine
+}
+
+func serve(engine *gin.Engine, req *http.Request) *httptest.ResponseRecorder {
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	return w
+}
+
+func TestRequestID(t *testing.T) {
+	rec := logtest.New(t)
+	engine := newEngine(RequestID())
+
+	w := serve(engine, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	rid := w.Header().Get(XRequestIDKey)
+	if len(rid) != 36 || w.Body.String() != rid {
+		t.Errorf("expected a generated request ID in header and context, got %q and %q", rid, w.Body.String())
+	}
+	rec.Message("handled").FieldValue(log.KeyRequestID.String(), rid).AssertCount(1)
+
+	req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set(XRequestIDKey, "7a7b9f24")
+	if w := serve(engine, req); w.Header().Get(XRequestIDKey) != "7a7b9f24" || w.Body.String() != "7a7b9f24" {
+		t.Errorf("expected the request ID of the client to be propagated, got %q", w.Header().Get(XRequestIDKey))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set(XRequestIDKey, "bad id\n")
+	if w := serve(engine, req); w.Header().Get(XRequestIDKey) == "bad id\n" {
+		t.Errorf("expected an invalid request ID to be replaced")
+	}
+}
+
+func TestLogger(t *testing.T) {
+	rec := logtest.New(t)
+	engine := newEngine(RequestID(), LoggerWithConfig(LoggerConfig{SkipPaths: []string{"/healthz"}}), Recovery())
+
+	serve(engine, httptest.NewRequest(http.MethodGet, "/ok?limit=1", nil))
+	rec.Level(log.InfoLevel).Message("HTTP request").
+		FieldValue("path", "/ok").FieldValue("query", "limit=1").FieldValue("status", int64(http.StatusOK)).
+		FieldKey(log.KeyRequestID.String()).AssertCount(1)
+
+	serve(engine, httptest.NewRequest(http.MethodGet, "/missing", nil))
+	rec.Level(log.WarnLevel).Message("HTTP request").FieldValue("status", int64(http.StatusNotFound)).AssertCount(1)
+
+	serve(engine, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	rec.Level(log.ErrorLevel).Message("HTTP request").FieldValue("status", int64(http.StatusInternalServerError)).AssertCount(1)
+
+	rec.Reset()
+	serve(engine, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	rec.Message("HTTP request").AssertEmpty()
+}
+
+func TestRecovery(t *testing.T) {
+	logtest.New(t)
+	var observed interface{}
+	engine := newEngine(Recovery())
+
+	saved := utilruntime.PanicHandlers
+	utilruntime.PanicHandlers = append(append([]func(interface{}){}, saved...), func(r interface{}) { observed = r })
+	defer func() { utilruntime.PanicHandlers = saved }()
+
+	w := serve(engine, httptest.NewRequest(http.MethodGet, "/panic", nil))
+	if w.Code != http.StatusInternalServerError {
+		t.Fatalf("got status %d, want %d", w.Code, http.StatusInternalServerError)
+	}
+	var resp ErrResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if unknown := errors.ParseCoder(errors.New("")); resp.Code != unknown.Code() || resp.Message != unknown.String() {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if observed != "boom" {
+		t.Errorf("expected the panic handlers to be called, got %v", observed)
+	}
+
+	if w := serve(engine, httptest.NewRequest(http.MethodGet, "/ok", nil)); w.Code != http.StatusOK {
+		t.Errorf("expected the server to keep serving, got status %d", w.Code)
+	}
+}
+
+func TestCORS(t *testing.T) {
+	conf := DefaultCORSConfig()
+	conf.AllowOrigins = []string{"https://example.com", "https://*.example.org"}
+	conf.AllowCredentials = true
+	engine := newEngine(CORS(conf))
+
+	preflight := func(origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodOptions, "/ok", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", http.MethodDelete)
+
+		return serve(engine, req)
+	}
+
+	w := preflight("https://api.example.org")
+	if w.Code != http.StatusNoContent ||
+		w.Header().Get("Access-Control-Allow-Origin") != "https://api.example.org" ||
+		w.Header().Get("Access-Control-Allow-Credentials") != "true" ||
+		!strings.Contains(w.Header().Get("Access-Control-Allow-Methods"), http.MethodDelete) ||
+		w.Header().Get("Access-Control-Max-Age") != "43200" {
+		t.Errorf("unexpected preflight response %d %v", w.Code, w.Header())
+	}
+	if w := preflight("https://example.org"); w.Code != http.StatusForbidden {
+		t.Errorf("expected preflight from a disallowed origin to be forbidden, got %d", w.Code)
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set("Origin", "https://example.com")
+	w = serve(engine, req)
+	if w.Code != http.StatusOK || w.Header().Get("Access-Control-Allow-Origin") != "https://example.com" ||
+		w.Header().Get("Access-Control-Expose-Headers") != XRequestIDKey {
+		t.Errorf("unexpected response %d %v", w.Code, w.Header())
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set("Origin", "https://evil.com")
+	if w := serve(engine, req); w.Header().Get("Access-Control-Allow-Origin") != "" {
+		t.Errorf("unexpected CORS headers for a disallowed origin: %v", w.Header())
+	}
+
+	w = serve(newEngine(CORS(DefaultCORSConfig())), req)
+	if w.Header().Get("Access-Control-Allow-Origin") != "*" || w.Header().Get("Access-Control-Allow-Credentials") != "" {
+		t.Errorf("expected wildcard origin without credentials, got %v", w.Header())
+	}
+}
+
+func TestCORSWildcardWithCredentials(t *testing.T) {
+	conf := DefaultCORSConfig()
+	conf.AllowCredentials = true
+	if err := conf.Validate(); err == nil {
+		t.Errorf("expected an error allowing credentials for all origins")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected CORS to panic allowing credentials for all origins")
+		}
+	}()
+	CORS(conf)
+}
+
+func TestSecure(t *testing.T) {
+	engine := newEngine(Secure(DefaultSecureConfig()))
+
+	w := serve(engine, httptest.NewRequest(http.MethodGet, "/ok", nil))
+	for header, want := range map[string]string{
+		"X-Frame-Options":           "DENY",
+		"X-Content-Type-Options":    "nosniff",
+		"Referrer-Policy":           "no-referrer",
+		"Strict-Transport-Security": "",
+	} {
+		if got := w.Header().Get(header); got != want {
+			t.Errorf("%s: got %q, want %q", header, got, want)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/ok", nil)
+	req.Header.Set("X-Forwarded-Proto", "https")
+	w = serve(engine, req)
+	if got := w.Header().Get("Strict-Transport-Security"); got != "max-age=31536000; includeSubDomains" {
+		t.Errorf("unexpected Strict-Transport-Security %q", got)
+	}
+}
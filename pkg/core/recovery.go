@@ -0,0 +1,39 @@
+package core
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gzwillyy/components/errors"
+
+	"github.com/gzwillyy/components/pkg/util/runtime"
+)
+
+// Recovery 返回一个从 panic 中恢复的中间件.
+// panic 首先交给 runtime.HandleCrash 处理，runtime.PanicHandlers 中注册的处理函数会被调用，
+// 随后请求以 errors 包的未知错误码（500）响应，服务本身不会退出.
+// http.ErrAbortHandler 会被继续抛出，由 net/http 中止响应.
+func Recovery() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		defer func() {
+			// runtime.ReallyCrash 为 true 时 HandleCrash 会再次抛出 panic
+			if r := recover(); r == http.ErrAbortHandler {
+				panic(r)
+			}
+		}()
+		defer runtime.HandleCrash(func(r interface{}) {
+			if r == http.ErrAbortHandler {
+				return
+			}
+			if c.Writer.Written() {
+				c.Abort()
+
+				return
+			}
+			WriteResponse(c, errors.Errorf("panic recovered: %v", r), nil)
+			c.Abort()
+		})
+
+		c.Next()
+	}
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"context"
+	"crypto/rand"
+	"fmt"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gzwillyy/components/log"
+)
+
+// XRequestIDKey 是传递请求 ID 的 HTTP 头，同时也是请求 ID 在 gin.Context 中的键.
+const XRequestIDKey = "X-Request-ID"
+
+// maxRequestIDLength 是沿用客户端请求 ID 的最大长度，更长的请求 ID 会被重新生成.
+const maxRequestIDLength = 128
+
+// RequestID 返回一个中间件，它为每个请求确定请求 ID：
+// 沿用请求头 X-Request-ID 中合法的值，否则生成新的 ID.
+// 请求 ID 会写入响应头、gin.Context 以及请求上下文的 log.KeyRequestID 中，
+// 因此通过 log.L(c.Request.Context()) 输出的日志都带有请求 ID.
+func RequestID() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		rid := c.GetHeader(XRequestIDKey)
+		if !validRequestID(rid) {
+			rid = newRequestID()
+			c.Request.Header.Set(XRequestIDKey, rid)
+		}
+
+		c.Set(XRequestIDKey, rid)
+		c.Writer.Header().Set(XRequestIDKey, rid)
+		c.Request = c.Request.WithContext(context.WithValue(c.Request.Context(), log.KeyRequestID, rid))
+		c.Next()
+	}
+}
+
+// GetRequestID 返回 RequestID 中间件为当前请求确定的请求 ID.
+func GetRequestID(c *gin.Context) string {
+	return c.GetString(XRequestIDKey)
+}
+
+// RequestIDFromContext 返回 ctx 中的请求 ID，
+// 调用其他服务时可以将它设置到请求头 X-Request-ID 中继续传递.
+func RequestIDFromContext(ctx context.Context) string {
+	rid, _ := ctx.Value(log.KeyRequestID).(string)
+
+	return rid
+}
+
+// validRequestID 判断客户端提供的请求 ID 是否可以沿用，只允许长度有限的可打印 ASCII 字符.
+func validRequestID(rid string) bool {
+	if len(rid) == 0 || len(rid) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(rid); i++ {
+		if rid[i] < 0x21 || rid[i] > 0x7e {
+			return false
+		}
+	}
+
+	return true
+}
+
+// newRequestID 生成一个随机的 UUID (version 4) 作为请求 ID.
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
@@ -0,0 +1,140 @@
+// Package export writes API objects embedding meta/v1.ObjectMeta as YAML or JSON manifests and
+// recreates objects from such manifests, for backups and for moving resources between
+// environments.
+package export
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"time"
+
+	"github.com/gzwillyy/components/pkg/json"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/runtime"
+	"github.com/gzwillyy/components/pkg/scheme"
+)
+
+// negotiatedSerializer provides the stream serializers of the manifest media types.
+var negotiatedSerializer = runtime.NewNegotiatedSerializer()
+
+// Strip clears the fields of obj that are specific to the environment it is stored in: the IDs,
+// the timestamps and the resource version. The remaining fields are the ones a user specifies.
+func Strip(obj metav1.Object) {
+	obj.SetID(0)
+	obj.SetInstanceID("")
+	obj.SetCreatedAt(time.Time{})
+	obj.SetUpdatedAt(time.Time{})
+	obj.SetDeletionTimestamp(nil)
+	obj.SetResourceVersion(0)
+}
+
+// Exporter writes objects as manifests.
+type Exporter struct {
+	// Scheme, if set, fills in the apiVersion and kind of objects whose TypeMeta is empty. Without
+	// a scheme every object must carry its apiVersion and kind.
+	Scheme *scheme.Scheme
+
+	// Options controls which fields are exported. Unless Options.Exact is set the fields cleared
+	// by Strip are omitted.
+	Options metav1.ExportOptions
+}
+
+// Export writes objs to w as a stream of manifests in mediaType, usually runtime.ContentTypeYAML,
+// which separates the manifests with "---", or runtime.ContentTypeJSON, which writes one manifest
+// per line. The objects are not modified.
+func (e *Exporter) Export(w io.Writer, mediaType string, objs ...metav1.Object) error {
+	info, err := runtime.NegotiateOutputMediaTypeStream(mediaType, negotiatedSerializer)
+	if err != nil {
+		return err
+	}
+
+	enc := info.StreamSerializer.NewEncoder(w)
+	for _, obj := range objs {
+		manifest, err := e.manifest(obj)
+		if err != nil {
+			return err
+		}
+		if err := enc.Encode(manifest); err != nil {
+			return fmt.Errorf("unable to encode %q: %w", obj.GetName(), err)
+		}
+	}
+
+	return nil
+}
+
+// manifest returns the form of obj to export.
+func (e *Exporter) manifest(obj metav1.Object) (interface{}, error) {
+	out, err := deepCopy(obj)
+	if err != nil {
+		return nil, err
+	}
+	if !e.Options.Exact {
+		Strip(out)
+	}
+
+	o, ok := out.(scheme.Object)
+	if !ok {
+		return nil, fmt.Errorf("%T doesn't embed metav1.TypeMeta", obj)
+	}
+	if o.GetObjectKind().GroupVersionKind().Empty() && e.Scheme != nil {
+		if err := e.Scheme.SetObjectKind(o); err != nil {
+			return nil, err
+		}
+	}
+	if gvk := o.GetObjectKind().GroupVersionKind(); len(gvk.Kind) == 0 || len(gvk.Version) == 0 {
+		return nil, fmt.Errorf("object %q has no apiVersion or kind", obj.GetName())
+	}
+	if e.Options.Exact {
+		return out, nil
+	}
+
+	return withoutTimestamps(out)
+}
+
+// withoutTimestamps returns the JSON form of a stripped object without its timestamps, time.Time
+// fields are encoded even when they are empty.
+func withoutTimestamps(obj metav1.Object) (map[string]json.RawMessage, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil, err
+	}
+
+	// ObjectMeta is usually encoded as "metadata", but may be inlined
+	raw, nested := fields["metadata"]
+	metadata := fields
+	if nested {
+		metadata = nil
+		if err := json.Unmarshal(raw, &metadata); err != nil {
+			return nil, err
+		}
+	}
+	delete(metadata, "createdAt")
+	delete(metadata, "updatedAt")
+	if nested {
+		if fields["metadata"], err = json.Marshal(metadata); err != nil {
+			return nil, err
+		}
+	}
+
+	return fields, nil
+}
+
+// deepCopy copies obj through its JSON form.
+func deepCopy(obj metav1.Object) (metav1.Object, error) {
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	out, _ := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(metav1.Object)
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, err
+	}
+
+	return out, nil
+}
@@ -0,0 +1,190 @@
+package export_test
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/gzwillyy/components/pkg/export"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/runtime"
+	"github.com/gzwillyy/components/pkg/scheme"
+)
+
+type secret struct {
+	metav1.TypeMeta   `json:",inline" gorm:"-"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Description string `json:"description" gorm:"column:description"`
+}
+
+// BeforeCreate assigns the instance ID like the applications do.
+func (s *secret) BeforeCreate(tx *gorm.DB) error {
+	if s.InstanceID == "" {
+		s.InstanceID = "secret-" + s.Name
+	}
+
+	return s.ObjectMeta.BeforeCreate(tx)
+}
+
+func newScheme() *scheme.Scheme {
+	s := scheme.NewScheme()
+	s.AddKnownTypes(scheme.GroupVersion{Group: "iam.api", Version: "v1"}, &secret{})
+
+	return s
+}
+
+func newDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := db.AutoMigrate(&secret{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return db
+}
+
+func stored() []metav1.Object {
+	now := time.Now()
+
+	return []metav1.Object{
+		&secret{
+			ObjectMeta: metav1.ObjectMeta{
+				ID:              7,
+				InstanceID:      "secret-abc",
+				Name:            "colin",
+				Labels:          map[string]string{"team": "infra"},
+				ResourceVersion: 4,
+				CreatedAt:       now,
+				UpdatedAt:       now,
+			},
+			Description: "colin's secret",
+		},
+		&secret{ObjectMeta: metav1.ObjectMeta{ID: 8, Name: "neo", ResourceVersion: 1}},
+	}
+}
+
+func TestExportYAML(t *testing.T) {
+	objs := stored()
+	e := &export.Exporter{Scheme: newScheme()}
+
+	var buf bytes.Buffer
+	if err := e.Export(&buf, runtime.ContentTypeYAML, objs...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := buf.String()
+	for _, want := range []string{"apiVersion: iam.api/v1", "kind: secret", "name: colin", "team: infra", "---"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in manifests:\n%s", want, out)
+		}
+	}
+	for _, unwanted := range []string{"id:", "instanceID", "resourceVersion", "createdAt", "updatedAt"} {
+		if strings.Contains(out, unwanted) {
+			t.Errorf("unexpected %q in manifests:\n%s", unwanted, out)
+		}
+	}
+
+	if s := objs[0].(*secret); s.ID != 7 || s.ResourceVersion != 4 || len(s.Kind) != 0 {
+		t.Errorf("export modified the object: %+v", s)
+	}
+}
+
+func TestExportExact(t *testing.T) {
+	e := &export.Exporter{Scheme: newScheme(), Options: metav1.ExportOptions{Export: true, Exact: true}}
+
+	var buf bytes.Buffer
+	if err := e.Export(&buf, runtime.ContentTypeJSON, stored()...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatalf("expected one manifest per line, got:\n%s", buf.String())
+	}
+	for _, want := range []string{`"id":7`, `"instanceID":"secret-abc"`, `"resourceVersion":4`, `"kind":"secret"`} {
+		if !strings.Contains(lines[0], want) {
+			t.Errorf("expected %s in %s", want, lines[0])
+		}
+	}
+}
+
+func TestExportWithoutKind(t *testing.T) {
+	e := &export.Exporter{}
+	if err := e.Export(&bytes.Buffer{}, runtime.ContentTypeYAML, stored()...); err == nil {
+		t.Errorf("expected an error for objects without kind")
+	}
+}
+
+func TestImport(t *testing.T) {
+	s := newScheme()
+	db := newDB(t)
+
+	var manifests bytes.Buffer
+	if err := (&export.Exporter{Scheme: s}).Export(&manifests, runtime.ContentTypeYAML, stored()...); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	i := &export.Importer{Scheme: s, DB: db}
+	result, err := i.Import(context.TODO(), bytes.NewReader(manifests.Bytes()), runtime.ContentTypeYAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Created) != 2 || len(result.Updated) != 0 || len(result.Unchanged) != 0 {
+		t.Fatalf("unexpected result of first import: %+v", result)
+	}
+
+	var colin secret
+	if err := db.Where("name = ?", "colin").Take(&colin).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if colin.ID == 7 || colin.InstanceID != "secret-colin" || colin.ResourceVersion != 1 ||
+		colin.Description != "colin's secret" || colin.Labels["team"] != "infra" {
+		t.Errorf("unexpected imported object: %+v", colin)
+	}
+
+	result, err = i.Import(context.TODO(), bytes.NewReader(manifests.Bytes()), runtime.ContentTypeYAML)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Created) != 0 || len(result.Updated) != 0 || len(result.Unchanged) != 2 {
+		t.Fatalf("expected import to be idempotent, got %+v", result)
+	}
+
+	changed := `{"apiVersion":"iam.api/v1","kind":"secret","metadata":{"name":"colin"},"description":"rotated"}` + "\n"
+	result, err = i.Import(context.TODO(), strings.NewReader(changed), runtime.ContentTypeJSON)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(result.Updated) != 1 {
+		t.Fatalf("expected colin to be updated, got %+v", result)
+	}
+
+	var updated secret
+	if err := db.Where("name = ?", "colin").Take(&updated).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if updated.ID != colin.ID || updated.ResourceVersion != 2 || updated.Description != "rotated" || len(updated.Labels) != 0 {
+		t.Errorf("unexpected updated object: %+v", updated)
+	}
+}
+
+func TestImportInvalidManifest(t *testing.T) {
+	i := &export.Importer{Scheme: newScheme(), DB: newDB(t)}
+
+	for _, manifest := range []string{
+		"metadata:\n  name: colin\n",
+		"apiVersion: iam.api/v1\nkind: unknown\nmetadata:\n  name: colin\n",
+		"apiVersion: iam.api/v1\nkind: secret\nmetadata:\n  name: not valid\n",
+	} {
+		if _, err := i.Import(context.TODO(), strings.NewReader(manifest), runtime.ContentTypeYAML); err == nil {
+			t.Errorf("expected an error for manifest:\n%s", manifest)
+		}
+	}
+}
@@ -0,0 +1,174 @@
+package export
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gzwillyy/components/errors"
+	"github.com/gzwillyy/components/pkg/json"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/rest"
+	"github.com/gzwillyy/components/pkg/runtime"
+	"github.com/gzwillyy/components/pkg/scheme"
+)
+
+// ImportResult lists the names of the imported objects by outcome.
+type ImportResult struct {
+	// Created are the objects that didn't exist.
+	Created []string
+
+	// Updated are the existing objects that differed from their manifest.
+	Updated []string
+
+	// Unchanged are the existing objects that already matched their manifest.
+	Unchanged []string
+}
+
+// Importer recreates the objects of manifests written by Exporter in a database. Objects are
+// identified by name, importing the same manifests again leaves the objects unchanged.
+type Importer struct {
+	// Scheme maps the apiVersion and kind of manifests to Go types. It is required.
+	Scheme *scheme.Scheme
+
+	// DB is the database objects are stored in, every kind in its own table.
+	DB *gorm.DB
+
+	// Processor defaults and validates the objects before they are stored.
+	Processor rest.Processor
+}
+
+// Import reads a stream of manifests in mediaType from r. An object that doesn't exist is created,
+// an existing object with the same name is updated to match its manifest. The fields cleared by
+// Strip are never imported, storage assigns them. Import stops at the first manifest that fails.
+func (i *Importer) Import(ctx context.Context, r io.Reader, mediaType string) (*ImportResult, error) {
+	if i.Scheme == nil {
+		return nil, fmt.Errorf("an importer requires a scheme")
+	}
+	info, err := runtime.NegotiateInputSerializerStream(mediaType, negotiatedSerializer)
+	if err != nil {
+		return nil, errors.WrapC(err, metav1.ErrUnsupportedMediaType, "unsupported manifest media type %q", mediaType)
+	}
+
+	result := &ImportResult{}
+	dec := info.StreamSerializer.NewDecoder(r)
+	for n := 0; ; n++ {
+		var raw json.RawMessage
+		if err := dec.Decode(&raw); err != nil {
+			if errors.Is(err, io.EOF) {
+				return result, nil
+			}
+
+			return result, errors.WrapC(err, metav1.ErrValidation, "unable to read manifest %d", n)
+		}
+		if len(bytes.TrimSpace(raw)) == 0 || bytes.Equal(bytes.TrimSpace(raw), []byte("null")) {
+			continue
+		}
+
+		if err := i.importManifest(ctx, raw, result); err != nil {
+			return result, err
+		}
+	}
+}
+
+// importManifest creates or updates the object of a single manifest.
+func (i *Importer) importManifest(ctx context.Context, raw []byte, result *ImportResult) error {
+	obj, gvk, err := i.decode(raw)
+	if err != nil {
+		return err
+	}
+	Strip(obj)
+
+	current, err := i.Scheme.New(gvk)
+	if err != nil {
+		return err
+	}
+	existing, _ := current.(metav1.Object)
+	err = i.DB.WithContext(ctx).
+		Where(clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "name"}, Value: obj.GetName()}).
+		Take(existing).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		if _, err := i.Processor.Create(ctx, obj, metav1.CreateOptions{}, i.create); err != nil {
+			return err
+		}
+		result.Created = append(result.Created, obj.GetName())
+
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	current.GetObjectKind().SetGroupVersionKind(gvk)
+	if same, err := equalManifests(existing, obj); err != nil || same {
+		if same {
+			result.Unchanged = append(result.Unchanged, obj.GetName())
+		}
+
+		return err
+	}
+	if _, err := i.Processor.Update(ctx, existing, obj, metav1.UpdateOptions{}, i.update); err != nil {
+		return err
+	}
+	result.Updated = append(result.Updated, obj.GetName())
+
+	return nil
+}
+
+// decode decodes a manifest into a new object of its kind.
+func (i *Importer) decode(raw []byte) (metav1.Object, scheme.GroupVersionKind, error) {
+	var tm metav1.TypeMeta
+	if err := json.Unmarshal(raw, &tm); err != nil {
+		return nil, scheme.GroupVersionKind{}, errors.WrapC(err, metav1.ErrValidation, "invalid manifest")
+	}
+	gvk := tm.GroupVersionKind()
+	if len(gvk.Kind) == 0 || len(gvk.Version) == 0 {
+		return nil, gvk, errors.WithCode(metav1.ErrValidation, "manifest has no apiVersion or kind")
+	}
+
+	o, err := i.Scheme.New(gvk)
+	if err != nil {
+		return nil, gvk, errors.WrapC(err, metav1.ErrValidation, "unknown manifest kind %q", gvk)
+	}
+	obj, ok := o.(metav1.Object)
+	if !ok {
+		return nil, gvk, fmt.Errorf("%T doesn't implement the metav1.Object interface", o)
+	}
+	if err := json.Unmarshal(raw, obj); err != nil {
+		return nil, gvk, errors.WrapC(err, metav1.ErrValidation, "invalid %s manifest", gvk.Kind)
+	}
+
+	return obj, gvk, nil
+}
+
+func (i *Importer) create(ctx context.Context, obj metav1.Object) error {
+	return i.DB.WithContext(ctx).Create(obj).Error
+}
+
+func (i *Importer) update(ctx context.Context, obj metav1.Object) error {
+	return i.DB.WithContext(ctx).Save(obj).Error
+}
+
+// equalManifests returns true if the stripped form of existing equals obj.
+func equalManifests(existing, obj metav1.Object) (bool, error) {
+	stripped, err := deepCopy(existing)
+	if err != nil {
+		return false, err
+	}
+	Strip(stripped)
+
+	a, err := json.Marshal(stripped)
+	if err != nil {
+		return false, err
+	}
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return false, err
+	}
+
+	return bytes.Equal(a, b), nil
+}
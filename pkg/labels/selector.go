@@ -123,6 +123,11 @@ type Requirement struct {
 //
 // The empty string is a valid value in the input values set.
 func NewRequirement(key string, op selection.Operator, vals []string) (*Requirement, error) {
+	return newRequirement(key, op, vals, true)
+}
+
+// newRequirement is NewRequirement, the values are only validated as label values if validateValues is set.
+func newRequirement(key string, op selection.Operator, vals []string, validateValues bool) (*Requirement, error) {
 	if err := validateLabelKey(key); err != nil {
 		return nil, err
 	}
@@ -152,7 +157,7 @@ func NewRequirement(key string, op selection.Operator, vals []string) (*Requirem
 		return nil, fmt.Errorf("operator '%v' is not recognized", op)
 	}
 
-	for i := range vals {
+	for i := 0; validateValues && i < len(vals); i++ {
 		if err := validateLabelValue(key, vals[i]); err != nil {
 			return nil, err
 		}
@@ -553,6 +558,8 @@ type Parser struct {
 	l            *Lexer
 	scannedItems []ScannedItem
 	position     int
+	// anyValues disables the validation of values as label values.
+	anyValues bool
 }
 
 // ParserContext represents context during parsing:
@@ -646,7 +653,7 @@ func (p *Parser) parseRequirement() (*Requirement, error) {
 		return nil, err
 	}
 	if operator == selection.Exists || operator == selection.DoesNotExist { // operator found lookahead set checked
-		return NewRequirement(key, operator, []string{})
+		return newRequirement(key, operator, []string{}, !p.anyValues)
 	}
 	operator, err = p.parseOperator()
 	if err != nil {
@@ -662,7 +669,7 @@ func (p *Parser) parseRequirement() (*Requirement, error) {
 	if err != nil {
 		return nil, err
 	}
-	return NewRequirement(key, operator, values.List())
+	return newRequirement(key, operator, values.List(), !p.anyValues)
 }
 
 // parseKeyAndInferOperator parse literals.
@@ -839,7 +846,23 @@ func Parse(selector string) (Selector, error) {
 // callers. This function has two callers now, one returns a Selector interface and the other
 // returns a list of requirements.
 func parse(selector string) (internalSelector, error) {
-	p := &Parser{l: &Lexer{s: selector, pos: 0}}
+	return parseSelector(selector, false)
+}
+
+// ParseAnyValues is Parse, but VALUE may be any sequence of characters other than white space and
+// the special symbols, it isn't validated as a label value. It suits selectors matched against
+// attributes that aren't labels, e.g. user names like "alice@example.com" or "system:admin".
+func ParseAnyValues(selector string) (Selector, error) {
+	parsedSelector, err := parseSelector(selector, true)
+	if err != nil {
+		return nil, err
+	}
+	return parsedSelector, nil
+}
+
+// parseSelector parses selector, anyValues disables the validation of values as label values.
+func parseSelector(selector string, anyValues bool) (internalSelector, error) {
+	p := &Parser{l: &Lexer{s: selector, pos: 0}, anyValues: anyValues}
 	items, err := p.parse()
 	if err != nil {
 		return nil, err
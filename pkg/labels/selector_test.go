@@ -46,6 +46,38 @@ func TestSelectorParse(t *testing.T) {
 	}
 }
 
+func TestParseAnyValues(t *testing.T) {
+	long := strings.Repeat("a", 64)
+	for _, test := range []string{"user=alice@example.com", "group in (ops,system:admin)", "name=" + long} {
+		if _, err := Parse(test); err == nil {
+			t.Errorf("%v: expected Parse to reject the value", test)
+		}
+		lq, err := ParseAnyValues(test)
+		if err != nil {
+			t.Errorf("%v: error %v", test, err)
+			continue
+		}
+		if lq.String() != test {
+			t.Errorf("%v restring gave: %v", test, lq.String())
+		}
+	}
+	if !mustParseAnyValues(t, "user=alice@example.com").Matches(Set{"user": "alice@example.com"}) {
+		t.Errorf("expected the selector to match")
+	}
+	if _, err := ParseAnyValues("invalid key!=x"); err == nil {
+		t.Errorf("expected keys to be validated")
+	}
+}
+
+func mustParseAnyValues(t *testing.T, selector string) Selector {
+	t.Helper()
+	lq, err := ParseAnyValues(selector)
+	if err != nil {
+		t.Fatalf("%v: error %v", selector, err)
+	}
+	return lq
+}
+
 func TestDeterministicParse(t *testing.T) {
 	s1, err := Parse("x=a,a=x")
 	s2, err2 := Parse("a=x,x=a")
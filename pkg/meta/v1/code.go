@@ -0,0 +1,130 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/gzwillyy/components/errors"
+)
+
+// Common error codes returned by the generic API machinery. They are registered with
+// errors.MustRegister, so core.WriteResponse maps them to the listed HTTP status.
+const (
+	// ErrConflict - 409: The object has been modified, please apply your changes to the latest version and try again.
+	ErrConflict int = iota + 100901
+
+	// ErrInvalidSelector - 400: Invalid label or field selector.
+	ErrInvalidSelector
+
+	// ErrResourceExpired - 410: The continue token has expired, please restart the list without it.
+	ErrResourceExpired
+
+	// ErrInvalidContinue - 400: Invalid continue token.
+	ErrInvalidContinue
+
+	// ErrValidation - 400: Validation failed.
+	ErrValidation
+
+	// ErrNotFound - 404: The requested object was not found.
+	ErrNotFound
+
+	// ErrUnsupportedMediaType - 415: The media type of the request body is not supported.
+	ErrUnsupportedMediaType
+
+	// ErrNotAcceptable - 406: The object can't be returned in the requested representation.
+	ErrNotAcceptable
+
+	// ErrInternal - 500: Internal server error.
+	ErrInternal
+
+	// ErrBind - 400: Error occurred while binding the request body to the struct.
+	ErrBind
+
+	// ErrAlreadyExists - 409: An object with the same name already exists.
+	ErrAlreadyExists
+)
+
+// ErrCode implements `github.com/gzwillyy/components/errors`.Coder interface.
+type ErrCode struct {
+	// C refers to the code of the ErrCode.
+	C int
+
+	// HTTP status that should be used for the associated error code.
+	HTTP int
+
+	// External (user) facing error text.
+	Ext string
+
+	// Ref specify the reference document.
+	Ref string
+}
+
+var _ errors.Coder = &ErrCode{}
+
+// Code returns the integer code of ErrCode.
+func (coder ErrCode) Code() int {
+	return coder.C
+}
+
+// String implements stringer. String returns the external error message,
+// if any.
+func (coder ErrCode) String() string {
+	return coder.Ext
+}
+
+// Reference returns the reference document.
+func (coder ErrCode) Reference() string {
+	return coder.Ref
+}
+
+// HTTPStatus returns the associated HTTP status code, if any. Otherwise,
+// returns 500.
+func (coder ErrCode) HTTPStatus() int {
+	if coder.HTTP == 0 {
+		return http.StatusInternalServerError
+	}
+
+	return coder.HTTP
+}
+
+func register(code int, httpStatus int, message string, refs ...string) {
+	var reference string
+	if len(refs) > 0 {
+		reference = refs[0]
+	}
+
+	errors.MustRegister(&ErrCode{
+		C:    code,
+		HTTP: httpStatus,
+		Ext:  message,
+		Ref:  reference,
+	})
+}
+
+func init() {
+	register(ErrConflict, http.StatusConflict,
+		"The object has been modified, please apply your changes to the latest version and try again")
+	register(ErrInvalidSelector, http.StatusBadRequest, "Invalid label or field selector")
+	register(ErrResourceExpired, http.StatusGone, "The continue token has expired, please restart the list without it")
+	register(ErrInvalidContinue, http.StatusBadRequest, "Invalid continue token")
+	register(ErrValidation, http.StatusBadRequest, "Validation failed")
+	register(ErrNotFound, http.StatusNotFound, "The requested object was not found")
+	register(ErrUnsupportedMediaType, http.StatusUnsupportedMediaType, "The media type of the request body is not supported")
+	register(ErrNotAcceptable, http.StatusNotAcceptable, "The object can't be returned in the requested representation")
+	register(ErrInternal, http.StatusInternalServerError, "Internal server error")
+	register(ErrBind, http.StatusBadRequest, "Error occurred while binding the request body to the struct")
+	register(ErrAlreadyExists, http.StatusConflict, "An object with the same name already exists")
+}
+
+// NewConflict returns a coded error reporting that the object named name could not be updated
+// because its stored resource version differs from the expected one.
+func NewConflict(name string, expected uint64) error {
+	return errors.WithCode(ErrConflict,
+		"operation cannot be fulfilled on %q: the object has been modified, expected resource version %d",
+		name, expected)
+}
+
+// IsConflict determines if err is an error which indicates that an update conflicted with a
+// concurrent modification.
+func IsConflict(err error) bool {
+	return errors.IsCode(err, ErrConflict)
+}
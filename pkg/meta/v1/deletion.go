@@ -0,0 +1,81 @@
+package v1
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Delete performs a graceful deletion of obj, which must be the gorm model embedding ObjectMeta.
+// An object without finalizers, or any object when opts.Unscoped is set, is purged immediately.
+// Otherwise only its deletion timestamp is set; the object is purged by the update that removes its
+// last finalizer. Deleting a terminating object again is a no-op. It returns true if the object
+// was purged.
+func Delete(db *gorm.DB, obj Object, opts DeleteOptions) (bool, error) {
+	if opts.Unscoped || len(obj.GetFinalizers()) == 0 {
+		if err := db.Unscoped().Delete(obj).Error; err != nil {
+			return false, err
+		}
+
+		return true, nil
+	}
+
+	if obj.GetDeletionTimestamp() != nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	if err := db.Model(obj).Update("deletionTimestamp", &now).Error; err != nil {
+		return false, err
+	}
+	obj.SetDeletionTimestamp(&now)
+
+	return false, nil
+}
+
+// IsTerminating returns true if a graceful deletion has been requested for obj.
+func IsTerminating(obj Object) bool {
+	return obj.GetDeletionTimestamp() != nil
+}
+
+// ContainsFinalizer checks an Object that the provided finalizer is present.
+func ContainsFinalizer(obj Object, finalizer string) bool {
+	for _, f := range obj.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+
+	return false
+}
+
+// AddFinalizer accepts an Object and adds the provided finalizer if not present.
+// It returns an indication of whether it updated the object's list of finalizers.
+// Finalizers can't be added to terminating objects.
+func AddFinalizer(obj Object, finalizer string) bool {
+	if IsTerminating(obj) || ContainsFinalizer(obj, finalizer) {
+		return false
+	}
+	obj.SetFinalizers(append(obj.GetFinalizers(), finalizer))
+
+	return true
+}
+
+// RemoveFinalizer accepts an Object and removes the provided finalizer if present.
+// It returns an indication of whether it updated the object's list of finalizers.
+func RemoveFinalizer(obj Object, finalizer string) bool {
+	f := obj.GetFinalizers()
+	length := len(f)
+
+	index := 0
+	for i := 0; i < length; i++ {
+		if f[i] == finalizer {
+			continue
+		}
+		f[index] = f[i]
+		index++
+	}
+	obj.SetFinalizers(f[:index])
+
+	return length != index
+}
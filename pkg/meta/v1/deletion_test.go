@@ -0,0 +1,114 @@
+package v1_test
+
+import (
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+)
+
+func TestDelete(t *testing.T) {
+	db := newDB(t)
+
+	plain := &secret{ObjectMeta: metav1.ObjectMeta{InstanceID: "secret-1", Name: "plain"}}
+	guarded := &secret{ObjectMeta: metav1.ObjectMeta{InstanceID: "secret-2", Name: "guarded", Finalizers: []string{"a", "b"}}}
+	forced := &secret{ObjectMeta: metav1.ObjectMeta{InstanceID: "secret-3", Name: "forced", Finalizers: []string{"a"}}}
+	for _, obj := range []*secret{plain, guarded, forced} {
+		if err := db.Create(obj).Error; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if purged, err := metav1.Delete(db, plain, metav1.DeleteOptions{}); err != nil || !purged {
+		t.Errorf("expected object without finalizers to be purged, got %v, %v", purged, err)
+	}
+	if purged, err := metav1.Delete(db, forced, metav1.DeleteOptions{Unscoped: true}); err != nil || !purged {
+		t.Errorf("expected unscoped delete to purge, got %v, %v", purged, err)
+	}
+	if purged, err := metav1.Delete(db, guarded, metav1.DeleteOptions{}); err != nil || purged {
+		t.Fatalf("expected object with finalizers to be kept, got %v, %v", purged, err)
+	}
+	if !metav1.IsTerminating(guarded) {
+		t.Errorf("expected deletion timestamp to be set")
+	}
+	if metav1.AddFinalizer(guarded, "c") {
+		t.Errorf("expected finalizers not to be added to a terminating object")
+	}
+
+	var stored secret
+	if err := db.First(&stored, guarded.ID).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !metav1.IsTerminating(&stored) || len(stored.Finalizers) != 2 {
+		t.Fatalf("unexpected stored object: %+v", stored.ObjectMeta)
+	}
+
+	for _, finalizer := range []string{"a", "b"} {
+		if !metav1.RemoveFinalizer(&stored, finalizer) {
+			t.Errorf("expected finalizer %s to be removed", finalizer)
+		}
+		if err := db.Save(&stored).Error; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		err := db.First(&secret{}, guarded.ID).Error
+		if purged := errors.Is(err, gorm.ErrRecordNotFound); purged != (finalizer == "b") {
+			t.Errorf("after removing finalizer %s: unexpected lookup result %v", finalizer, err)
+		}
+	}
+
+	var count int64
+	if err := db.Unscoped().Model(&secret{}).Count(&count).Error; err != nil || count != 0 {
+		t.Errorf("expected all objects to be purged, got %d, %v", count, err)
+	}
+}
+
+func TestTerminatingScope(t *testing.T) {
+	db := newDB(t)
+
+	for _, obj := range []*secret{
+		{ObjectMeta: metav1.ObjectMeta{InstanceID: "secret-1", Name: "alive"}},
+		{ObjectMeta: metav1.ObjectMeta{InstanceID: "secret-2", Name: "terminating", Finalizers: []string{"a"}}},
+	} {
+		if err := db.Create(obj).Error; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if obj.Name == "terminating" {
+			if _, err := metav1.Delete(db, obj, metav1.DeleteOptions{}); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	}
+
+	tests := map[metav1.TerminatingPolicy][]string{
+		"":                        {"alive", "terminating"},
+		metav1.TerminatingInclude: {"alive", "terminating"},
+		metav1.TerminatingExclude: {"alive"},
+		metav1.TerminatingOnly:    {"terminating"},
+	}
+	for policy, expected := range tests {
+		scope, err := metav1.ListOptionsScope(metav1.ListOptions{Terminating: policy})
+		if err != nil {
+			t.Fatalf("%q: unexpected error: %v", policy, err)
+		}
+		var list []secret
+		if err := db.Scopes(scope).Order("id").Find(&list).Error; err != nil {
+			t.Fatalf("%q: unexpected error: %v", policy, err)
+		}
+		if len(list) != len(expected) {
+			t.Errorf("%q: expected %v, got %d objects", policy, expected, len(list))
+
+			continue
+		}
+		for i := range list {
+			if list[i].Name != expected[i] {
+				t.Errorf("%q: expected %v, got %s at %d", policy, expected, list[i].Name, i)
+			}
+		}
+	}
+
+	if _, err := metav1.ListOptionsScope(metav1.ListOptions{Terminating: "All"}); err == nil {
+		t.Errorf("expected error for an unknown terminating policy")
+	}
+}
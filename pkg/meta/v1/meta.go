@@ -17,8 +17,20 @@ type ObjectMetaAccessor interface {
 type Object interface {
 	GetID() uint64
 	SetID(id uint64)
+	GetInstanceID() string
+	SetInstanceID(instanceID string)
 	GetName() string
 	SetName(name string)
+	GetResourceVersion() uint64
+	SetResourceVersion(rv uint64)
+	GetLabels() map[string]string
+	SetLabels(labels map[string]string)
+	GetAnnotations() map[string]string
+	SetAnnotations(annotations map[string]string)
+	GetDeletionTimestamp() *time.Time
+	SetDeletionTimestamp(timestamp *time.Time)
+	GetFinalizers() []string
+	SetFinalizers(finalizers []string)
 	GetCreatedAt() time.Time
 	SetCreatedAt(createdAt time.Time)
 	GetUpdatedAt() time.Time
@@ -75,7 +87,24 @@ func (meta *ObjectMeta) GetID() uint64                    { return meta.ID }
 func (meta *ObjectMeta) SetID(id uint64)                  { meta.ID = id }
 func (meta *ObjectMeta) GetName() string                  { return meta.Name }
 func (meta *ObjectMeta) SetName(name string)              { meta.Name = name }
+func (meta *ObjectMeta) GetResourceVersion() uint64       { return meta.ResourceVersion }
+func (meta *ObjectMeta) SetResourceVersion(rv uint64)     { meta.ResourceVersion = rv }
 func (meta *ObjectMeta) GetCreatedAt() time.Time          { return meta.CreatedAt }
 func (meta *ObjectMeta) SetCreatedAt(createdAt time.Time) { meta.CreatedAt = createdAt }
 func (meta *ObjectMeta) GetUpdatedAt() time.Time          { return meta.UpdatedAt }
 func (meta *ObjectMeta) SetUpdatedAt(updatedAt time.Time) { meta.UpdatedAt = updatedAt }
+
+func (meta *ObjectMeta) GetInstanceID() string              { return meta.InstanceID }
+func (meta *ObjectMeta) SetInstanceID(instanceID string)    { meta.InstanceID = instanceID }
+func (meta *ObjectMeta) GetLabels() map[string]string       { return meta.Labels }
+func (meta *ObjectMeta) SetLabels(labels map[string]string) { meta.Labels = labels }
+func (meta *ObjectMeta) GetAnnotations() map[string]string  { return meta.Annotations }
+func (meta *ObjectMeta) SetAnnotations(annotations map[string]string) {
+	meta.Annotations = annotations
+}
+func (meta *ObjectMeta) GetDeletionTimestamp() *time.Time { return meta.DeletionTimestamp }
+func (meta *ObjectMeta) SetDeletionTimestamp(timestamp *time.Time) {
+	meta.DeletionTimestamp = timestamp
+}
+func (meta *ObjectMeta) GetFinalizers() []string           { return meta.Finalizers }
+func (meta *ObjectMeta) SetFinalizers(finalizers []string) { meta.Finalizers = finalizers }
@@ -0,0 +1,284 @@
+package v1
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gzwillyy/components/errors"
+	"github.com/gzwillyy/components/pkg/json"
+)
+
+// SortOrder is the order of a list by the ID of its items.
+type SortOrder string
+
+const (
+	// Ascending lists the items with the lowest IDs first.
+	Ascending SortOrder = "asc"
+	// Descending lists the items with the highest IDs first.
+	Descending SortOrder = "desc"
+)
+
+// continueTokenVersion is the version of the encoding of continue tokens.
+var continueTokenVersion = SchemeGroupVersion.String()
+
+// ContinueTokenTTL is how long a continue token may be used after it was issued. Expired tokens
+// are rejected with an ErrResourceExpired error.
+var ContinueTokenTTL = 15 * time.Minute
+
+// ContinueTokenKey is the HMAC-SHA256 key signing continue tokens, so clients can't forge or extend
+// them. It defaults to a random key, replicas serving the same lists must share a key.
+var ContinueTokenKey = randomKey()
+
+// continueTokenClockSkew is how far in the future a token may be dated, it allows for clock skew
+// between replicas.
+const continueTokenClockSkew = time.Minute
+
+// ContinueToken is the decoded form of the opaque continue token of a paginated list. It marks
+// the position after which the next page starts.
+type ContinueToken struct {
+	// Key is the ID of the last item of the previous page.
+	Key uint64 `json:"key"`
+
+	// Order is the sort order of the list.
+	Order SortOrder `json:"order"`
+
+	// ResourceVersion is the resource version of the last item of the previous page. It is
+	// informational, the next page starts after Key even if the item has changed since.
+	ResourceVersion uint64 `json:"rv"`
+
+	// IssuedAt is the unix time the token was created at, tokens older than ContinueTokenTTL expire.
+	IssuedAt int64 `json:"iat"`
+}
+
+type continueTokenEnvelope struct {
+	Version string `json:"v"`
+	ContinueToken
+}
+
+// Encode returns the opaque string form of the token, signed with ContinueTokenKey.
+func (t ContinueToken) Encode() (string, error) {
+	data, err := json.Marshal(&continueTokenEnvelope{Version: continueTokenVersion, ContinueToken: t})
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data) + "." +
+		base64.RawURLEncoding.EncodeToString(signContinueToken(data)), nil
+}
+
+// DecodeContinueToken parses a token returned in ListMeta.Continue. Malformed tokens and tokens
+// not signed with ContinueTokenKey or dated in the future return an ErrInvalidContinue error,
+// tokens older than ContinueTokenTTL an ErrResourceExpired error.
+func DecodeContinueToken(token string) (*ContinueToken, error) {
+	payload, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return nil, errors.WithCode(ErrInvalidContinue, "continue key is not valid")
+	}
+	data, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		return nil, errors.WrapC(err, ErrInvalidContinue, "continue key is not valid")
+	}
+	mac, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil {
+		return nil, errors.WrapC(err, ErrInvalidContinue, "continue key is not valid")
+	}
+	if !hmac.Equal(mac, signContinueToken(data)) {
+		return nil, errors.WithCode(ErrInvalidContinue, "continue key signature is not valid")
+	}
+
+	var envelope continueTokenEnvelope
+	if err := json.Unmarshal(data, &envelope); err != nil {
+		return nil, errors.WrapC(err, ErrInvalidContinue, "continue key is not valid")
+	}
+	if envelope.Version != continueTokenVersion {
+		return nil, errors.WithCode(ErrInvalidContinue, "continue key %q version is not supported", envelope.Version)
+	}
+	if envelope.Order != Ascending && envelope.Order != Descending {
+		return nil, errors.WithCode(ErrInvalidContinue, "continue key has an invalid sort order %q", envelope.Order)
+	}
+	if time.Until(time.Unix(envelope.IssuedAt, 0)) > continueTokenClockSkew {
+		return nil, errors.WithCode(ErrInvalidContinue, "continue key is issued in the future")
+	}
+	if time.Since(time.Unix(envelope.IssuedAt, 0)) > ContinueTokenTTL {
+		return nil, errors.WithCode(ErrResourceExpired, "continue key issued at %s has expired",
+			time.Unix(envelope.IssuedAt, 0).UTC().Format(time.RFC3339))
+	}
+
+	return &envelope.ContinueToken, nil
+}
+
+// signContinueToken returns the HMAC of the encoded token data.
+func signContinueToken(data []byte) []byte {
+	mac := hmac.New(sha256.New, ContinueTokenKey)
+	mac.Write(data)
+
+	return mac.Sum(nil)
+}
+
+// randomKey returns a random 32 bytes key.
+func randomKey() []byte {
+	key := make([]byte, 32)
+	if _, err := rand.Read(key); err != nil {
+		panic(err)
+	}
+
+	return key
+}
+
+// Pager builds the gorm queries of a list paginated with continue tokens. Pages are ordered by ID,
+// so they stay stable while items are inserted concurrently.
+//
+//	pager, err := metav1.NewPager(opts)
+//	query := db.Model(&User{}).Scopes(selectors).Session(&gorm.Session{})
+//	err = query.Scopes(pager.Scope).Find(&users.Items).Error
+//	err = pager.Complete(query, &users.ListMeta, &users.Items)
+type Pager struct {
+	limit int64
+	order SortOrder
+	after *ContinueToken
+}
+
+// NewPager returns a pager for the Limit, Continue and Order fields of opts. A negative limit or an
+// unknown order returns an ErrValidation error, an invalid continue token an ErrInvalidContinue error.
+func NewPager(opts ListOptions) (*Pager, error) {
+	p := &Pager{order: opts.Order}
+	if opts.Limit != nil {
+		p.limit = *opts.Limit
+	}
+	if p.limit < 0 {
+		return nil, errors.WithCode(ErrValidation, "limit must not be negative")
+	}
+	if p.order == "" {
+		p.order = Ascending
+	}
+	if p.order != Ascending && p.order != Descending {
+		return nil, errors.WithCode(ErrValidation, "invalid sort order %q", opts.Order)
+	}
+
+	if len(opts.Continue) == 0 {
+		return p, nil
+	}
+	if opts.Offset != nil {
+		return nil, errors.WithCode(ErrInvalidContinue, "continue can't be combined with offset")
+	}
+
+	token, err := DecodeContinueToken(opts.Continue)
+	if err != nil {
+		return nil, err
+	}
+	if len(opts.Order) != 0 && opts.Order != token.Order {
+		return nil, errors.WithCode(ErrInvalidContinue, "sort order %q doesn't match the continued list", opts.Order)
+	}
+	p.order, p.after = token.Order, token
+
+	return p, nil
+}
+
+// Scope is a gorm scope that orders the query, starts it after the continue token and fetches one
+// item more than the limit to learn whether more items exist.
+func (p *Pager) Scope(db *gorm.DB) *gorm.DB {
+	column := clause.Column{Table: clause.CurrentTable, Name: "id"}
+	db = db.Order(clause.OrderByColumn{Column: column, Desc: p.order == Descending})
+
+	if p.after != nil {
+		if p.order == Descending {
+			db = db.Where(clause.Lt{Column: column, Value: p.after.Key})
+		} else {
+			db = db.Where(clause.Gt{Column: column, Value: p.after.Key})
+		}
+	}
+	if p.limit > 0 {
+		db = db.Limit(int(p.limit) + 1)
+	}
+
+	return db
+}
+
+// Complete trims the items fetched with Scope to the limit and, if more items exist, sets the
+// continue token and the remaining item count of list. items is a pointer to the slice of objects
+// passed to Find. db is the query without the pager scope, it is used to count the remaining items.
+func (p *Pager) Complete(db *gorm.DB, list *ListMeta, items interface{}) error {
+	list.Continue, list.RemainingItemCount = "", nil
+
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("items must be a pointer to a slice, got %T", items)
+	}
+	v = v.Elem()
+	if p.limit == 0 || int64(v.Len()) <= p.limit {
+		return nil
+	}
+	v.Set(v.Slice(0, int(p.limit)))
+
+	last, err := objectAt(v, v.Len()-1)
+	if err != nil {
+		return err
+	}
+	token := p.tokenAfter(last)
+	if list.Continue, err = token.Encode(); err != nil {
+		return err
+	}
+
+	var remaining int64
+	after := (&Pager{order: p.order, after: token}).Scope
+	if err := db.Session(&gorm.Session{}).Scopes(after).Count(&remaining).Error; err != nil {
+		return err
+	}
+	list.RemainingItemCount = &remaining
+
+	return nil
+}
+
+// Limit returns the maximum number of items of a page, 0 if the list isn't limited.
+func (p *Pager) Limit() int64 {
+	return p.limit
+}
+
+// Order returns the sort order of the list.
+func (p *Pager) Order() SortOrder {
+	return p.order
+}
+
+// After returns the continue token the page starts after, nil for the first page.
+func (p *Pager) After() *ContinueToken {
+	return p.after
+}
+
+// ContinueAfter returns the encoded continue token of a page ending with last. Storage not backed
+// by gorm uses it to paginate like Scope and Complete do.
+func (p *Pager) ContinueAfter(last Object) (string, error) {
+	return p.tokenAfter(last).Encode()
+}
+
+// tokenAfter returns the continue token of a page ending with last.
+func (p *Pager) tokenAfter(last Object) *ContinueToken {
+	return &ContinueToken{
+		Key:             last.GetID(),
+		Order:           p.order,
+		ResourceVersion: last.GetResourceVersion(),
+		IssuedAt:        time.Now().Unix(),
+	}
+}
+
+// objectAt returns the object at index i of a slice of structs or of pointers to structs.
+func objectAt(v reflect.Value, i int) (Object, error) {
+	item := v.Index(i)
+	if item.Kind() != reflect.Ptr {
+		item = item.Addr()
+	}
+	obj, ok := item.Interface().(Object)
+	if !ok {
+		return nil, fmt.Errorf("%v doesn't implement the metav1.Object interface", item.Type())
+	}
+
+	return obj, nil
+}
@@ -0,0 +1,172 @@
+package v1_test
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/gzwillyy/components/errors"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+)
+
+type secretList struct {
+	metav1.ListMeta `json:",inline"`
+
+	Items []*secret `json:"items"`
+}
+
+func TestPager(t *testing.T) {
+	db := newDB(t)
+	for i := 1; i <= 7; i++ {
+		obj := &secret{ObjectMeta: metav1.ObjectMeta{
+			InstanceID: fmt.Sprintf("secret-%d", i),
+			Name:       fmt.Sprintf("secret-%d", i),
+			Labels:     map[string]string{"odd": fmt.Sprint(i%2 == 1)},
+		}}
+		if err := db.Create(obj).Error; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	list := func(opts metav1.ListOptions) (*secretList, error) {
+		selectors, err := metav1.ListOptionsScope(opts)
+		if err != nil {
+			return nil, err
+		}
+		pager, err := metav1.NewPager(opts)
+		if err != nil {
+			return nil, err
+		}
+		query := db.Model(&secret{}).Scopes(selectors).Session(&gorm.Session{})
+
+		var l secretList
+		if err := query.Scopes(pager.Scope).Find(&l.Items).Error; err != nil {
+			return nil, err
+		}
+
+		return &l, pager.Complete(query, &l.ListMeta, &l.Items)
+	}
+
+	tests := []struct {
+		opts      metav1.ListOptions
+		expected  [][]uint64
+		remaining []int64
+	}{
+		{
+			opts:      metav1.ListOptions{Limit: int64Ptr(3)},
+			expected:  [][]uint64{{1, 2, 3}, {4, 5, 6}, {7}},
+			remaining: []int64{4, 1},
+		},
+		{
+			opts:      metav1.ListOptions{Limit: int64Ptr(3), Order: metav1.Descending},
+			expected:  [][]uint64{{7, 6, 5}, {4, 3, 2}, {1}},
+			remaining: []int64{4, 1},
+		},
+		{
+			opts:      metav1.ListOptions{Limit: int64Ptr(2), LabelSelector: "odd=true"},
+			expected:  [][]uint64{{1, 3}, {5, 7}},
+			remaining: []int64{2},
+		},
+		{
+			opts:     metav1.ListOptions{},
+			expected: [][]uint64{{1, 2, 3, 4, 5, 6, 7}},
+		},
+	}
+	for _, tt := range tests {
+		opts := tt.opts
+		for page, expected := range tt.expected {
+			l, err := list(opts)
+			if err != nil {
+				t.Fatalf("%+v: unexpected error: %v", opts, err)
+			}
+			ids := make([]uint64, 0, len(l.Items))
+			for _, item := range l.Items {
+				ids = append(ids, item.ID)
+			}
+			if fmt.Sprint(ids) != fmt.Sprint(expected) {
+				t.Errorf("%+v: page %d: expected %v, got %v", tt.opts, page, expected, ids)
+			}
+
+			last := page == len(tt.expected)-1
+			if last != (l.Continue == "") {
+				t.Errorf("%+v: page %d: unexpected continue token %q", tt.opts, page, l.Continue)
+			}
+			if last {
+				if l.RemainingItemCount != nil {
+					t.Errorf("%+v: unexpected remaining item count on the last page", tt.opts)
+				}
+
+				break
+			}
+			if l.RemainingItemCount == nil || *l.RemainingItemCount != tt.remaining[page] {
+				t.Errorf("%+v: page %d: expected %d remaining items, got %v", tt.opts, page, tt.remaining[page], l.RemainingItemCount)
+			}
+			opts.Continue = l.Continue
+			opts.Order = ""
+		}
+	}
+}
+
+func TestContinueToken(t *testing.T) {
+	token, err := metav1.ContinueToken{Key: 3, Order: metav1.Ascending, ResourceVersion: 2, IssuedAt: time.Now().Unix()}.Encode()
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	decoded, err := metav1.DecodeContinueToken(token)
+	if err != nil || decoded.Key != 3 || decoded.ResourceVersion != 2 {
+		t.Errorf("unexpected token %+v, %v", decoded, err)
+	}
+
+	expired, _ := metav1.ContinueToken{
+		Key:      3,
+		Order:    metav1.Ascending,
+		IssuedAt: time.Now().Add(-metav1.ContinueTokenTTL - time.Minute).Unix(),
+	}.Encode()
+	_, err = metav1.DecodeContinueToken(expired)
+	if !errors.IsCode(err, metav1.ErrResourceExpired) || errors.ParseCoder(err).HTTPStatus() != http.StatusGone {
+		t.Errorf("expected ErrResourceExpired, got %v", err)
+	}
+
+	if _, err := metav1.DecodeContinueToken("not-a-token"); !errors.IsCode(err, metav1.ErrInvalidContinue) {
+		t.Errorf("expected ErrInvalidContinue, got %v", err)
+	}
+
+	// tokens are signed, a client can't change them or date them in the future
+	payload, _, _ := strings.Cut(token, ".")
+	forged, _ := base64.RawURLEncoding.DecodeString(payload)
+	forged = bytes.Replace(forged, []byte(`"key":3`), []byte(`"key":4`), 1)
+	if _, err := metav1.DecodeContinueToken(base64.RawURLEncoding.EncodeToString(forged) + token[len(payload):]); !errors.IsCode(err, metav1.ErrInvalidContinue) {
+		t.Errorf("expected ErrInvalidContinue for a forged token, got %v", err)
+	}
+	if _, err := metav1.DecodeContinueToken(payload); !errors.IsCode(err, metav1.ErrInvalidContinue) {
+		t.Errorf("expected ErrInvalidContinue for an unsigned token, got %v", err)
+	}
+	future, _ := metav1.ContinueToken{Key: 3, Order: metav1.Ascending, IssuedAt: time.Now().Add(time.Hour).Unix()}.Encode()
+	if _, err := metav1.DecodeContinueToken(future); !errors.IsCode(err, metav1.ErrInvalidContinue) {
+		t.Errorf("expected ErrInvalidContinue for a token issued in the future, got %v", err)
+	}
+	if _, err := metav1.NewPager(metav1.ListOptions{Continue: token, Offset: int64Ptr(1)}); !errors.IsCode(err, metav1.ErrInvalidContinue) {
+		t.Errorf("expected ErrInvalidContinue combining continue and offset, got %v", err)
+	}
+	if _, err := metav1.NewPager(metav1.ListOptions{Continue: token, Order: metav1.Descending}); !errors.IsCode(err, metav1.ErrInvalidContinue) {
+		t.Errorf("expected ErrInvalidContinue changing the order, got %v", err)
+	}
+
+	// invalid parameters don't invalidate the continue token
+	if _, err := metav1.NewPager(metav1.ListOptions{Limit: int64Ptr(-1)}); !errors.IsCode(err, metav1.ErrValidation) {
+		t.Errorf("expected ErrValidation for a negative limit, got %v", err)
+	}
+	if _, err := metav1.NewPager(metav1.ListOptions{Continue: token, Order: "random"}); !errors.IsCode(err, metav1.ErrValidation) {
+		t.Errorf("expected ErrValidation for an unknown order, got %v", err)
+	}
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
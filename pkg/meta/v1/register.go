@@ -0,0 +1,9 @@
+package v1
+
+import "github.com/gzwillyy/components/pkg/scheme"
+
+// GroupName is the group name of the types of this package.
+const GroupName = "meta.gzwillyy.com"
+
+// SchemeGroupVersion is the group version of the types of this package.
+var SchemeGroupVersion = scheme.GroupVersion{Group: GroupName, Version: "v1"}
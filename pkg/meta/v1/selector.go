@@ -0,0 +1,238 @@
+package v1
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gzwillyy/components/errors"
+	"github.com/gzwillyy/components/pkg/fields"
+	"github.com/gzwillyy/components/pkg/labels"
+	"github.com/gzwillyy/components/pkg/selection"
+)
+
+// labelsColumn is the column label selectors are evaluated against.
+const labelsColumn = "labelsShadow"
+
+// metadataFields are the columns of ObjectMeta field selectors may compare, with or without the
+// "metadata." prefix.
+var metadataFields = []string{"name", "instanceID"}
+
+// FieldSelectable is implemented by objects whose columns other than the metadata fields name and
+// instanceID may be compared by field selectors. Columns holding secrets, e.g. password hashes,
+// must not be selectable: a client could guess their values one list request at a time.
+type FieldSelectable interface {
+	// SelectableFields returns the columns field selectors may compare.
+	SelectableFields() []string
+}
+
+// SelectableFields returns the columns obj declares selectable by implementing FieldSelectable.
+func SelectableFields(obj interface{}) []string {
+	if s, ok := obj.(FieldSelectable); ok {
+		return s.SelectableFields()
+	}
+
+	return nil
+}
+
+// FieldSelectorColumn returns the column compared by the field of a field selector. The field is
+// one of the metadata fields, optionally prefixed with "metadata.", or one of selectable, other
+// fields return an ErrInvalidSelector error.
+func FieldSelectorColumn(field string, selectable []string) (string, error) {
+	if column := strings.TrimPrefix(field, "metadata."); column != field {
+		if containsString(metadataFields, column) {
+			return column, nil
+		}
+	} else if containsString(metadataFields, field) || containsString(selectable, field) {
+		return field, nil
+	}
+
+	return "", errors.WithCode(ErrInvalidSelector, "field %q is not supported by field selectors", field)
+}
+
+// ListOptionsScope returns a gorm scope restricting a query to the rows matching the label and
+// field selectors and the terminating policy of opts. Field selectors may compare the metadata
+// fields and the columns in selectable. A selector that can't be parsed returns an
+// ErrInvalidSelector error.
+func ListOptionsScope(opts ListOptions, selectable ...string) (func(*gorm.DB) *gorm.DB, error) {
+	labelSelector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, errors.WrapC(err, ErrInvalidSelector, "invalid label selector %q", opts.LabelSelector)
+	}
+	fieldSelector, err := fields.ParseSelector(opts.FieldSelector)
+	if err != nil {
+		return nil, errors.WrapC(err, ErrInvalidSelector, "invalid field selector %q", opts.FieldSelector)
+	}
+
+	terminating, err := TerminatingScope(opts.Terminating)
+	if err != nil {
+		return nil, err
+	}
+
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Scopes(LabelSelectorScope(labelSelector), FieldSelectorScope(fieldSelector, selectable...), terminating)
+	}, nil
+}
+
+// TerminatingScope returns a gorm scope listing terminating objects according to policy. An empty
+// policy includes them.
+func TerminatingScope(policy TerminatingPolicy) (func(*gorm.DB) *gorm.DB, error) {
+	column := clause.Column{Table: clause.CurrentTable, Name: "deletionTimestamp"}
+
+	switch policy {
+	case "", TerminatingInclude:
+		return func(db *gorm.DB) *gorm.DB { return db }, nil
+	case TerminatingExclude:
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where(clause.Eq{Column: column, Value: nil})
+		}, nil
+	case TerminatingOnly:
+		return func(db *gorm.DB) *gorm.DB {
+			return db.Where(clause.Neq{Column: column, Value: nil})
+		}, nil
+	default:
+		return nil, errors.WithCode(ErrInvalidSelector, "unknown terminating policy %q", policy)
+	}
+}
+
+// LabelSelectorScope returns a gorm scope restricting a query to the rows whose labels match
+// selector. Labels are read from the JSON encoded labels column with the JSON functions of the
+// database, MySQL, PostgreSQL and SQLite are supported. Like labels.Selector, "!=" and "notin"
+// also match rows without the label, "gt" and "lt" compare the label values as integers.
+func LabelSelectorScope(selector labels.Selector) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		requirements, selectable := selector.Requirements()
+		if !selectable {
+			return db.Where("1 = 0")
+		}
+
+		for _, r := range requirements {
+			expr, err := labelRequirementExpr(db, r)
+			if err != nil {
+				_ = db.AddError(err)
+
+				return db
+			}
+			db = db.Where(expr)
+		}
+
+		return db
+	}
+}
+
+// FieldSelectorScope returns a gorm scope restricting a query to the rows whose columns match
+// selector. Fields are the metadata fields name and instanceID, an optional "metadata." prefix is
+// ignored, so "metadata.name=colin" compares the name column, or one of the columns in selectable.
+func FieldSelectorScope(selector fields.Selector, selectable ...string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		for _, r := range selector.Requirements() {
+			column, err := FieldSelectorColumn(r.Field, selectable)
+			if err != nil {
+				_ = db.AddError(err)
+
+				return db
+			}
+
+			col := clause.Column{Table: clause.CurrentTable, Name: column}
+			switch r.Operator {
+			case selection.Equals, selection.DoubleEquals:
+				db = db.Where(clause.Eq{Column: col, Value: r.Value})
+			case selection.NotEquals:
+				db = db.Where(clause.Neq{Column: col, Value: r.Value})
+			default:
+				_ = db.AddError(errors.WithCode(ErrInvalidSelector, "operator %q is not supported by field selectors", r.Operator))
+
+				return db
+			}
+		}
+
+		return db
+	}
+}
+
+// labelRequirementExpr translates a label requirement into a SQL expression on the labels column.
+func labelRequirementExpr(db *gorm.DB, r labels.Requirement) (clause.Expression, error) {
+	value, err := labelValueExpr(db, r.Key())
+	if err != nil {
+		return nil, err
+	}
+
+	values := r.Values().List()
+	args := make([]interface{}, 0, len(values))
+	for _, v := range values {
+		args = append(args, v)
+	}
+
+	switch r.Operator() {
+	case selection.Exists:
+		return clause.Expr{SQL: "? IS NOT NULL", Vars: []interface{}{value}}, nil
+	case selection.DoesNotExist:
+		return clause.Expr{SQL: "? IS NULL", Vars: []interface{}{value}}, nil
+	case selection.Equals, selection.DoubleEquals:
+		return clause.Expr{SQL: "? = ?", Vars: []interface{}{value, args[0]}}, nil
+	case selection.NotEquals:
+		return clause.Expr{SQL: "(? IS NULL OR ? <> ?)", Vars: []interface{}{value, value, args[0]}}, nil
+	case selection.In:
+		return clause.Expr{SQL: "? IN ?", Vars: []interface{}{value, args}}, nil
+	case selection.NotIn:
+		return clause.Expr{SQL: "(? IS NULL OR ? NOT IN ?)", Vars: []interface{}{value, value, args}}, nil
+	case selection.GreaterThan, selection.LessThan:
+		n, err := strconv.ParseInt(values[0], 10, 64)
+		if err != nil {
+			return nil, errors.WrapC(err, ErrInvalidSelector, "invalid integer value for label %q", r.Key())
+		}
+		op := ">"
+		if r.Operator() == selection.LessThan {
+			op = "<"
+		}
+
+		return clause.Expr{SQL: "? " + op + " ?", Vars: []interface{}{integerExpr(db, value), n}}, nil
+	default:
+		return nil, errors.WithCode(ErrInvalidSelector, "operator %q is not supported by label selectors", r.Operator())
+	}
+}
+
+// labelValueExpr returns the expression reading the value of label key as text from the labels
+// column, it is NULL for rows without the label.
+func labelValueExpr(db *gorm.DB, key string) (clause.Expression, error) {
+	column := clause.Column{Table: clause.CurrentTable, Name: labelsColumn}
+	// label keys are validated and never contain double quotes
+	path := fmt.Sprintf(`$."%s"`, key)
+
+	switch name := db.Dialector.Name(); name {
+	case "mysql":
+		return clause.Expr{SQL: "JSON_UNQUOTE(JSON_EXTRACT(?, ?))", Vars: []interface{}{column, path}}, nil
+	case "sqlite":
+		return clause.Expr{SQL: "json_extract(?, ?)", Vars: []interface{}{column, path}}, nil
+	case "postgres":
+		return clause.Expr{SQL: "(CAST(? AS jsonb) ->> ?)", Vars: []interface{}{column, key}}, nil
+	default:
+		return nil, fmt.Errorf("label selectors are not supported for %s databases", name)
+	}
+}
+
+// integerExpr casts the text expression value to an integer.
+func integerExpr(db *gorm.DB, value clause.Expression) clause.Expression {
+	typ := "INTEGER"
+	switch db.Dialector.Name() {
+	case "mysql":
+		typ = "SIGNED"
+	case "postgres":
+		typ = "BIGINT"
+	}
+
+	return clause.Expr{SQL: "CAST(? AS " + typ + ")", Vars: []interface{}{value}}
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
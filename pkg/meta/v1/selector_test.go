@@ -0,0 +1,98 @@
+package v1_test
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/gzwillyy/components/errors"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+)
+
+func TestListOptionsScope(t *testing.T) {
+	db := newDB(t)
+
+	for _, obj := range []*secret{
+		{ObjectMeta: metav1.ObjectMeta{InstanceID: "secret-1", Name: "alpha", Labels: map[string]string{"app": "iam", "tier": "1"}}},
+		{ObjectMeta: metav1.ObjectMeta{InstanceID: "secret-2", Name: "beta", Labels: map[string]string{"app": "iam", "example.com/tier": "3"}}},
+		{ObjectMeta: metav1.ObjectMeta{InstanceID: "secret-3", Name: "gamma", Labels: map[string]string{"app": "apiserver", "tier": "5"}}},
+		{ObjectMeta: metav1.ObjectMeta{InstanceID: "secret-4", Name: "delta", Annotations: map[string]string{"owner": "colin"}}, Description: "delta"},
+	} {
+		if err := db.Create(obj).Error; err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	tests := []struct {
+		opts     metav1.ListOptions
+		expected []string
+	}{
+		{opts: metav1.ListOptions{}, expected: []string{"alpha", "beta", "delta", "gamma"}},
+		{opts: metav1.ListOptions{LabelSelector: "app=iam"}, expected: []string{"alpha", "beta"}},
+		{opts: metav1.ListOptions{LabelSelector: "app!=iam"}, expected: []string{"delta", "gamma"}},
+		{opts: metav1.ListOptions{LabelSelector: "app in (iam, apiserver),tier"}, expected: []string{"alpha", "gamma"}},
+		{opts: metav1.ListOptions{LabelSelector: "app notin (iam)"}, expected: []string{"delta", "gamma"}},
+		{opts: metav1.ListOptions{LabelSelector: "!app"}, expected: []string{"delta"}},
+		{opts: metav1.ListOptions{LabelSelector: "example.com/tier"}, expected: []string{"beta"}},
+		{opts: metav1.ListOptions{LabelSelector: "tier>2"}, expected: []string{"gamma"}},
+		{opts: metav1.ListOptions{LabelSelector: "tier<2"}, expected: []string{"alpha"}},
+		{opts: metav1.ListOptions{FieldSelector: "metadata.name=beta"}, expected: []string{"beta"}},
+		{opts: metav1.ListOptions{LabelSelector: "app=iam", FieldSelector: "name!=beta"}, expected: []string{"alpha"}},
+		{opts: metav1.ListOptions{FieldSelector: "metadata.instanceID=secret-3"}, expected: []string{"gamma"}},
+		{opts: metav1.ListOptions{FieldSelector: "description=delta"}, expected: []string{"delta"}},
+	}
+	for _, tt := range tests {
+		scope, err := metav1.ListOptionsScope(tt.opts, "description")
+		if err != nil {
+			t.Fatalf("%+v: unexpected error: %v", tt.opts, err)
+		}
+		var list []secret
+		if err := db.Scopes(scope).Find(&list).Error; err != nil {
+			t.Fatalf("%+v: unexpected error: %v", tt.opts, err)
+		}
+		names := make([]string, 0, len(list))
+		for _, obj := range list {
+			names = append(names, obj.Name)
+		}
+		sort.Strings(names)
+		if len(names) != len(tt.expected) {
+			t.Errorf("%+v: expected %v, got %v", tt.opts, tt.expected, names)
+
+			continue
+		}
+		for i := range names {
+			if names[i] != tt.expected[i] {
+				t.Errorf("%+v: expected %v, got %v", tt.opts, tt.expected, names)
+
+				break
+			}
+		}
+	}
+
+	var stored secret
+	if err := db.Where("name = ?", "delta").First(&stored).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored.Annotations["owner"] != "colin" || len(stored.Labels) != 0 {
+		t.Errorf("unexpected labels %v and annotations %v", stored.Labels, stored.Annotations)
+	}
+}
+
+func TestListOptionsScopeInvalidSelector(t *testing.T) {
+	db := newDB(t)
+
+	if _, err := metav1.ListOptionsScope(metav1.ListOptions{LabelSelector: "app in iam"}); !errors.IsCode(err, metav1.ErrInvalidSelector) {
+		t.Errorf("expected ErrInvalidSelector, got %v", err)
+	}
+
+	// only the metadata fields and the selectable columns may be compared
+	for _, selector := range []string{"spec.name'=x", "description=x", "metadata.description=x", "resourceVersion=1"} {
+		scope, err := metav1.ListOptionsScope(metav1.ListOptions{FieldSelector: selector})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var list []secret
+		if err := db.Scopes(scope).Find(&list).Error; !errors.IsCode(err, metav1.ErrInvalidSelector) {
+			t.Errorf("%s: expected ErrInvalidSelector, got %v", selector, err)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"github.com/gzwillyy/components/pkg/json"
 )
@@ -53,6 +54,20 @@ type TypeMeta struct {
 // various status objects. A resource may have only one of {ObjectMeta, ListMeta}.
 type ListMeta struct {
 	TotalCount int64 `json:"totalCount,omitempty"`
+
+	// Continue may be set if the user set a limit on the number of items returned, and indicates that
+	// the server has more data available. The value is opaque and may be used to issue another request
+	// to the endpoint that served this list to retrieve the next set of available objects. Continuing a
+	// list may not be possible if the server configuration has changed or more than a few minutes have
+	// passed. The continue field is empty when there are no more items to return.
+	// +optional
+	Continue string `json:"continue,omitempty"`
+
+	// RemainingItemCount is the number of subsequent items in the list which are not included in this
+	// list response, counted with the selectors of the request. It is only set for responses of
+	// paginated requests that have more items.
+	// +optional
+	RemainingItemCount *int64 `json:"remainingItemCount,omitempty"`
 }
 
 // ObjectMeta is metadata that all persisted resources must have, which includes all objects
@@ -84,7 +99,34 @@ type ObjectMeta struct {
 	// definition.
 	// It will be generated automated only if Name is not specified.
 	// Cannot be updated.
-	Name string `json:"name,omitempty" gorm:"column:name;type:varchar(64);not null" validate:"name"`
+	Name string `json:"name,omitempty" gorm:"column:name;type:varchar(64);not null;uniqueIndex" validate:"name"`
+
+	// ResourceVersion is an opaque value that represents the internal version of this object that can
+	// be used by clients to determine when objects have changed. It is set to 1 on creation and
+	// incremented on every update. An update is only applied if the stored resource version still
+	// equals the resource version of the updated object, otherwise a conflict error is returned.
+	//
+	// Populated by the system.
+	// Read-only.
+	ResourceVersion uint64 `json:"resourceVersion,omitempty" gorm:"column:resourceVersion;not null;default:0"`
+
+	// Map of string keys and values that can be used to organize and categorize
+	// (scope and select) objects. May match selectors of list queries.
+	// +optional
+	Labels map[string]string `json:"labels,omitempty" gorm:"-"`
+
+	// LabelsShadow is the JSON encoded shadow of Labels, label selectors are evaluated against it.
+	// DO NOT modify directly.
+	LabelsShadow string `json:"-" gorm:"column:labelsShadow"`
+
+	// Annotations is an unstructured key value map stored with a resource that may be
+	// set by external tools to store and retrieve arbitrary metadata. They are not
+	// queryable and should be preserved when modifying objects.
+	// +optional
+	Annotations map[string]string `json:"annotations,omitempty" gorm:"-"`
+
+	// AnnotationsShadow is the JSON encoded shadow of Annotations. DO NOT modify directly.
+	AnnotationsShadow string `json:"-" gorm:"column:annotationsShadow"`
 
 	// Extend store the fields that need to be added, but do not want to add a new table column, will not be stored in db.
 	Extend Extend `json:"extend,omitempty" gorm:"-" validate:"omitempty"`
@@ -109,25 +151,71 @@ type ObjectMeta struct {
 	// Null for lists.
 	UpdatedAt time.Time `json:"updatedAt,omitempty" gorm:"column:updatedAt"`
 
-	// DeletedAt is RFC 3339 date and time at which this resource will be deleted. This
+	// DeletionTimestamp is RFC 3339 date and time at which this resource will be deleted. This
 	// field is set by the server when a graceful deletion is requested by the user, and is not
-	// directly settable by a client.
+	// directly settable by a client. The resource is purged once all finalizers have been
+	// removed, until then it is terminating and still visible.
 	//
 	// Populated by the system when a graceful deletion is requested.
 	// Read-only.
-	// DeletedAt gorm.DeletedAt `json:"-" gorm:"column:deletedAt;index:idx_deletedAt"`
+	DeletionTimestamp *time.Time `json:"deletionTimestamp,omitempty" gorm:"column:deletionTimestamp;index:idx_deletionTimestamp"`
+
+	// Must be empty before the object is deleted from the registry. Each entry
+	// is an identifier for the responsible component that will remove the entry
+	// from the list. If the deletionTimestamp of the object is non-nil, entries
+	// in this list can only be removed.
+	// +optional
+	Finalizers []string `json:"finalizers,omitempty" gorm:"-"`
+
+	// FinalizersShadow is the JSON encoded shadow of Finalizers. DO NOT modify directly.
+	FinalizersShadow string `json:"-" gorm:"column:finalizersShadow"`
 }
 
 // BeforeCreate run before create database record.
 func (obj *ObjectMeta) BeforeCreate(tx *gorm.DB) error {
 	obj.ExtendShadow = obj.Extend.String()
+	obj.LabelsShadow = mapShadow(obj.Labels)
+	obj.AnnotationsShadow = mapShadow(obj.Annotations)
+	obj.FinalizersShadow = listShadow(obj.Finalizers)
+	if obj.ResourceVersion == 0 {
+		obj.ResourceVersion = 1
+	}
 
 	return nil
 }
 
-// BeforeUpdate run before update database record.
+// BeforeUpdate run before update database record. The update is made conditional on the
+// resource version of obj and increments it. Batch updates without a primary key are not versioned.
 func (obj *ObjectMeta) BeforeUpdate(tx *gorm.DB) error {
 	obj.ExtendShadow = obj.Extend.String()
+	obj.LabelsShadow = mapShadow(obj.Labels)
+	obj.AnnotationsShadow = mapShadow(obj.Annotations)
+	obj.FinalizersShadow = listShadow(obj.Finalizers)
+	if obj.ID == 0 {
+		return nil
+	}
+
+	tx.Statement.AddClause(clause.Where{Exprs: []clause.Expression{
+		clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "resourceVersion"}, Value: obj.ResourceVersion},
+	}})
+	tx.Statement.SetColumn("resourceVersion", obj.ResourceVersion+1)
+
+	return nil
+}
+
+// AfterUpdate run after update database record. No updated rows mean that the object has been
+// modified or deleted since it was read. A terminating object whose last finalizer has been
+// removed is purged.
+func (obj *ObjectMeta) AfterUpdate(tx *gorm.DB) error {
+	if obj.ID == 0 || tx.DryRun {
+		return nil
+	}
+	if tx.Statement.DB.RowsAffected == 0 {
+		return NewConflict(obj.Name, obj.ResourceVersion-1)
+	}
+	if obj.DeletionTimestamp != nil && len(obj.Finalizers) == 0 {
+		return tx.Unscoped().Delete(tx.Statement.Model).Error
+	}
 
 	return nil
 }
@@ -137,8 +225,49 @@ func (obj *ObjectMeta) AfterFind(tx *gorm.DB) error {
 	if err := json.Unmarshal([]byte(obj.ExtendShadow), &obj.Extend); err != nil {
 		return err
 	}
+	if err := unmarshalMapShadow(obj.LabelsShadow, &obj.Labels); err != nil {
+		return err
+	}
 
-	return nil
+	if err := unmarshalMapShadow(obj.AnnotationsShadow, &obj.Annotations); err != nil {
+		return err
+	}
+	if len(obj.FinalizersShadow) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal([]byte(obj.FinalizersShadow), &obj.Finalizers)
+}
+
+// mapShadow returns the JSON encoded shadow of m. Empty maps are stored as "{}" so the shadow is
+// always a valid JSON document for the JSON functions of the database.
+func mapShadow(m map[string]string) string {
+	if len(m) == 0 {
+		return "{}"
+	}
+	data, _ := json.Marshal(m)
+
+	return string(data)
+}
+
+// listShadow returns the JSON encoded shadow of l, empty lists are stored as "[]".
+func listShadow(l []string) string {
+	if len(l) == 0 {
+		return "[]"
+	}
+	data, _ := json.Marshal(l)
+
+	return string(data)
+}
+
+// unmarshalMapShadow decodes a shadow written by mapShadow, rows created before the shadow
+// column existed have an empty shadow.
+func unmarshalMapShadow(shadow string, m *map[string]string) error {
+	if len(shadow) == 0 {
+		return nil
+	}
+
+	return json.Unmarshal([]byte(shadow), m)
 }
 
 // ListOptions is the query options to a standard REST list call.
@@ -151,16 +280,46 @@ type ListOptions struct {
 	// FieldSelector restricts the list of returned objects by their fields. Defaults to everything.
 	FieldSelector string `json:"fieldSelector,omitempty" form:"fieldSelector"`
 
+	// Terminating controls whether objects with a deletion timestamp are listed.
+	// Defaults to Include.
+	// +optional
+	Terminating TerminatingPolicy `json:"terminating,omitempty" form:"terminating" validate:"omitempty,oneof=Include Exclude Only"`
+
 	// TimeoutSeconds specifies the seconds of ClientIP type session sticky time.
 	TimeoutSeconds *int64 `json:"timeoutSeconds,omitempty"`
 
 	// Offset specify the number of records to skip before starting to return the records.
-	Offset *int64 `json:"offset,omitempty" form:"offset"`
+	Offset *int64 `json:"offset,omitempty" form:"offset" validate:"omitempty,min=0"`
 
 	// Limit specify the number of records to be retrieved.
-	Limit *int64 `json:"limit,omitempty" form:"limit"`
+	Limit *int64 `json:"limit,omitempty" form:"limit" validate:"omitempty,min=0"`
+
+	// The continue option should be set when retrieving more results from the server. Since this value
+	// is server defined, clients may only use the continue value from a previous query result with
+	// identical query parameters (except for the value of continue). If the token has expired the
+	// server responds with a 410 ResourceExpired error, the client must restart its list without the
+	// continue field. Continue can't be combined with Offset.
+	// +optional
+	Continue string `json:"continue,omitempty" form:"continue"`
+
+	// Order specifies the order of the items by their ID, "asc" (the default) or "desc". A continued
+	// list keeps the order of its first page.
+	// +optional
+	Order SortOrder `json:"order,omitempty" form:"order" validate:"omitempty,oneof=asc desc"`
 }
 
+// TerminatingPolicy specifies how list calls treat objects that are being deleted.
+type TerminatingPolicy string
+
+const (
+	// TerminatingInclude lists terminating objects together with all other objects.
+	TerminatingInclude TerminatingPolicy = "Include"
+	// TerminatingExclude hides terminating objects.
+	TerminatingExclude TerminatingPolicy = "Exclude"
+	// TerminatingOnly lists terminating objects only, e.g. for controllers handling finalizers.
+	TerminatingOnly TerminatingPolicy = "Only"
+)
+
 // ExportOptions is the query options to the standard REST get call.
 // Deprecated. Planned for removal in 1.18.
 type ExportOptions struct {
@@ -183,8 +342,10 @@ type GetOptions struct {
 type DeleteOptions struct {
 	TypeMeta `json:",inline"`
 
+	// Unscoped purges the object immediately, ignoring its finalizers. Without it an object with
+	// finalizers only gets a deletion timestamp and is purged once its finalizers are removed.
 	// +optional
-	Unscoped bool `json:"unscoped"`
+	Unscoped bool `json:"unscoped" form:"unscoped"`
 }
 
 // CreateOptions may be provided when creating an API object.
@@ -197,7 +358,7 @@ type CreateOptions struct {
 	// request. Valid values are:
 	// - All: all dry run stages will be processed
 	// +optional
-	DryRun []string `json:"dryRun,omitempty"`
+	DryRun []string `json:"dryRun,omitempty" form:"dryRun"`
 }
 
 // PatchOptions may be provided when patching an API object.
@@ -211,13 +372,13 @@ type PatchOptions struct {
 	// request. Valid values are:
 	// - All: all dry run stages will be processed
 	// +optional
-	DryRun []string `json:"dryRun,omitempty"`
+	DryRun []string `json:"dryRun,omitempty" form:"dryRun"`
 
-	// Force is going to "force" Apply requests. It means user will
-	// re-acquire conflicting fields owned by other people. Force
-	// flag must be unset for non-apply patch requests.
+	// Force is going to "force" patch requests. A patch that sets a resourceVersion
+	// different from the stored one conflicts with a concurrent modification and is
+	// rejected, unless Force is set; then the patch is applied to the latest version.
 	// +optional
-	Force bool `json:"force,omitempty"`
+	Force bool `json:"force,omitempty" form:"force"`
 }
 
 // UpdateOptions may be provided when updating an API object.
@@ -231,7 +392,37 @@ type UpdateOptions struct {
 	// request. Valid values are:
 	// - All: all dry run stages will be processed
 	// +optional
-	DryRun []string `json:"dryRun,omitempty"`
+	DryRun []string `json:"dryRun,omitempty" form:"dryRun"`
+
+	// Must be fulfilled before the object is updated. The update is rejected with a conflict
+	// error if the stored object doesn't match them.
+	// +optional
+	Preconditions *Preconditions `json:"preconditions,omitempty"`
+}
+
+// Preconditions must be fulfilled before an operation (update, delete, etc.) is carried out.
+type Preconditions struct {
+	// Specifies the target ResourceVersion
+	// +optional
+	ResourceVersion *uint64 `json:"resourceVersion,omitempty"`
+}
+
+// NewRVPreconditions returns a Preconditions with ResourceVersion set.
+func NewRVPreconditions(rv uint64) *Preconditions {
+	return &Preconditions{ResourceVersion: &rv}
+}
+
+// Check returns a conflict error if obj doesn't fulfill the preconditions. A nil Preconditions is
+// always fulfilled.
+func (p *Preconditions) Check(obj Object) error {
+	if p == nil || p.ResourceVersion == nil {
+		return nil
+	}
+	if *p.ResourceVersion != obj.GetResourceVersion() {
+		return NewConflict(obj.GetName(), *p.ResourceVersion)
+	}
+
+	return nil
 }
 
 // AuthorizeOptions may be provided when authorize an API object.
@@ -246,4 +437,8 @@ type TableOptions struct {
 	// NoHeaders is only exposed for internal callers. It is not included in our OpenAPI definitions
 	// and may be removed as a field in a future release.
 	NoHeaders bool `json:"-"`
+
+	// IncludeObject decides whether every row of the table carries the object it was rendered from.
+	// +optional
+	IncludeObject bool `json:"includeObject,omitempty" form:"includeObject"`
 }
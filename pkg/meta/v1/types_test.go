@@ -0,0 +1,92 @@
+package v1_test
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/gzwillyy/components/errors"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+)
+
+type secret struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Description string `json:"description" gorm:"column:description"`
+}
+
+func newDB(t *testing.T) *gorm.DB {
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := db.AutoMigrate(&secret{}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return db
+}
+
+func TestResourceVersion(t *testing.T) {
+	db := newDB(t)
+
+	obj := &secret{ObjectMeta: metav1.ObjectMeta{InstanceID: "secret-1", Name: "colin"}}
+	if err := db.Create(obj).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if obj.ResourceVersion != 1 {
+		t.Errorf("expected resource version 1 after create, got %d", obj.ResourceVersion)
+	}
+
+	var stale secret
+	if err := db.First(&stale, obj.ID).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	obj.Description = "updated"
+	if err := db.Save(obj).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if obj.ResourceVersion != 2 {
+		t.Errorf("expected resource version 2 after update, got %d", obj.ResourceVersion)
+	}
+
+	if err := db.Model(obj).Updates(map[string]interface{}{"description": "patched"}).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	stale.Description = "lost update"
+	err := db.Save(&stale).Error
+	if !metav1.IsConflict(err) {
+		t.Fatalf("expected conflict error, got %v", err)
+	}
+	if coder := errors.ParseCoder(err); coder.HTTPStatus() != http.StatusConflict {
+		t.Errorf("expected HTTP status 409, got %d", coder.HTTPStatus())
+	}
+
+	var stored secret
+	if err := db.First(&stored, obj.ID).Error; err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if stored.Description != "patched" || stored.ResourceVersion != 3 {
+		t.Errorf("unexpected stored object: %q at version %d", stored.Description, stored.ResourceVersion)
+	}
+}
+
+func TestPreconditions(t *testing.T) {
+	obj := &metav1.ObjectMeta{Name: "colin", ResourceVersion: 3}
+
+	var p *metav1.Preconditions
+	if err := p.Check(obj); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := metav1.NewRVPreconditions(3).Check(obj); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if err := metav1.NewRVPreconditions(2).Check(obj); !metav1.IsConflict(err) {
+		t.Errorf("expected conflict error, got %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package printers
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/gzwillyy/components/pkg/labels"
+)
+
+// Formatter converts the value of a field into the cell of a table column.
+type Formatter func(value interface{}) interface{}
+
+// The formatters registered by default.
+const (
+	// FormatAge prints a time as the short duration elapsed since then, like "5m" or "3d".
+	FormatAge = "age"
+	// FormatLabels prints a label map as a sorted, comma separated list of key=value pairs.
+	FormatLabels = "labels"
+)
+
+var (
+	formattersMu sync.RWMutex
+	formatters   = map[string]Formatter{
+		FormatAge:    formatAge,
+		FormatLabels: formatLabels,
+	}
+)
+
+// RegisterFormatter registers f under name, so fields tagged with `table:",format=name"` are
+// formatted by it. A formatter already registered under name is replaced.
+func RegisterFormatter(name string, f Formatter) {
+	formattersMu.Lock()
+	defer formattersMu.Unlock()
+
+	formatters[name] = f
+}
+
+// formatterFor returns the formatter registered under name.
+func formatterFor(name string) (Formatter, bool) {
+	formattersMu.RLock()
+	defer formattersMu.RUnlock()
+
+	f, ok := formatters[name]
+
+	return f, ok
+}
+
+// formatAge formats a time.Time or *time.Time as the time elapsed since then.
+func formatAge(value interface{}) interface{} {
+	var t time.Time
+	switch v := value.(type) {
+	case time.Time:
+		t = v
+	case *time.Time:
+		if v != nil {
+			t = *v
+		}
+	default:
+		return fmt.Sprint(value)
+	}
+	if t.IsZero() {
+		return "<unknown>"
+	}
+
+	return ShortHumanDuration(time.Since(t))
+}
+
+// formatLabels formats a map[string]string as a label list.
+func formatLabels(value interface{}) interface{} {
+	m, ok := value.(map[string]string)
+	if !ok {
+		return fmt.Sprint(value)
+	}
+	if len(m) == 0 {
+		return "<none>"
+	}
+
+	return labels.Set(m).String()
+}
+
+// ShortHumanDuration returns a succinct representation of d using its largest unit, like "45s",
+// "5m", "3h", "12d" or "2y". Negative durations, caused by clock skew, are printed as "0s".
+func ShortHumanDuration(d time.Duration) string {
+	switch {
+	case d < 0:
+		return "0s"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int64(d/time.Second))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int64(d/time.Minute))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int64(d/time.Hour))
+	case d < 365*24*time.Hour:
+		return fmt.Sprintf("%dd", int64(d/(24*time.Hour)))
+	default:
+		return fmt.Sprintf("%dy", int64(d/(365*24*time.Hour)))
+	}
+}
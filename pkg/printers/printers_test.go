@@ -0,0 +1,224 @@
+package printers_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/printers"
+)
+
+type user struct {
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Nickname string     `json:"nickname" table:""`
+	Email    string     `json:"email" table:",priority=1"`
+	Logins   int        `json:"logins" table:"Logins"`
+	LoginAt  *time.Time `json:"loginAt" table:"Last Login,format=age"`
+	Password string     `json:"password"`
+}
+
+type userList struct {
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []*user `json:"items"`
+}
+
+func TestToTableColumns(t *testing.T) {
+	table, err := printers.ToTable(&user{}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		name     string
+		typ      string
+		priority int32
+	}{
+		{"Name", "string", 0},
+		{"Nickname", "string", 0},
+		{"Email", "string", 1},
+		{"Logins", "integer", 0},
+		{"Last Login", "string", 0},
+		{"Age", "string", 0},
+		{"Labels", "string", 1},
+	}
+	if len(table.ColumnDefinitions) != len(want) {
+		t.Fatalf("got %d columns, want %d: %+v", len(table.ColumnDefinitions), len(want), table.ColumnDefinitions)
+	}
+	for i, w := range want {
+		c := table.ColumnDefinitions[i]
+		if c.Name != w.name || c.Type != w.typ || c.Priority != w.priority {
+			t.Errorf("column %d: got %s/%s/%d, want %s/%s/%d", i, c.Name, c.Type, c.Priority, w.name, w.typ, w.priority)
+		}
+	}
+	if table.Kind != "Table" || table.APIVersion != metav1.SchemeGroupVersion.String() {
+		t.Errorf("unexpected type meta %+v", table.TypeMeta)
+	}
+}
+
+func TestToTableRows(t *testing.T) {
+	loginAt := time.Now().Add(-90 * time.Minute)
+	remaining := int64(3)
+	list := &userList{
+		ListMeta: metav1.ListMeta{TotalCount: 5, Continue: "token", RemainingItemCount: &remaining},
+		Items: []*user{
+			{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      "colin",
+					CreatedAt: time.Now().Add(-50 * time.Hour),
+					Labels:    map[string]string{"team": "infra", "env": "prod"},
+				},
+				Nickname: "Colin",
+				Logins:   7,
+				LoginAt:  &loginAt,
+			},
+			{ObjectMeta: metav1.ObjectMeta{Name: "neo"}},
+		},
+	}
+
+	table, err := printers.ToTable(list, &metav1.TableOptions{IncludeObject: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if table.Continue != "token" || table.TotalCount != 5 {
+		t.Errorf("list metadata not copied: %+v", table.ListMeta)
+	}
+	if len(table.Rows) != 2 {
+		t.Fatalf("got %d rows, want 2", len(table.Rows))
+	}
+
+	got := table.Rows[0].Cells
+	want := []interface{}{"colin", "Colin", "", int64(7), "1h", "2d", "env=prod,team=infra"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("cell %d: got %#v, want %#v", i, got[i], want[i])
+		}
+	}
+	if table.Rows[0].Object != list.Items[0] {
+		t.Errorf("row object not included")
+	}
+
+	got = table.Rows[1].Cells
+	if got[4] != "<unknown>" || got[5] != "<unknown>" || got[6] != "<none>" {
+		t.Errorf("unexpected cells for empty fields: %#v", got)
+	}
+}
+
+func TestToTableSlice(t *testing.T) {
+	table, err := printers.ToTable([]user{{ObjectMeta: metav1.ObjectMeta{Name: "colin"}}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(table.Rows) != 1 || table.Rows[0].Cells[0] != "colin" || table.Rows[0].Object != nil {
+		t.Errorf("unexpected rows %+v", table.Rows)
+	}
+
+	if _, err := printers.ToTable([]string{"colin"}, nil); err == nil {
+		t.Errorf("expected an error for a slice of non objects")
+	}
+}
+
+func TestToTableInvalidTag(t *testing.T) {
+	type invalid struct {
+		metav1.ObjectMeta
+		Field string `table:",format=unknown"`
+	}
+	if _, err := printers.ToTable(&invalid{}, nil); err == nil {
+		t.Errorf("expected an error for an unknown formatter")
+	}
+}
+
+func TestRegisterFormatter(t *testing.T) {
+	printers.RegisterFormatter("upper", func(v interface{}) interface{} { return strings.ToUpper(v.(string)) })
+
+	type shouting struct {
+		metav1.ObjectMeta
+		Field string `table:",format=upper"`
+	}
+	table, err := printers.ToTable(&shouting{Field: "quiet"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cell := table.Rows[0].Cells[1]; cell != "QUIET" {
+		t.Errorf("got %v, want QUIET", cell)
+	}
+}
+
+func TestShortHumanDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		-time.Second:          "0s",
+		45 * time.Second:      "45s",
+		5 * time.Minute:       "5m",
+		3 * time.Hour:         "3h",
+		12 * 24 * time.Hour:   "12d",
+		800 * 24 * time.Hour:  "2y",
+		59*time.Minute + 59e9: "59m",
+	}
+	for d, want := range tests {
+		if got := printers.ShortHumanDuration(d); got != want {
+			t.Errorf("ShortHumanDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
+
+func newTable() *metav1.Table {
+	return &metav1.Table{
+		ColumnDefinitions: []metav1.TableColumnDefinition{
+			{Name: "Name"},
+			{Name: "Description"},
+			{Name: "Labels", Priority: 1},
+		},
+		Rows: []metav1.TableRow{
+			{Cells: []interface{}{"colin", "a user with a rather long description", "team=infra"}},
+			{Cells: []interface{}{"neo", nil, "team=dev"}},
+		},
+	}
+}
+
+func TestPrintTable(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printers.PrintTable(&buf, newTable(), printers.PrintOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"NAME    DESCRIPTION\n" +
+		"colin   a user with a rather long description\n" +
+		"neo     <none>\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+
+	buf.Reset()
+	if err := printers.PrintTable(&buf, newTable(), printers.PrintOptions{NoHeaders: true, Wide: true}); err != nil {
+		t.Fatal(err)
+	}
+	want = "" +
+		"colin   a user with a rather long description   team=infra\n" +
+		"neo     <none>                                  team=dev\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+}
+
+func TestPrintTableWraps(t *testing.T) {
+	var buf bytes.Buffer
+	if err := printers.PrintTable(&buf, newTable(), printers.PrintOptions{Width: 30}); err != nil {
+		t.Fatal(err)
+	}
+	want := "" +
+		"NAME    DESCRIPTION\n" +
+		"colin   a user with a rather\n" +
+		"        long description\n" +
+		"neo     <none>\n"
+	if buf.String() != want {
+		t.Errorf("got\n%s\nwant\n%s", buf.String(), want)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if len(line) > 30 {
+			t.Errorf("line %q is wider than 30", line)
+		}
+	}
+}
@@ -0,0 +1,341 @@
+// Package printers renders API objects embedding meta/v1.ObjectMeta as meta/v1.Table and prints
+// tables to terminals.
+//
+// The columns of a table are derived from the type of the objects. Every table starts with the
+// NAME column and ends with the AGE and, in wide output, the LABELS column. Fields add a column
+// in between when they are tagged with `table`:
+//
+//	type User struct {
+//		metav1.ObjectMeta `json:"metadata,omitempty"`
+//
+//		Nickname string    `json:"nickname" table:""`
+//		Email    string    `json:"email" table:",priority=1"`
+//		LoginAt  time.Time `json:"loginAt" table:"Last Login,format=age"`
+//	}
+//
+// The first element of the tag is the column name, it defaults to the field name. Headers are
+// printed upper cased.
+// The priority option sets TableColumnDefinition.Priority, columns with a priority greater than 0
+// are only printed in wide output. The format option names a Formatter registered with
+// RegisterFormatter that converts the field value into the cell.
+package printers
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gzwillyy/components/errors"
+
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+)
+
+var (
+	objectType     = reflect.TypeOf((*metav1.Object)(nil)).Elem()
+	objectMetaType = reflect.TypeOf(metav1.ObjectMeta{})
+	typeMetaType   = reflect.TypeOf(metav1.TypeMeta{})
+	listMetaType   = reflect.TypeOf(metav1.ListMeta{})
+	timeType       = reflect.TypeOf(time.Time{})
+)
+
+// column is a column of a table and the function computing its cells.
+type column struct {
+	definition metav1.TableColumnDefinition
+	cell       func(obj metav1.Object, v reflect.Value) interface{}
+}
+
+// ConvertToTable converts data into a table like ToTable does, errors have the
+// metav1.ErrNotAcceptable code. It is the core.TableConvertor of API servers returning tables:
+//
+//	core.SetTableConvertor(printers.ConvertToTable)
+func ConvertToTable(data interface{}, includeObject bool) (interface{}, error) {
+	table, err := ToTable(data, &metav1.TableOptions{IncludeObject: includeObject})
+	if err != nil {
+		return nil, errors.WithCode(metav1.ErrNotAcceptable, "%s", err.Error())
+	}
+
+	return table, nil
+}
+
+// ToTable converts obj into a table. obj is an object embedding ObjectMeta, a list with an Items
+// field holding such objects, or a slice of them. The ListMeta of a list is copied to the table.
+func ToTable(obj interface{}, opts *metav1.TableOptions) (*metav1.Table, error) {
+	if opts == nil {
+		opts = &metav1.TableOptions{}
+	}
+
+	items, listMeta, err := itemsOf(obj)
+	if err != nil {
+		return nil, err
+	}
+	columns, err := columnsFor(items.Type().Elem())
+	if err != nil {
+		return nil, err
+	}
+
+	table := &metav1.Table{
+		TypeMeta:          metav1.TypeMeta{APIVersion: metav1.SchemeGroupVersion.String(), Kind: "Table"},
+		ColumnDefinitions: make([]metav1.TableColumnDefinition, 0, len(columns)),
+		Rows:              make([]metav1.TableRow, 0, items.Len()),
+	}
+	if listMeta != nil {
+		table.ListMeta = *listMeta
+	}
+	for _, c := range columns {
+		table.ColumnDefinitions = append(table.ColumnDefinitions, c.definition)
+	}
+
+	for i := 0; i < items.Len(); i++ {
+		item := items.Index(i)
+		if item.Kind() == reflect.Ptr {
+			if item.IsNil() {
+				continue
+			}
+		} else {
+			if !item.CanAddr() {
+				copied := reflect.New(item.Type()).Elem()
+				copied.Set(item)
+				item = copied
+			}
+			item = item.Addr()
+		}
+		object, _ := item.Interface().(metav1.Object)
+
+		row := metav1.TableRow{Cells: make([]interface{}, 0, len(columns))}
+		for _, c := range columns {
+			row.Cells = append(row.Cells, c.cell(object, item.Elem()))
+		}
+		if opts.IncludeObject {
+			row.Object = item.Interface()
+		}
+		table.Rows = append(table.Rows, row)
+	}
+
+	return table, nil
+}
+
+// itemsOf returns the objects of obj as a slice and the list metadata of obj, if any.
+func itemsOf(obj interface{}) (reflect.Value, *metav1.ListMeta, error) {
+	if obj == nil {
+		return reflect.Value{}, nil, fmt.Errorf("unable to convert nil to a table")
+	}
+
+	if _, ok := obj.(metav1.Object); ok {
+		v := reflect.ValueOf(obj)
+		items := reflect.MakeSlice(reflect.SliceOf(v.Type()), 0, 1)
+
+		return reflect.Append(items, v), nil, nil
+	}
+
+	v := reflect.ValueOf(obj)
+	for v.Kind() == reflect.Ptr && !v.IsNil() {
+		v = v.Elem()
+	}
+
+	var listMeta *metav1.ListMeta
+	if v.Kind() == reflect.Struct {
+		if lm := v.FieldByName("ListMeta"); lm.IsValid() && lm.Type() == listMetaType {
+			m, _ := lm.Interface().(metav1.ListMeta)
+			listMeta = &m
+		}
+		v = v.FieldByName("Items")
+	}
+	if !v.IsValid() || v.Kind() != reflect.Slice {
+		return reflect.Value{}, nil, fmt.Errorf("unable to convert %T to a table: not an object, list or slice", obj)
+	}
+
+	elem := v.Type().Elem()
+	if elem.Kind() != reflect.Ptr {
+		elem = reflect.PtrTo(elem)
+	}
+	if !elem.Implements(objectType) || elem.Elem().Kind() != reflect.Struct {
+		return reflect.Value{}, nil, fmt.Errorf("unable to convert %T to a table: %v doesn't implement the metav1.Object interface",
+			obj, v.Type().Elem())
+	}
+
+	return v, listMeta, nil
+}
+
+// columnsFor returns the columns of the tables of objects of type t, a struct or a pointer to a struct.
+func columnsFor(t reflect.Type) ([]column, error) {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	columns := []column{{
+		definition: metav1.TableColumnDefinition{
+			Name:        "Name",
+			Type:        "string",
+			Format:      "name",
+			Description: "Name must be unique within a resource.",
+		},
+		cell: func(obj metav1.Object, _ reflect.Value) interface{} { return obj.GetName() },
+	}}
+
+	tagged, err := taggedColumns(t, nil)
+	if err != nil {
+		return nil, err
+	}
+	columns = append(columns, tagged...)
+
+	age, _ := formatterFor(FormatAge)
+	labels, _ := formatterFor(FormatLabels)
+
+	return append(columns,
+		column{
+			definition: metav1.TableColumnDefinition{
+				Name:        "Age",
+				Type:        "string",
+				Format:      FormatAge,
+				Description: "Time elapsed since the object was created.",
+			},
+			cell: func(obj metav1.Object, _ reflect.Value) interface{} { return age(obj.GetCreatedAt()) },
+		},
+		column{
+			definition: metav1.TableColumnDefinition{
+				Name:        "Labels",
+				Type:        "string",
+				Format:      FormatLabels,
+				Description: "Labels of the object.",
+				Priority:    1,
+			},
+			cell: func(obj metav1.Object, _ reflect.Value) interface{} { return labels(obj.GetLabels()) },
+		},
+	), nil
+}
+
+// taggedColumns returns the columns of the fields of t tagged with `table`, including the fields
+// of embedded structs. index is the index sequence of t in the object.
+func taggedColumns(t reflect.Type, index []int) ([]column, error) {
+	var columns []column
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		fieldIndex := append(append([]int(nil), index...), i)
+
+		if f.Anonymous && f.Type.Kind() == reflect.Struct && f.Type != objectMetaType && f.Type != typeMetaType {
+			embedded, err := taggedColumns(f.Type, fieldIndex)
+			if err != nil {
+				return nil, err
+			}
+			columns = append(columns, embedded...)
+
+			continue
+		}
+
+		tag, ok := f.Tag.Lookup("table")
+		if !ok || tag == "-" || !f.IsExported() {
+			continue
+		}
+		c, err := fieldColumn(f, tag, fieldIndex)
+		if err != nil {
+			return nil, fmt.Errorf("invalid table tag of field %s.%s: %w", t, f.Name, err)
+		}
+		columns = append(columns, c)
+	}
+
+	return columns, nil
+}
+
+// fieldColumn returns the column of field f tagged with tag.
+func fieldColumn(f reflect.StructField, tag string, index []int) (column, error) {
+	name, options, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = f.Name
+	}
+	def := metav1.TableColumnDefinition{Name: name, Type: openAPIType(f.Type)}
+
+	var format Formatter
+	for _, option := range strings.Split(options, ",") {
+		if option == "" {
+			continue
+		}
+		key, value, _ := strings.Cut(option, "=")
+		switch key {
+		case "priority":
+			priority, err := strconv.ParseInt(value, 10, 32)
+			if err != nil || priority < 0 {
+				return column{}, fmt.Errorf("priority %q is not a non-negative integer", value)
+			}
+			def.Priority = int32(priority)
+		case "format":
+			var ok bool
+			if format, ok = formatterFor(value); !ok {
+				return column{}, fmt.Errorf("no formatter registered for %q", value)
+			}
+			def.Type, def.Format = "string", value
+		default:
+			return column{}, fmt.Errorf("unknown option %q", key)
+		}
+	}
+
+	return column{
+		definition: def,
+		cell: func(_ metav1.Object, v reflect.Value) interface{} {
+			field, err := v.FieldByIndexErr(index)
+			if err != nil {
+				// a nil embedded pointer
+				return nil
+			}
+			if format != nil {
+				return format(field.Interface())
+			}
+
+			return cellValue(field)
+		},
+	}, nil
+}
+
+// cellValue returns the cell of a field without formatter.
+func cellValue(v reflect.Value) interface{} {
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool()
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return v.Int()
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		return v.Float()
+	case reflect.String:
+		return v.String()
+	}
+	if v.Type() == timeType {
+		t, _ := v.Interface().(time.Time)
+
+		return t.Format(time.RFC3339)
+	}
+
+	return v.Interface()
+}
+
+// openAPIType returns the OpenAPI type of the cells of a field of type t.
+func openAPIType(t reflect.Type) string {
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map:
+		return "object"
+	default:
+		return "string"
+	}
+}
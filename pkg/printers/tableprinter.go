@@ -0,0 +1,181 @@
+package printers
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf8"
+
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/term"
+)
+
+const (
+	// columnSeparator separates the columns of a printed table.
+	columnSeparator = "   "
+	// minColumnWidth is the width columns are not shrunk below to fit a table into the terminal.
+	minColumnWidth = 8
+)
+
+// PrintOptions controls how a table is printed.
+type PrintOptions struct {
+	// NoHeaders omits the line with the column names.
+	NoHeaders bool
+
+	// Wide prints the columns with a priority greater than 0.
+	Wide bool
+
+	// Width is the maximum width of a line. Cells are wrapped to keep the table within the width.
+	// 0 uses the width of the terminal w is connected to, output to anything else is not wrapped.
+	Width int
+}
+
+// PrintTable prints table to w as aligned columns. Columns wider than the available width are
+// shrunk, widest first, and their cells wrapped over several lines.
+func PrintTable(w io.Writer, table *metav1.Table, opts PrintOptions) error {
+	width := opts.Width
+	if width == 0 {
+		if terminalWidth, _, err := term.TerminalSize(w); err == nil {
+			width = terminalWidth
+		}
+	}
+
+	var columns []int
+	for i, c := range table.ColumnDefinitions {
+		if c.Priority == 0 || opts.Wide {
+			columns = append(columns, i)
+		}
+	}
+
+	var lines [][]string
+	if !opts.NoHeaders {
+		header := make([]string, 0, len(columns))
+		for _, i := range columns {
+			header = append(header, strings.ToUpper(table.ColumnDefinitions[i].Name))
+		}
+		lines = append(lines, header)
+	}
+	for _, row := range table.Rows {
+		if len(row.Cells) != len(table.ColumnDefinitions) {
+			return fmt.Errorf("table row has %d cells but %d columns are defined", len(row.Cells), len(table.ColumnDefinitions))
+		}
+		cells := make([]string, 0, len(columns))
+		for _, i := range columns {
+			cells = append(cells, cellString(row.Cells[i]))
+		}
+		lines = append(lines, cells)
+	}
+
+	widths := columnWidths(lines, len(columns), width)
+	out := bufio.NewWriter(w)
+	for _, cells := range lines {
+		writeRow(out, cells, widths)
+	}
+
+	return out.Flush()
+}
+
+// cellString returns the printed form of a cell.
+func cellString(cell interface{}) string {
+	if cell == nil {
+		return "<none>"
+	}
+	s := fmt.Sprint(cell)
+
+	return strings.Join(strings.Fields(s), " ")
+}
+
+// columnWidths returns the widths of the columns of lines. If maxWidth is positive, the widest
+// columns are shrunk until the lines fit into maxWidth or no column can shrink anymore.
+func columnWidths(lines [][]string, n, maxWidth int) []int {
+	widths := make([]int, n)
+	for _, cells := range lines {
+		for i, cell := range cells {
+			if l := utf8.RuneCountInString(cell); l > widths[i] {
+				widths[i] = l
+			}
+		}
+	}
+	if maxWidth <= 0 || n == 0 {
+		return widths
+	}
+
+	floors := make([]int, n)
+	total := len(columnSeparator) * (n - 1)
+	for i, w := range widths {
+		floors[i] = w
+		if floors[i] > minColumnWidth {
+			floors[i] = minColumnWidth
+		}
+		total += w
+	}
+
+	for total > maxWidth {
+		widest := -1
+		for i := range widths {
+			if widths[i] > floors[i] && (widest < 0 || widths[i] > widths[widest]) {
+				widest = i
+			}
+		}
+		if widest < 0 {
+			break
+		}
+		widths[widest]--
+		total--
+	}
+
+	return widths
+}
+
+// writeRow writes the cells of a row, wrapping each cell to the width of its column.
+func writeRow(w *bufio.Writer, cells []string, widths []int) {
+	wrapped := make([][]string, len(cells))
+	height := 1
+	for i, cell := range cells {
+		wrapped[i] = wrap(cell, widths[i])
+		if len(wrapped[i]) > height {
+			height = len(wrapped[i])
+		}
+	}
+
+	for line := 0; line < height; line++ {
+		var b strings.Builder
+		for i := range cells {
+			if i > 0 {
+				b.WriteString(columnSeparator)
+			}
+			var s string
+			if line < len(wrapped[i]) {
+				s = wrapped[i][line]
+			}
+			b.WriteString(s)
+			if i < len(cells)-1 {
+				b.WriteString(strings.Repeat(" ", widths[i]-utf8.RuneCountInString(s)))
+			}
+		}
+		_, _ = w.WriteString(strings.TrimRight(b.String(), " "))
+		_ = w.WriteByte('\n')
+	}
+}
+
+// wrap splits s into lines of at most width runes, breaking after commas and at spaces where
+// possible.
+func wrap(s string, width int) []string {
+	var lines []string
+	runes := []rune(s)
+	for len(runes) > width {
+		cut := width
+		for i := width; i > 0; i-- {
+			if runes[i-1] == ',' || runes[i] == ' ' {
+				cut = i
+
+				break
+			}
+		}
+		lines = append(lines, strings.TrimRight(string(runes[:cut]), " "))
+		runes = []rune(strings.TrimLeft(string(runes[cut:]), " "))
+	}
+
+	return append(lines, string(runes))
+}
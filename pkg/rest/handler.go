@@ -0,0 +1,205 @@
+package rest
+
+import (
+	"context"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gzwillyy/components/errors"
+	"github.com/gzwillyy/components/pkg/core"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/validation"
+)
+
+// Resource describes a resource served by the generic handlers.
+//
+//	secrets := &rest.Resource{
+//		Name:    "secrets",
+//		New:     func() metav1.Object { return &v1.Secret{} },
+//		NewList: func() interface{} { return &v1.SecretList{} },
+//		Storage: store,
+//	}
+//	secrets.Install(engine.Group("/v1"))
+type Resource struct {
+	// Name is the plural name of the resource, it is the path segment of its routes.
+	Name string
+
+	// New returns an empty object of the resource.
+	New func() metav1.Object
+
+	// NewList returns an empty list of the resource, a pointer to a struct with a ListMeta and an
+	// Items field.
+	NewList func() interface{}
+
+	// Storage stores the objects of the resource.
+	Storage Storage
+
+	// Processor defaults and validates created, updated and patched objects.
+	Processor Processor
+}
+
+// Install registers the routes of the resource on router:
+//
+//	POST   /<name>        creates an object
+//	GET    /<name>        lists objects, the query parameters are bound to metav1.ListOptions
+//	GET    /<name>/:name  gets an object
+//	PUT    /<name>/:name  replaces an object
+//	PATCH  /<name>/:name  patches an object, the Content-Type selects the PatchType
+//	DELETE /<name>/:name  deletes an object
+//
+// All responses are written with core.WriteResponse, errors carry the codes of meta/v1.
+func (r *Resource) Install(router gin.IRouter) {
+	collection := "/" + r.Name
+	item := collection + "/:name"
+
+	router.POST(collection, r.create)
+	router.GET(collection, r.list)
+	router.GET(item, r.get)
+	router.PUT(item, r.update)
+	router.PATCH(item, r.patch)
+	router.DELETE(item, r.delete)
+}
+
+func (r *Resource) create(c *gin.Context) {
+	var opts metav1.CreateOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, metav1.ErrBind, "invalid query parameters"), nil)
+
+		return
+	}
+	obj := r.New()
+	if err := c.ShouldBindJSON(obj); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, metav1.ErrBind, "invalid %s object", r.Name), nil)
+
+		return
+	}
+
+	persist := func(ctx context.Context, obj metav1.Object) error {
+		return r.Storage.Create(ctx, obj, opts)
+	}
+	out, err := r.Processor.Create(c.Request.Context(), obj, opts, persist)
+	core.WriteResponse(c, err, out)
+}
+
+func (r *Resource) list(c *gin.Context) {
+	var opts metav1.ListOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, metav1.ErrBind, "invalid query parameters"), nil)
+
+		return
+	}
+	if errs := validation.NewValidator(&opts).Validate(); len(errs) > 0 {
+		core.WriteResponse(c, newValidationError(errs), nil)
+
+		return
+	}
+
+	list := r.NewList()
+	if err := r.Storage.List(c.Request.Context(), list, opts); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+	core.WriteResponse(c, nil, list)
+}
+
+func (r *Resource) get(c *gin.Context) {
+	obj := r.New()
+	if err := r.Storage.Get(c.Request.Context(), c.Param("name"), obj, metav1.GetOptions{}); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+	core.WriteResponse(c, nil, obj)
+}
+
+func (r *Resource) update(c *gin.Context) {
+	var opts metav1.UpdateOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, metav1.ErrBind, "invalid query parameters"), nil)
+
+		return
+	}
+	obj := r.New()
+	if err := c.ShouldBindJSON(obj); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, metav1.ErrBind, "invalid %s object", r.Name), nil)
+
+		return
+	}
+	if obj.GetName() == "" {
+		obj.SetName(c.Param("name"))
+	}
+
+	current, err := r.current(c)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	persist := func(ctx context.Context, obj metav1.Object) error {
+		return r.Storage.Update(ctx, obj, opts)
+	}
+	out, err := r.Processor.Update(c.Request.Context(), current, obj, opts, persist)
+	core.WriteResponse(c, err, out)
+}
+
+func (r *Resource) patch(c *gin.Context) {
+	var opts metav1.PatchOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, metav1.ErrBind, "invalid query parameters"), nil)
+
+		return
+	}
+	patch, err := c.GetRawData()
+	if err != nil {
+		core.WriteResponse(c, errors.WrapC(err, metav1.ErrBind, "unable to read the patch"), nil)
+
+		return
+	}
+
+	current, err := r.current(c)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+
+	persist := func(ctx context.Context, obj metav1.Object) error {
+		return r.Storage.Update(ctx, obj, metav1.UpdateOptions{DryRun: opts.DryRun})
+	}
+	out, err := r.Processor.Patch(c.Request.Context(), current, PatchType(c.ContentType()), patch, opts, persist)
+	core.WriteResponse(c, err, out)
+}
+
+func (r *Resource) delete(c *gin.Context) {
+	var opts metav1.DeleteOptions
+	if err := c.ShouldBindQuery(&opts); err != nil {
+		core.WriteResponse(c, errors.WrapC(err, metav1.ErrBind, "invalid query parameters"), nil)
+
+		return
+	}
+
+	obj, err := r.current(c)
+	if err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+	if err := r.Storage.Delete(c.Request.Context(), obj, opts); err != nil {
+		core.WriteResponse(c, err, nil)
+
+		return
+	}
+	core.WriteResponse(c, nil, obj)
+}
+
+// current returns the stored object named by the path of the request.
+func (r *Resource) current(c *gin.Context) (metav1.Object, error) {
+	obj := r.New()
+	if err := r.Storage.Get(c.Request.Context(), c.Param("name"), obj, metav1.GetOptions{}); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
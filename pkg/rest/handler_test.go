@@ -0,0 +1,225 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/gzwillyy/components/errors"
+	"github.com/gzwillyy/components/pkg/core"
+	"github.com/gzwillyy/components/pkg/json"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/rest"
+)
+
+type secretList struct {
+	metav1.ListMeta `json:"metadata,omitempty"`
+
+	Items []*secret `json:"items"`
+}
+
+// mapStorage stores secrets in a map, it implements just enough of the semantics of storage for
+// the handlers.
+type mapStorage struct {
+	objects map[string]secret
+	lastID  uint64
+	opts    metav1.ListOptions
+}
+
+func (s *mapStorage) Get(_ context.Context, name string, out metav1.Object, _ metav1.GetOptions) error {
+	obj, ok := s.objects[name]
+	if !ok {
+		return errors.WithCode(metav1.ErrNotFound, "secret %q not found", name)
+	}
+	*out.(*secret) = obj
+
+	return nil
+}
+
+func (s *mapStorage) List(_ context.Context, list interface{}, opts metav1.ListOptions) error {
+	s.opts = opts
+	l := list.(*secretList)
+	for name := range s.objects {
+		obj := s.objects[name]
+		l.Items = append(l.Items, &obj)
+	}
+	sort.Slice(l.Items, func(i, j int) bool { return l.Items[i].ID < l.Items[j].ID })
+	l.TotalCount = int64(len(l.Items))
+
+	return nil
+}
+
+func (s *mapStorage) Create(_ context.Context, obj metav1.Object, _ metav1.CreateOptions) error {
+	if _, ok := s.objects[obj.GetName()]; ok {
+		return errors.WithCode(metav1.ErrConflict, "secret %q already exists", obj.GetName())
+	}
+	if obj.GetID() == 0 {
+		s.lastID++
+		obj.SetID(s.lastID)
+	}
+	obj.SetResourceVersion(1)
+	obj.SetCreatedAt(time.Now())
+	s.objects[obj.GetName()] = *obj.(*secret)
+
+	return nil
+}
+
+func (s *mapStorage) Update(_ context.Context, obj metav1.Object, _ metav1.UpdateOptions) error {
+	if s.objects[obj.GetName()].ResourceVersion != obj.GetResourceVersion() {
+		return metav1.NewConflict(obj.GetName(), obj.GetResourceVersion())
+	}
+	obj.SetResourceVersion(obj.GetResourceVersion() + 1)
+	s.objects[obj.GetName()] = *obj.(*secret)
+
+	return nil
+}
+
+func (s *mapStorage) Delete(_ context.Context, obj metav1.Object, _ metav1.DeleteOptions) error {
+	delete(s.objects, obj.GetName())
+
+	return nil
+}
+
+func newServer() (*gin.Engine, *mapStorage) {
+	gin.SetMode(gin.TestMode)
+	store := &mapStorage{objects: map[string]secret{}}
+	resource := &rest.Resource{
+		Name:      "secrets",
+		New:       func() metav1.Object { return &secret{} },
+		NewList:   func() interface{} { return &secretList{} },
+		Storage:   store,
+		Processor: *newProcessor(),
+	}
+	engine := gin.New()
+	resource.Install(engine.Group("/v1"))
+
+	return engine, store
+}
+
+func do(engine *gin.Engine, method, path, contentType, body string, out interface{}) (int, core.ErrResponse) {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, req)
+
+	var resp core.ErrResponse
+	if w.Code != http.StatusOK {
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+	} else if out != nil {
+		_ = json.Unmarshal(w.Body.Bytes(), out)
+	}
+
+	return w.Code, resp
+}
+
+func TestResourceHandlers(t *testing.T) {
+	engine, store := newServer()
+
+	var created secret
+	code, _ := do(engine, http.MethodPost, "/v1/secrets", "application/json", `{"metadata":{"name":"colin"},"description":"key"}`, &created)
+	if code != http.StatusOK || created.ID != 1 || created.ResourceVersion != 1 || created.Expires != 3600 {
+		t.Fatalf("unexpected create response %d: %+v", code, created)
+	}
+
+	code, _ = do(engine, http.MethodPost, "/v1/secrets?dryRun=All", "application/json", `{"metadata":{"name":"dry"}}`, nil)
+	if _, ok := store.objects["dry"]; code != http.StatusOK || ok {
+		t.Errorf("expected dry run create not to be stored, got %d", code)
+	}
+
+	var got secret
+	if code, _ := do(engine, http.MethodGet, "/v1/secrets/colin", "", "", &got); code != http.StatusOK || got.Description != "key" {
+		t.Errorf("unexpected get response %d: %+v", code, got)
+	}
+
+	var updated secret
+	code, _ = do(engine, http.MethodPut, "/v1/secrets/colin", "application/json", `{"description":"rotated","expires":60}`, &updated)
+	if code != http.StatusOK || updated.Description != "rotated" || updated.Expires != 60 || updated.ResourceVersion != 2 {
+		t.Errorf("unexpected update response %d: %+v", code, updated)
+	}
+
+	var patched secret
+	code, _ = do(engine, http.MethodPatch, "/v1/secrets/colin", string(rest.MergePatchType), `{"expires":120}`, &patched)
+	if code != http.StatusOK || patched.Description != "rotated" || patched.Expires != 120 || patched.ResourceVersion != 3 {
+		t.Errorf("unexpected patch response %d: %+v", code, patched)
+	}
+
+	var list secretList
+	code, _ = do(engine, http.MethodGet, "/v1/secrets?offset=0&limit=10&labelSelector=app%3Diam&fieldSelector=name%3Dcolin", "", "", &list)
+	if code != http.StatusOK || len(list.Items) != 1 || list.TotalCount != 1 {
+		t.Errorf("unexpected list response %d: %+v", code, list)
+	}
+	if store.opts.LabelSelector != "app=iam" || store.opts.FieldSelector != "name=colin" || *store.opts.Limit != 10 || *store.opts.Offset != 0 {
+		t.Errorf("list options not bound: %+v", store.opts)
+	}
+
+	if code, _ := do(engine, http.MethodDelete, "/v1/secrets/colin", "", "", nil); code != http.StatusOK {
+		t.Errorf("unexpected delete response %d", code)
+	}
+	if _, ok := store.objects["colin"]; ok {
+		t.Errorf("expected colin to be deleted")
+	}
+}
+
+func TestCreateIgnoresSystemFields(t *testing.T) {
+	engine, store := newServer()
+
+	body := `{"metadata":{"id":42,"instanceID":"secret-42","name":"colin","resourceVersion":7,` +
+		`"createdAt":"2020-01-01T00:00:00Z","deletionTimestamp":"2020-01-01T00:00:00Z","finalizers":["x"]}}`
+	var created secret
+	code, _ := do(engine, http.MethodPost, "/v1/secrets", "application/json", body, &created)
+	if code != http.StatusOK {
+		t.Fatalf("unexpected create response %d", code)
+	}
+	stored := store.objects["colin"]
+	if stored.ID != 1 || stored.InstanceID != "" || stored.ResourceVersion != 1 || stored.CreatedAt.Year() == 2020 ||
+		stored.DeletionTimestamp != nil || len(stored.Finalizers) != 0 {
+		t.Errorf("expected the system fields to be ignored, got %+v", stored.ObjectMeta)
+	}
+
+	var dryRun secret
+	code, _ = do(engine, http.MethodPost, "/v1/secrets?dryRun=All", "application/json", strings.Replace(body, "colin", "dry", 1), &dryRun)
+	if code != http.StatusOK || dryRun.ID != 0 || dryRun.DeletionTimestamp != nil || dryRun.ResourceVersion != 1 {
+		t.Errorf("expected the system fields to be ignored by dry runs, got %d %+v", code, dryRun.ObjectMeta)
+	}
+}
+
+func TestResourceHandlerErrors(t *testing.T) {
+	engine, _ := newServer()
+	do(engine, http.MethodPost, "/v1/secrets", "application/json", `{"metadata":{"name":"colin"}}`, nil)
+
+	tests := []struct {
+		name        string
+		method      string
+		path        string
+		contentType string
+		body        string
+		status      int
+		code        int
+	}{
+		{"get missing", http.MethodGet, "/v1/secrets/lisa", "", "", http.StatusNotFound, metav1.ErrNotFound},
+		{"delete missing", http.MethodDelete, "/v1/secrets/lisa", "", "", http.StatusNotFound, metav1.ErrNotFound},
+		{"invalid body", http.MethodPost, "/v1/secrets", "application/json", `{"metadata":`, http.StatusBadRequest, metav1.ErrBind},
+		{"invalid name", http.MethodPost, "/v1/secrets", "application/json", `{"metadata":{"name":"-x"}}`, http.StatusBadRequest, metav1.ErrValidation},
+		{"duplicate", http.MethodPost, "/v1/secrets", "application/json", `{"metadata":{"name":"colin"}}`, http.StatusConflict, metav1.ErrConflict},
+		{"invalid limit", http.MethodGet, "/v1/secrets?limit=abc", "", "", http.StatusBadRequest, metav1.ErrBind},
+		{"negative limit", http.MethodGet, "/v1/secrets?limit=-1", "", "", http.StatusBadRequest, metav1.ErrValidation},
+		{"invalid order", http.MethodGet, "/v1/secrets?order=random", "", "", http.StatusBadRequest, metav1.ErrValidation},
+		{"rename", http.MethodPut, "/v1/secrets/colin", "application/json", `{"metadata":{"name":"lisa"}}`, http.StatusBadRequest, metav1.ErrValidation},
+		{"stale update", http.MethodPut, "/v1/secrets/colin", "application/json", `{"metadata":{"resourceVersion":7}}`, http.StatusConflict, metav1.ErrConflict},
+		{"unsupported patch", http.MethodPatch, "/v1/secrets/colin", "application/json", `{}`, http.StatusUnsupportedMediaType, metav1.ErrUnsupportedMediaType},
+	}
+	for _, tt := range tests {
+		status, resp := do(engine, tt.method, tt.path, tt.contentType, tt.body, nil)
+		if status != tt.status || resp.Code != tt.code {
+			t.Errorf("%s: got %d/%d, want %d/%d", tt.name, status, resp.Code, tt.status, tt.code)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package rest
+
+import (
+	"context"
+	"reflect"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+
+	"github.com/gzwillyy/components/errors"
+	"github.com/gzwillyy/components/pkg/json"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+)
+
+// PatchType is the type of a patch document, it is sent as the Content-Type of patch requests.
+type PatchType string
+
+// The supported patch types.
+const (
+	// JSONPatchType is a JSON patch as defined by RFC 6902.
+	JSONPatchType PatchType = "application/json-patch+json"
+	// MergePatchType is a JSON merge patch as defined by RFC 7386.
+	MergePatchType PatchType = "application/merge-patch+json"
+)
+
+// ApplyPatch applies patch to the JSON form of obj and returns the result as a new object of the
+// same type. obj is not modified. A failed "test" operation of a JSON patch returns an ErrConflict
+// error, a malformed patch an ErrValidation error.
+func ApplyPatch(obj metav1.Object, patchType PatchType, patch []byte) (metav1.Object, error) {
+	original, err := json.Marshal(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	var patched []byte
+	switch patchType {
+	case JSONPatchType:
+		var p jsonpatch.Patch
+		if p, err = jsonpatch.DecodePatch(patch); err != nil {
+			return nil, errors.WrapC(err, metav1.ErrValidation, "invalid JSON patch")
+		}
+		if patched, err = p.Apply(original); err != nil {
+			if errors.Is(err, jsonpatch.ErrTestFailed) {
+				return nil, errors.WrapC(err, metav1.ErrConflict, "JSON patch precondition failed")
+			}
+
+			return nil, errors.WrapC(err, metav1.ErrValidation, "unable to apply JSON patch")
+		}
+	case MergePatchType:
+		if patched, err = jsonpatch.MergePatch(original, patch); err != nil {
+			return nil, errors.WrapC(err, metav1.ErrValidation, "unable to apply JSON merge patch")
+		}
+	default:
+		return nil, errors.WithCode(metav1.ErrUnsupportedMediaType, "unsupported patch type %q", patchType)
+	}
+
+	out, _ := reflect.New(reflect.TypeOf(obj).Elem()).Interface().(metav1.Object)
+	if err := json.Unmarshal(patched, out); err != nil {
+		return nil, errors.WrapC(err, metav1.ErrValidation, "patched object is invalid")
+	}
+
+	return out, nil
+}
+
+// Patch applies patch to current and updates it with the result like Update does. A patch that
+// sets a resource version different from the one of current conflicts with a concurrent
+// modification; with opts.Force the patch is applied to current regardless.
+func (p *Processor) Patch(
+	ctx context.Context,
+	current metav1.Object,
+	patchType PatchType,
+	patch []byte,
+	opts metav1.PatchOptions,
+	persist PersistFunc,
+) (metav1.Object, error) {
+	obj, err := ApplyPatch(current, patchType, patch)
+	if err != nil {
+		return nil, err
+	}
+
+	if rv := obj.GetResourceVersion(); rv != 0 && rv != current.GetResourceVersion() {
+		if !opts.Force {
+			return nil, metav1.NewConflict(current.GetName(), rv)
+		}
+		obj.SetResourceVersion(current.GetResourceVersion())
+	}
+
+	return p.Update(ctx, current, obj, metav1.UpdateOptions{DryRun: opts.DryRun}, persist)
+}
@@ -0,0 +1,154 @@
+// Package rest implements the generic processing of write requests for API objects embedding
+// meta/v1.ObjectMeta: dry run, defaulting, validation and patching, and the gin handlers serving
+// a resource from a Storage.
+package rest
+
+import (
+	"context"
+	"time"
+
+	"github.com/gzwillyy/components/errors"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/scheme"
+	"github.com/gzwillyy/components/pkg/validation"
+	"github.com/gzwillyy/components/pkg/validation/field"
+)
+
+// DryRunAll is the only supported dry run directive, all stages of a request are processed but
+// nothing is persisted.
+const DryRunAll = "All"
+
+// ValidateDryRun validates the dryRun directives of create, update and patch options.
+func ValidateDryRun(fldPath *field.Path, dryRun []string) field.ErrorList {
+	allErrs := field.ErrorList{}
+	for i, directive := range dryRun {
+		if directive != DryRunAll {
+			allErrs = append(allErrs, field.NotSupported(fldPath.Index(i), directive, []string{DryRunAll}))
+		}
+	}
+
+	return allErrs
+}
+
+// IsDryRun returns true if the dryRun directives request a dry run.
+func IsDryRun(dryRun []string) bool {
+	return len(dryRun) > 0
+}
+
+// ValidateFunc validates an object in addition to the struct tag validation of pkg/validation.
+type ValidateFunc func(ctx context.Context, obj metav1.Object) field.ErrorList
+
+// PersistFunc stores obj. It is not called for dry run requests.
+type PersistFunc func(ctx context.Context, obj metav1.Object) error
+
+// Processor runs the stages shared by all write requests: defaulting, validation and, unless the
+// request is a dry run, persisting the object. The zero value validates struct tags only.
+type Processor struct {
+	// Scheme, if set, applies the defaulting functions registered for the type of the object.
+	Scheme *scheme.Scheme
+
+	// Validate, if set, is called after the struct tag validation succeeded.
+	Validate ValidateFunc
+}
+
+// Create defaults and validates obj and persists it. The fields populated by the system are cleared
+// first, a client can't choose the ID, resource version or timestamps of a new object or create it
+// terminating. For dry run requests the returned object is the object that would have been
+// created, with its creation timestamps and initial resource version set as storage would.
+func (p *Processor) Create(ctx context.Context, obj metav1.Object, opts metav1.CreateOptions, persist PersistFunc) (metav1.Object, error) {
+	if errs := ValidateDryRun(field.NewPath("dryRun"), opts.DryRun); len(errs) > 0 {
+		return nil, newValidationError(errs)
+	}
+
+	obj.SetID(0)
+	obj.SetInstanceID("")
+	obj.SetResourceVersion(0)
+	obj.SetCreatedAt(time.Time{})
+	obj.SetUpdatedAt(time.Time{})
+	obj.SetDeletionTimestamp(nil)
+	obj.SetFinalizers(nil)
+	if err := p.prepare(ctx, obj); err != nil {
+		return nil, err
+	}
+
+	if IsDryRun(opts.DryRun) {
+		now := time.Now()
+		obj.SetCreatedAt(now)
+		obj.SetUpdatedAt(now)
+		obj.SetResourceVersion(1)
+
+		return obj, nil
+	}
+	if err := persist(ctx, obj); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// Update replaces current with obj. The fields populated by the system are kept from current and
+// the name can't be changed. An update is conditional on the resource version of obj, an obj
+// without resource version updates the version of current. The preconditions of opts are checked
+// against current.
+func (p *Processor) Update(ctx context.Context, current, obj metav1.Object, opts metav1.UpdateOptions, persist PersistFunc) (metav1.Object, error) {
+	if errs := ValidateDryRun(field.NewPath("dryRun"), opts.DryRun); len(errs) > 0 {
+		return nil, newValidationError(errs)
+	}
+	if err := opts.Preconditions.Check(current); err != nil {
+		return nil, err
+	}
+	if obj.GetName() != current.GetName() {
+		return nil, newValidationError(field.ErrorList{
+			field.Invalid(field.NewPath("metadata", "name"), obj.GetName(), "field is immutable"),
+		})
+	}
+	if rv := obj.GetResourceVersion(); rv != 0 && rv != current.GetResourceVersion() {
+		return nil, metav1.NewConflict(obj.GetName(), rv)
+	}
+
+	obj.SetID(current.GetID())
+	obj.SetInstanceID(current.GetInstanceID())
+	obj.SetCreatedAt(current.GetCreatedAt())
+	obj.SetDeletionTimestamp(current.GetDeletionTimestamp())
+	obj.SetResourceVersion(current.GetResourceVersion())
+	if err := p.prepare(ctx, obj); err != nil {
+		return nil, err
+	}
+
+	if IsDryRun(opts.DryRun) {
+		obj.SetUpdatedAt(time.Now())
+		obj.SetResourceVersion(current.GetResourceVersion() + 1)
+
+		return obj, nil
+	}
+	if err := persist(ctx, obj); err != nil {
+		return nil, err
+	}
+
+	return obj, nil
+}
+
+// prepare runs defaulting and validation.
+func (p *Processor) prepare(ctx context.Context, obj metav1.Object) error {
+	if p.Scheme != nil {
+		if o, ok := obj.(scheme.Object); ok {
+			p.Scheme.Default(o)
+		}
+	}
+
+	if errs := validation.NewValidator(obj).Validate(); len(errs) > 0 {
+		return newValidationError(errs)
+	}
+	if p.Validate != nil {
+		if errs := p.Validate(ctx, obj); len(errs) > 0 {
+			return newValidationError(errs)
+		}
+	}
+
+	return nil
+}
+
+// newValidationError returns an ErrValidation error listing errs.
+func newValidationError(errs field.ErrorList) error {
+	return errors.WithCode(metav1.ErrValidation, "%s", errs.ToAggregate().Error())
+}
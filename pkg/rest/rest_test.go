@@ -0,0 +1,226 @@
+package rest_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/gzwillyy/components/errors"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/rest"
+	"github.com/gzwillyy/components/pkg/scheme"
+	"github.com/gzwillyy/components/pkg/validation/field"
+)
+
+type secret struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Description string `json:"description" validate:"description"`
+	Expires     int64  `json:"expires"`
+}
+
+func newProcessor() *rest.Processor {
+	s := scheme.NewScheme()
+	s.AddKnownTypes(scheme.GroupVersion{Group: "iam.api", Version: "v1"}, &secret{})
+	s.AddTypeDefaultingFunc(&secret{}, func(obj interface{}) {
+		if o := obj.(*secret); o.Expires == 0 {
+			o.Expires = 3600
+		}
+	})
+
+	return &rest.Processor{
+		Scheme: s,
+		Validate: func(_ context.Context, obj metav1.Object) field.ErrorList {
+			if obj.(*secret).Expires < 0 {
+				return field.ErrorList{field.Invalid(field.NewPath("expires"), obj.(*secret).Expires, "must not be negative")}
+			}
+
+			return nil
+		},
+	}
+}
+
+func TestCreate(t *testing.T) {
+	p := newProcessor()
+	persisted := 0
+	persist := func(_ context.Context, obj metav1.Object) error {
+		persisted++
+		obj.SetID(1)
+
+		return nil
+	}
+
+	obj, err := p.Create(context.TODO(), &secret{ObjectMeta: metav1.ObjectMeta{Name: "colin"}},
+		metav1.CreateOptions{DryRun: []string{rest.DryRunAll}}, persist)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if persisted != 0 {
+		t.Errorf("expected dry run not to persist")
+	}
+	if s := obj.(*secret); s.Expires != 3600 || s.ResourceVersion != 1 || s.CreatedAt.IsZero() {
+		t.Errorf("unexpected dry run result: %+v", s)
+	}
+
+	if _, err := p.Create(context.TODO(), &secret{ObjectMeta: metav1.ObjectMeta{Name: "colin"}}, metav1.CreateOptions{}, persist); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if persisted != 1 {
+		t.Errorf("expected object to be persisted once, got %d", persisted)
+	}
+
+	tests := map[string]struct {
+		obj  *secret
+		opts metav1.CreateOptions
+	}{
+		"unknown dry run": {obj: &secret{ObjectMeta: metav1.ObjectMeta{Name: "colin"}}, opts: metav1.CreateOptions{DryRun: []string{"Some"}}},
+		"invalid name":    {obj: &secret{ObjectMeta: metav1.ObjectMeta{Name: "-colin"}}},
+		"custom":          {obj: &secret{ObjectMeta: metav1.ObjectMeta{Name: "colin"}, Expires: -1}},
+	}
+	for name, tt := range tests {
+		_, err := p.Create(context.TODO(), tt.obj, tt.opts, persist)
+		if !errors.IsCode(err, metav1.ErrValidation) || errors.ParseCoder(err).HTTPStatus() != http.StatusBadRequest {
+			t.Errorf("%s: expected ErrValidation, got %v", name, err)
+		}
+	}
+	if persisted != 1 {
+		t.Errorf("expected invalid objects not to be persisted")
+	}
+}
+
+func TestUpdate(t *testing.T) {
+	p := newProcessor()
+	created := time.Now().Add(-time.Hour)
+	current := &secret{
+		ObjectMeta:  metav1.ObjectMeta{ID: 7, InstanceID: "secret-7", Name: "colin", ResourceVersion: 3, CreatedAt: created},
+		Description: "old",
+		Expires:     60,
+	}
+	var persisted metav1.Object
+	persist := func(_ context.Context, obj metav1.Object) error {
+		persisted = obj
+
+		return nil
+	}
+
+	obj, err := p.Update(context.TODO(), current, &secret{ObjectMeta: metav1.ObjectMeta{Name: "colin"}, Description: "new"},
+		metav1.UpdateOptions{}, persist)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	s := persisted.(*secret)
+	if obj != persisted || s.ID != 7 || s.InstanceID != "secret-7" || !s.CreatedAt.Equal(created) || s.ResourceVersion != 3 || s.Expires != 3600 {
+		t.Errorf("unexpected updated object: %+v", s)
+	}
+
+	obj, err = p.Update(context.TODO(), current, &secret{ObjectMeta: metav1.ObjectMeta{Name: "colin", ResourceVersion: 3}},
+		metav1.UpdateOptions{DryRun: []string{rest.DryRunAll}}, func(context.Context, metav1.Object) error {
+			t.Errorf("unexpected persist call for a dry run")
+
+			return nil
+		})
+	if err != nil || obj.GetResourceVersion() != 4 {
+		t.Errorf("unexpected dry run result: %+v, %v", obj, err)
+	}
+
+	if _, err := p.Update(context.TODO(), current, &secret{ObjectMeta: metav1.ObjectMeta{Name: "colin", ResourceVersion: 2}},
+		metav1.UpdateOptions{}, persist); !metav1.IsConflict(err) {
+		t.Errorf("expected conflict for a stale resource version, got %v", err)
+	}
+	if _, err := p.Update(context.TODO(), current, &secret{ObjectMeta: metav1.ObjectMeta{Name: "colin"}},
+		metav1.UpdateOptions{Preconditions: metav1.NewRVPreconditions(2)}, persist); !metav1.IsConflict(err) {
+		t.Errorf("expected conflict for a failed precondition, got %v", err)
+	}
+	if _, err := p.Update(context.TODO(), current, &secret{ObjectMeta: metav1.ObjectMeta{Name: "lisa"}},
+		metav1.UpdateOptions{}, persist); !errors.IsCode(err, metav1.ErrValidation) {
+		t.Errorf("expected ErrValidation renaming the object, got %v", err)
+	}
+}
+
+func TestPatch(t *testing.T) {
+	p := newProcessor()
+	current := &secret{
+		ObjectMeta:  metav1.ObjectMeta{ID: 7, Name: "colin", ResourceVersion: 3, Labels: map[string]string{"app": "iam"}},
+		Description: "old",
+		Expires:     60,
+	}
+	persist := func(context.Context, metav1.Object) error { return nil }
+
+	tests := []struct {
+		name      string
+		patchType rest.PatchType
+		patch     string
+		force     bool
+		expected  func(*secret) bool
+		code      int
+	}{
+		{
+			name:      "merge patch",
+			patchType: rest.MergePatchType,
+			patch:     `{"description":"new","metadata":{"labels":{"app":null,"tier":"1"}}}`,
+			expected: func(s *secret) bool {
+				return s.Description == "new" && s.Expires == 60 && len(s.Labels) == 1 && s.Labels["tier"] == "1"
+			},
+		},
+		{
+			name:      "json patch",
+			patchType: rest.JSONPatchType,
+			patch:     `[{"op":"test","path":"/description","value":"old"},{"op":"replace","path":"/expires","value":120}]`,
+			expected:  func(s *secret) bool { return s.Description == "old" && s.Expires == 120 },
+		},
+		{
+			name:      "failed test operation",
+			patchType: rest.JSONPatchType,
+			patch:     `[{"op":"test","path":"/description","value":"other"}]`,
+			code:      metav1.ErrConflict,
+		},
+		{
+			name:      "stale resource version",
+			patchType: rest.MergePatchType,
+			patch:     `{"description":"new","metadata":{"resourceVersion":2}}`,
+			code:      metav1.ErrConflict,
+		},
+		{
+			name:      "forced stale resource version",
+			patchType: rest.MergePatchType,
+			patch:     `{"description":"new","metadata":{"resourceVersion":2}}`,
+			force:     true,
+			expected:  func(s *secret) bool { return s.Description == "new" && s.ResourceVersion == 3 },
+		},
+		{
+			name:      "malformed patch",
+			patchType: rest.JSONPatchType,
+			patch:     `{"op":"add"}`,
+			code:      metav1.ErrValidation,
+		},
+		{
+			name:      "unsupported patch type",
+			patchType: "application/strategic-merge-patch+json",
+			patch:     `{}`,
+			code:      metav1.ErrUnsupportedMediaType,
+		},
+	}
+	for _, tt := range tests {
+		obj, err := p.Patch(context.TODO(), current, tt.patchType, []byte(tt.patch), metav1.PatchOptions{Force: tt.force}, persist)
+		if tt.code != 0 {
+			if !errors.IsCode(err, tt.code) {
+				t.Errorf("%s: expected error code %d, got %v", tt.name, tt.code, err)
+			}
+
+			continue
+		}
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.name, err)
+
+			continue
+		}
+		if !tt.expected(obj.(*secret)) {
+			t.Errorf("%s: unexpected patched object: %+v", tt.name, obj)
+		}
+	}
+	if current.Description != "old" || current.Labels["app"] != "iam" {
+		t.Errorf("expected the current object not to be modified: %+v", current)
+	}
+}
@@ -0,0 +1,6 @@
+package rest
+
+import "github.com/gzwillyy/components/pkg/storage"
+
+// Storage stores the objects served by the generic handlers, see storage.Interface.
+type Storage = storage.Interface
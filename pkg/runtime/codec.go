@@ -0,0 +1,95 @@
+package runtime
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/gzwillyy/components/pkg/scheme"
+)
+
+// typeMeta is used to read the type information of a serialized object before its Go type is known.
+type typeMeta struct {
+	APIVersion string `json:"apiVersion,omitempty"`
+	Kind       string `json:"kind,omitempty"`
+}
+
+// codec decodes objects of any served version, defaults them and converts them into the version
+// of the target object. Objects are encoded in encodeVersion.
+type codec struct {
+	scheme        *scheme.Scheme
+	serializer    Serializer
+	encodeVersion scheme.GroupVersioner
+}
+
+var _ Serializer = &codec{}
+
+// NewCodec returns a Serializer that converts objects between versions through the scheme.
+// Decode reads the apiVersion and kind of the payload, decodes it into the registered type,
+// applies the defaulting functions of that type and converts the result into the object passed
+// in, which is usually of the internal version. Encode converts the object into encodeVersion
+// before it is serialized.
+func NewCodec(s *scheme.Scheme, serializer Serializer, encodeVersion scheme.GroupVersioner) Serializer {
+	return &codec{
+		scheme:        s,
+		serializer:    serializer,
+		encodeVersion: encodeVersion,
+	}
+}
+
+// Decode implements the Decoder interface. v must be a pointer to an object registered in the scheme.
+func (c *codec) Decode(data []byte, v interface{}) error {
+	into, ok := v.(scheme.Object)
+	if !ok {
+		return fmt.Errorf("%v is not a registered object", reflect.TypeOf(v))
+	}
+
+	var tm typeMeta
+	if err := c.serializer.Decode(data, &tm); err != nil {
+		return err
+	}
+	gvk := scheme.FromAPIVersionAndKind(tm.APIVersion, tm.Kind)
+	if gvk.Empty() {
+		// the payload carries no type information, decode it as the kind of the target object
+		var err error
+		if gvk, err = c.scheme.ObjectKind(into); err != nil {
+			return err
+		}
+	}
+	if len(gvk.Kind) == 0 {
+		return fmt.Errorf("object %q has no kind set in the payload", tm.APIVersion)
+	}
+	if len(gvk.Version) == 0 {
+		return fmt.Errorf("object of kind %q has no apiVersion set in the payload", gvk.Kind)
+	}
+
+	obj, err := c.scheme.New(gvk)
+	if err != nil {
+		return err
+	}
+	if err := c.serializer.Decode(data, obj); err != nil {
+		return err
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+	c.scheme.Default(obj)
+
+	if err := c.scheme.Convert(obj, into); err != nil {
+		return err
+	}
+
+	return c.scheme.SetObjectKind(into)
+}
+
+// Encode implements the Encoder interface. v must be a pointer to an object registered in the scheme.
+func (c *codec) Encode(v interface{}) ([]byte, error) {
+	obj, ok := v.(scheme.Object)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a registered object", reflect.TypeOf(v))
+	}
+
+	out, err := c.scheme.ConvertToVersion(obj, c.encodeVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	return c.serializer.Encode(out)
+}
@@ -0,0 +1,114 @@
+package runtime
+
+import (
+	"strings"
+	"testing"
+
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/scheme"
+)
+
+type internalWidget struct {
+	metav1.TypeMeta
+	Size  int
+	Color string
+}
+
+type widgetV1 struct {
+	metav1.TypeMeta `json:",inline"`
+	Size            int `json:"size"`
+}
+
+type widgetV2 struct {
+	metav1.TypeMeta `json:",inline"`
+	Size            int    `json:"size"`
+	Color           string `json:"color"`
+}
+
+var (
+	widgetV1GV = scheme.GroupVersion{Group: "test", Version: "v1"}
+	widgetV2GV = scheme.GroupVersion{Group: "test", Version: "v2"}
+)
+
+func newWidgetScheme(t *testing.T) *scheme.Scheme {
+	s := scheme.NewScheme()
+	s.AddKnownTypeWithName(scheme.GroupVersion{Group: "test", Version: scheme.APIVersionInternal}.WithKind("Widget"),
+		&internalWidget{})
+	s.AddKnownTypeWithName(widgetV1GV.WithKind("Widget"), &widgetV1{})
+	s.AddKnownTypeWithName(widgetV2GV.WithKind("Widget"), &widgetV2{})
+
+	must := func(err error) {
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	must(s.AddConversionFunc(&widgetV1{}, &internalWidget{}, func(a, b interface{}) error {
+		b.(*internalWidget).Size = a.(*widgetV1).Size
+		return nil
+	}))
+	must(s.AddConversionFunc(&internalWidget{}, &widgetV1{}, func(a, b interface{}) error {
+		b.(*widgetV1).Size = a.(*internalWidget).Size
+		return nil
+	}))
+	must(s.AddConversionFunc(&widgetV2{}, &internalWidget{}, func(a, b interface{}) error {
+		in, out := a.(*widgetV2), b.(*internalWidget)
+		out.Size, out.Color = in.Size, in.Color
+		return nil
+	}))
+	must(s.AddConversionFunc(&internalWidget{}, &widgetV2{}, func(a, b interface{}) error {
+		in, out := a.(*internalWidget), b.(*widgetV2)
+		out.Size, out.Color = in.Size, in.Color
+		return nil
+	}))
+	s.AddTypeDefaultingFunc(&widgetV1{}, func(obj interface{}) {
+		if w := obj.(*widgetV1); w.Size == 0 {
+			w.Size = 1
+		}
+	})
+
+	return s
+}
+
+func TestCodecRoundTrip(t *testing.T) {
+	s := newWidgetScheme(t)
+	serializer := &apimachineryClientNegotiatorSerializer{}
+
+	// decode a v1 body into the internal version, applying v1 defaults
+	internal := &internalWidget{}
+	if err := NewCodec(s, serializer, widgetV2GV).Decode([]byte(`{"apiVersion":"test/v1","kind":"Widget"}`), internal); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if internal.Size != 1 || internal.Kind != "" {
+		t.Errorf("unexpected internal object: %+v", internal)
+	}
+
+	// re-encode into the version requested by the client
+	internal.Color = "red"
+	data, err := NewCodec(s, serializer, widgetV2GV).Encode(internal)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, expected := range []string{`"apiVersion":"test/v2"`, `"kind":"Widget"`, `"size":1`, `"color":"red"`} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("expected %s in %s", expected, data)
+		}
+	}
+}
+
+func TestCodecDecodeWithoutTypeMeta(t *testing.T) {
+	s := newWidgetScheme(t)
+
+	out := &widgetV2{}
+	if err := NewCodec(s, &apimachineryClientNegotiatorSerializer{}, widgetV2GV).Decode([]byte(`{"size":3}`), out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Size != 3 || out.APIVersion != "test/v2" {
+		t.Errorf("unexpected object: %+v", out)
+	}
+
+	err := NewCodec(s, &apimachineryClientNegotiatorSerializer{}, widgetV2GV).
+		Decode([]byte(`{"apiVersion":"test/v3","kind":"Widget"}`), out)
+	if !scheme.IsNotRegisteredError(err) {
+		t.Errorf("expected not registered error, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package runtime
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"strings"
+)
+
+// MaxFrameSize is the largest frame a FrameReader accepts, it protects readers from
+// allocating unbounded memory for a corrupt or hostile stream.
+const MaxFrameSize = 16 * 1024 * 1024
+
+// ErrFrameTooLarge is returned by a FrameReader when a frame exceeds MaxFrameSize.
+var ErrFrameTooLarge = errors.New("frame exceeds the maximum frame size")
+
+// FrameWriter writes one serialized object per frame.
+type FrameWriter interface {
+	WriteFrame(frame []byte) error
+}
+
+// FrameReader reads the frames written by a FrameWriter. ReadFrame returns io.EOF when the
+// stream ends at a frame boundary.
+type FrameReader interface {
+	ReadFrame() ([]byte, error)
+}
+
+// Framer splits a byte stream into frames, each carrying one serialized object.
+type Framer interface {
+	NewFrameWriter(w io.Writer) FrameWriter
+	NewFrameReader(r io.Reader) FrameReader
+}
+
+var (
+	// NewlineDelimitedFramer frames objects as newline-delimited JSON (NDJSON). Frames must not
+	// contain newlines, which holds for compact JSON.
+	NewlineDelimitedFramer Framer = newlineDelimitedFramer{}
+	// LengthDelimitedFramer prefixes every frame with its length as a 4-byte big-endian integer,
+	// it is used for binary formats.
+	LengthDelimitedFramer Framer = lengthDelimitedFramer{}
+)
+
+type newlineDelimitedFramer struct{}
+
+func (newlineDelimitedFramer) NewFrameWriter(w io.Writer) FrameWriter {
+	return &newlineFrameWriter{w: w}
+}
+
+func (newlineDelimitedFramer) NewFrameReader(r io.Reader) FrameReader {
+	return &newlineFrameReader{r: bufio.NewReader(r)}
+}
+
+type newlineFrameWriter struct {
+	w io.Writer
+}
+
+func (fw *newlineFrameWriter) WriteFrame(frame []byte) error {
+	frame = bytes.TrimRight(frame, "\r\n")
+	if bytes.IndexByte(frame, '\n') >= 0 {
+		return errors.New("newline-delimited frame must not contain a newline")
+	}
+	if _, err := fw.w.Write(append(frame[:len(frame):len(frame)], '\n')); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type newlineFrameReader struct {
+	r *bufio.Reader
+}
+
+func (fr *newlineFrameReader) ReadFrame() ([]byte, error) {
+	for {
+		line, err := fr.readLine()
+		if len(line) > 0 {
+			// the last frame of a stream may lack the trailing newline
+			if err != nil && !errors.Is(err, io.EOF) {
+				return nil, err
+			}
+
+			return line, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		// skip empty lines, they are sometimes used as keep-alives
+	}
+}
+
+func (fr *newlineFrameReader) readLine() ([]byte, error) {
+	var line []byte
+	for {
+		chunk, err := fr.r.ReadSlice('\n')
+		if len(line)+len(chunk) > MaxFrameSize {
+			return nil, ErrFrameTooLarge
+		}
+		line = append(line, chunk...)
+		if errors.Is(err, bufio.ErrBufferFull) {
+			continue
+		}
+
+		return bytes.TrimRight(line, "\r\n"), err
+	}
+}
+
+type lengthDelimitedFramer struct{}
+
+func (lengthDelimitedFramer) NewFrameWriter(w io.Writer) FrameWriter {
+	return &lengthFrameWriter{w: w}
+}
+
+func (lengthDelimitedFramer) NewFrameReader(r io.Reader) FrameReader {
+	return &lengthFrameReader{r: r}
+}
+
+type lengthFrameWriter struct {
+	w io.Writer
+}
+
+func (fw *lengthFrameWriter) WriteFrame(frame []byte) error {
+	if len(frame) > MaxFrameSize {
+		return ErrFrameTooLarge
+	}
+	header := make([]byte, 4, 4+len(frame))
+	binary.BigEndian.PutUint32(header, uint32(len(frame)))
+	if _, err := fw.w.Write(append(header, frame...)); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+type lengthFrameReader struct {
+	r io.Reader
+}
+
+func (fr *lengthFrameReader) ReadFrame() ([]byte, error) {
+	var header [4]byte
+	if _, err := io.ReadFull(fr.r, header[:]); err != nil {
+		if errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, fmt.Errorf("reading frame header: %w", err)
+		}
+
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header[:])
+	if size > MaxFrameSize {
+		return nil, ErrFrameTooLarge
+	}
+	frame := make([]byte, size)
+	if _, err := io.ReadFull(fr.r, frame); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = io.ErrUnexpectedEOF
+		}
+
+		return nil, fmt.Errorf("reading frame: %w", err)
+	}
+
+	return frame, nil
+}
+
+// framedStreamSerializer serializes every object of a stream into its own frame.
+type framedStreamSerializer struct {
+	serializer Serializer
+	framer     Framer
+}
+
+// NewFramedStreamSerializer returns a StreamSerializer that encodes every object with serializer
+// and writes it as one frame of framer.
+func NewFramedStreamSerializer(serializer Serializer, framer Framer) StreamSerializer {
+	return &framedStreamSerializer{serializer: serializer, framer: framer}
+}
+
+func (s *framedStreamSerializer) NewEncoder(w io.Writer) StreamEncoder {
+	return &framedEncoder{serializer: s.serializer, w: s.framer.NewFrameWriter(w)}
+}
+
+func (s *framedStreamSerializer) NewDecoder(r io.Reader) StreamDecoder {
+	return &framedDecoder{serializer: s.serializer, r: s.framer.NewFrameReader(r)}
+}
+
+type framedEncoder struct {
+	serializer Serializer
+	w          FrameWriter
+}
+
+func (e *framedEncoder) Encode(v interface{}) error {
+	data, err := e.serializer.Encode(v)
+	if err != nil {
+		return err
+	}
+
+	return e.w.WriteFrame(data)
+}
+
+type framedDecoder struct {
+	serializer Serializer
+	r          FrameReader
+}
+
+func (d *framedDecoder) Decode(v interface{}) error {
+	frame, err := d.r.ReadFrame()
+	if err != nil {
+		return err
+	}
+
+	return d.serializer.Decode(frame, v)
+}
+
+// FramerFor returns the framer used for streams of objects encoded by the serializer of info.
+// JSON media types use newline-delimited frames, every other format is length-delimited.
+func FramerFor(info SerializerInfo) Framer {
+	subType := info.MediaTypeSubType
+	if len(subType) == 0 {
+		if mediaType, _, err := mime.ParseMediaType(info.MediaType); err == nil {
+			_, subType, _ = strings.Cut(mediaType, "/")
+		}
+	}
+	if subType == "json" || strings.HasSuffix(subType, "+json") {
+		return NewlineDelimitedFramer
+	}
+
+	return LengthDelimitedFramer
+}
@@ -0,0 +1,96 @@
+package runtime
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestNewlineDelimitedFramer(t *testing.T) {
+	var buf bytes.Buffer
+	w := NewlineDelimitedFramer.NewFrameWriter(&buf)
+	for _, frame := range []string{`{"a":1}`, `{"b":2}` + "\n"} {
+		if err := w.WriteFrame([]byte(frame)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if err := w.WriteFrame([]byte("{\n}")); err == nil {
+		t.Errorf("expected error writing a frame with an embedded newline")
+	}
+	if expected := "{\"a\":1}\n{\"b\":2}\n"; buf.String() != expected {
+		t.Errorf("expected %q, got %q", expected, buf.String())
+	}
+
+	// empty lines are skipped and the last frame may lack its newline
+	r := NewlineDelimitedFramer.NewFrameReader(strings.NewReader("{\"a\":1}\r\n\n{\"b\":2}"))
+	var frames []string
+	for {
+		frame, err := r.ReadFrame()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		frames = append(frames, string(frame))
+	}
+	if len(frames) != 2 || frames[0] != `{"a":1}` || frames[1] != `{"b":2}` {
+		t.Errorf("unexpected frames: %q", frames)
+	}
+}
+
+func TestLengthDelimitedFramer(t *testing.T) {
+	var buf bytes.Buffer
+	w := LengthDelimitedFramer.NewFrameWriter(&buf)
+	for _, frame := range []string{"first\nframe", ""} {
+		if err := w.WriteFrame([]byte(frame)); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	if buf.Len() != 4+11+4 {
+		t.Errorf("unexpected stream length %d", buf.Len())
+	}
+
+	r := LengthDelimitedFramer.NewFrameReader(bytes.NewReader(buf.Bytes()))
+	if frame, err := r.ReadFrame(); err != nil || string(frame) != "first\nframe" {
+		t.Errorf("unexpected frame %q, %v", frame, err)
+	}
+	if frame, err := r.ReadFrame(); err != nil || len(frame) != 0 {
+		t.Errorf("unexpected frame %q, %v", frame, err)
+	}
+	if _, err := r.ReadFrame(); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF, got %v", err)
+	}
+
+	// truncated frame
+	r = LengthDelimitedFramer.NewFrameReader(bytes.NewReader(buf.Bytes()[:8]))
+	if _, err := r.ReadFrame(); !errors.Is(err, io.ErrUnexpectedEOF) {
+		t.Errorf("expected io.ErrUnexpectedEOF, got %v", err)
+	}
+
+	// oversized frame
+	header := make([]byte, 4)
+	binary.BigEndian.PutUint32(header, MaxFrameSize+1)
+	r = LengthDelimitedFramer.NewFrameReader(bytes.NewReader(header))
+	if _, err := r.ReadFrame(); !errors.Is(err, ErrFrameTooLarge) {
+		t.Errorf("expected ErrFrameTooLarge, got %v", err)
+	}
+}
+
+func TestFramerFor(t *testing.T) {
+	for _, info := range NewNegotiatedSerializer().SupportedMediaTypes() {
+		expected := LengthDelimitedFramer
+		if info.MediaType == ContentTypeJSON {
+			expected = NewlineDelimitedFramer
+		}
+		if FramerFor(info) != expected {
+			t.Errorf("%s: unexpected framer %T", info.MediaType, FramerFor(info))
+		}
+	}
+	if FramerFor(SerializerInfo{MediaType: "application/merge-patch+json"}) != NewlineDelimitedFramer {
+		t.Errorf("expected newline-delimited framer for +json media types")
+	}
+}
@@ -1,6 +1,8 @@
 // Package runtime defines some functions used to encode/decode object.
 package runtime
 
+import "io"
+
 // Encoder writes objects to a serialized form.
 type Encoder interface {
 	// Encode writes an object to a stream. Implementations may return errors if the versions are
@@ -13,10 +15,59 @@ type Decoder interface {
 	Decode(data []byte, v interface{}) error
 }
 
+// Serializer is the core interface for transforming objects into a serialized format and back.
+type Serializer interface {
+	Encoder
+	Decoder
+}
+
+// StreamEncoder writes a sequence of objects to an underlying stream.
+type StreamEncoder interface {
+	Encode(v interface{}) error
+}
+
+// StreamDecoder reads a sequence of objects from an underlying stream. Decode returns io.EOF
+// when the stream ends.
+type StreamDecoder interface {
+	Decode(v interface{}) error
+}
+
+// StreamSerializer creates encoders and decoders for a sequence of objects.
+type StreamSerializer interface {
+	NewEncoder(w io.Writer) StreamEncoder
+	NewDecoder(r io.Reader) StreamDecoder
+}
+
+// SerializerInfo contains information about a specific serialization format.
+type SerializerInfo struct {
+	// MediaType is the value that represents this serializer over the wire.
+	MediaType string
+	// MediaTypeType is the first part of the MediaType ("application" in "application/json").
+	MediaTypeType string
+	// MediaTypeSubType is the second part of the MediaType ("json" in "application/json").
+	MediaTypeSubType string
+	// EncodesAsText indicates this serializer can be encoded to UTF-8 safely.
+	EncodesAsText bool
+	// Serializer is the individual object serializer for this media type.
+	Serializer Serializer
+	// StreamSerializer, if set, describes the streaming serialization format
+	// for this media type.
+	StreamSerializer StreamSerializer
+}
+
+// NegotiatedSerializer is an interface used for obtaining encoders, decoders, and serializers
+// for multiple supported media types.
+type NegotiatedSerializer interface {
+	// SupportedMediaTypes is the media types supported for reading and writing single objects.
+	// The first entry is used when the client expresses no preference.
+	SupportedMediaTypes() []SerializerInfo
+}
+
 // ClientNegotiator handles turning an HTTP content type into the appropriate encoder.
-// Use NewClientNegotiator or NewVersionedClientNegotiator to create this interface from
-// a NegotiatedSerializer.
+// Use NewClientNegotiator to create this interface from a NegotiatedSerializer.
 type ClientNegotiator interface {
 	Encoder() (Encoder, error)
 	Decoder() (Decoder, error)
+	StreamEncoder(w io.Writer) (StreamEncoder, error)
+	StreamDecoder(r io.Reader) (StreamDecoder, error)
 }
@@ -2,6 +2,10 @@ package runtime
 
 import (
 	"fmt"
+	"io"
+	"mime"
+	"strconv"
+	"strings"
 
 	"github.com/gzwillyy/components/pkg/json"
 )
@@ -20,6 +24,9 @@ func (e NegotiateError) Error() string {
 	return fmt.Sprintf("no serializers registered for %s", e.ContentType)
 }
 
+// jsonStreamSerializer writes streams of JSON objects as newline-delimited JSON.
+var jsonStreamSerializer = NewFramedStreamSerializer(jsonSerializer{}, NewlineDelimitedFramer)
+
 type apimachineryClientNegotiator struct{}
 
 var _ ClientNegotiator = &apimachineryClientNegotiator{}
@@ -32,6 +39,14 @@ func (n *apimachineryClientNegotiator) Decoder() (Decoder, error) {
 	return &apimachineryClientNegotiatorSerializer{}, nil
 }
 
+func (n *apimachineryClientNegotiator) StreamEncoder(w io.Writer) (StreamEncoder, error) {
+	return jsonStreamSerializer.NewEncoder(w), nil
+}
+
+func (n *apimachineryClientNegotiator) StreamDecoder(r io.Reader) (StreamDecoder, error) {
+	return jsonStreamSerializer.NewDecoder(r), nil
+}
+
 type apimachineryClientNegotiatorSerializer struct{}
 
 var _ Decoder = &apimachineryClientNegotiatorSerializer{}
@@ -49,3 +64,279 @@ func (s *apimachineryClientNegotiatorSerializer) Encode(v interface{}) ([]byte,
 func NewSimpleClientNegotiator() ClientNegotiator {
 	return &apimachineryClientNegotiator{}
 }
+
+// SerializerRegistry is a NegotiatedSerializer holding serializers registered by media type.
+type SerializerRegistry struct {
+	infos []SerializerInfo
+}
+
+var _ NegotiatedSerializer = &SerializerRegistry{}
+
+// NewSerializerRegistry returns an empty SerializerRegistry.
+func NewSerializerRegistry() *SerializerRegistry {
+	return &SerializerRegistry{}
+}
+
+// NewNegotiatedSerializer returns a SerializerRegistry with the JSON, YAML, protobuf and msgpack
+// serializers registered, in that order of preference. JSON is used when the client expresses no
+// preference.
+func NewNegotiatedSerializer() *SerializerRegistry {
+	r := NewSerializerRegistry()
+	msgpack := newMsgpackSerializer()
+	for _, info := range []SerializerInfo{
+		{MediaType: ContentTypeJSON, EncodesAsText: true, Serializer: jsonSerializer{}, StreamSerializer: jsonStreamSerializer},
+		{MediaType: ContentTypeYAML, EncodesAsText: true, Serializer: yamlSerializer{}, StreamSerializer: yamlSerializer{}},
+		{
+			MediaType:        ContentTypeProtobuf,
+			Serializer:       protobufSerializer{},
+			StreamSerializer: NewFramedStreamSerializer(protobufSerializer{}, LengthDelimitedFramer),
+		},
+		{MediaType: ContentTypeMsgpack, Serializer: msgpack, StreamSerializer: msgpack},
+	} {
+		_ = r.Register(info)
+	}
+
+	return r
+}
+
+// Register adds a serializer for info.MediaType. A serializer already registered for the same
+// media type is replaced and keeps its position.
+func (r *SerializerRegistry) Register(info SerializerInfo) error {
+	mediaType, _, err := mime.ParseMediaType(info.MediaType)
+	if err != nil {
+		return fmt.Errorf("invalid media type %q: %w", info.MediaType, err)
+	}
+	typ, subType, ok := strings.Cut(mediaType, "/")
+	if !ok || typ == "*" || subType == "*" {
+		return fmt.Errorf("invalid media type %q: a concrete type and subtype are required", info.MediaType)
+	}
+	if info.Serializer == nil {
+		return fmt.Errorf("no serializer provided for media type %q", info.MediaType)
+	}
+	info.MediaType, info.MediaTypeType, info.MediaTypeSubType = mediaType, typ, subType
+
+	for i := range r.infos {
+		if r.infos[i].MediaType == mediaType {
+			r.infos[i] = info
+
+			return nil
+		}
+	}
+	r.infos = append(r.infos, info)
+
+	return nil
+}
+
+// SupportedMediaTypes implements the NegotiatedSerializer interface.
+func (r *SerializerRegistry) SupportedMediaTypes() []SerializerInfo {
+	return append([]SerializerInfo(nil), r.infos...)
+}
+
+// NegotiateOutputMediaType returns the serializer that best matches the Accept header of a
+// request, honoring q-values and wildcards. An empty header selects the preferred serializer of ns.
+// A NegotiateError is returned when nothing matches.
+func NegotiateOutputMediaType(accept string, ns NegotiatedSerializer) (SerializerInfo, error) {
+	return negotiateOutput(accept, ns, false)
+}
+
+// NegotiateOutputMediaTypeStream is like NegotiateOutputMediaType, but only considers serializers
+// that support streaming.
+func NegotiateOutputMediaTypeStream(accept string, ns NegotiatedSerializer) (SerializerInfo, error) {
+	return negotiateOutput(accept, ns, true)
+}
+
+// NegotiateInputSerializer returns the serializer for the Content-Type header of a request.
+// An empty header selects the preferred serializer of ns. A NegotiateError is returned when
+// no serializer is registered for the content type.
+func NegotiateInputSerializer(contentType string, ns NegotiatedSerializer) (SerializerInfo, error) {
+	return negotiateInput(contentType, ns, false)
+}
+
+// NegotiateInputSerializerStream is like NegotiateInputSerializer, but only considers serializers
+// that support streaming.
+func NegotiateInputSerializerStream(contentType string, ns NegotiatedSerializer) (SerializerInfo, error) {
+	return negotiateInput(contentType, ns, true)
+}
+
+func negotiateOutput(accept string, ns NegotiatedSerializer, stream bool) (SerializerInfo, error) {
+	infos := supportedMediaTypes(ns, stream)
+	if strings.TrimSpace(accept) == "" {
+		if len(infos) > 0 {
+			return infos[0], nil
+		}
+
+		return SerializerInfo{}, NegotiateError{ContentType: accept, Stream: stream}
+	}
+
+	accepted := parseAccept(accept)
+	best, bestQ, bestSpecificity := -1, 0.0, -1
+	for i, info := range infos {
+		q, specificity := accepted.quality(info)
+		if q > bestQ || (q == bestQ && q > 0 && specificity > bestSpecificity) {
+			best, bestQ, bestSpecificity = i, q, specificity
+		}
+	}
+	if best >= 0 {
+		return infos[best], nil
+	}
+
+	return SerializerInfo{}, NegotiateError{ContentType: accept, Stream: stream}
+}
+
+func negotiateInput(contentType string, ns NegotiatedSerializer, stream bool) (SerializerInfo, error) {
+	infos := supportedMediaTypes(ns, stream)
+	if strings.TrimSpace(contentType) == "" {
+		if len(infos) > 0 {
+			return infos[0], nil
+		}
+
+		return SerializerInfo{}, NegotiateError{ContentType: contentType, Stream: stream}
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err == nil {
+		for _, info := range infos {
+			if info.MediaType == mediaType {
+				return info, nil
+			}
+		}
+	}
+
+	return SerializerInfo{}, NegotiateError{ContentType: contentType, Stream: stream}
+}
+
+func supportedMediaTypes(ns NegotiatedSerializer, stream bool) []SerializerInfo {
+	infos := ns.SupportedMediaTypes()
+	if !stream {
+		return infos
+	}
+
+	streams := make([]SerializerInfo, 0, len(infos))
+	for _, info := range infos {
+		if info.StreamSerializer != nil {
+			streams = append(streams, info)
+		}
+	}
+
+	return streams
+}
+
+// acceptedMediaType is a single media range of an Accept header.
+type acceptedMediaType struct {
+	typ     string
+	subType string
+	q       float64
+}
+
+func (a acceptedMediaType) matches(info SerializerInfo) bool {
+	if a.typ == "*" {
+		return true
+	}
+
+	return a.typ == info.MediaTypeType && (a.subType == "*" || a.subType == info.MediaTypeSubType)
+}
+
+// specificity orders media ranges: "type/subtype" before "type/*" before "*/*".
+func (a acceptedMediaType) specificity() int {
+	switch {
+	case a.typ == "*":
+		return 0
+	case a.subType == "*":
+		return 1
+	default:
+		return 2
+	}
+}
+
+// acceptedMediaTypes is the list of media ranges of an Accept header.
+type acceptedMediaTypes []acceptedMediaType
+
+// quality returns the q-value the Accept header assigns to info, taken from the most specific
+// matching media range, together with the specificity of that range. A q-value of 0 means info
+// is not acceptable.
+func (a acceptedMediaTypes) quality(info SerializerInfo) (float64, int) {
+	q, specificity := 0.0, -1
+	for _, accepted := range a {
+		if accepted.matches(info) && accepted.specificity() > specificity {
+			q, specificity = accepted.q, accepted.specificity()
+		}
+	}
+
+	return q, specificity
+}
+
+// parseAccept parses an Accept header into media ranges. Ranges that cannot be parsed are dropped.
+func parseAccept(header string) acceptedMediaTypes {
+	var accepted acceptedMediaTypes
+	for _, part := range strings.Split(header, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(part))
+		if err != nil {
+			continue
+		}
+		typ, subType, ok := strings.Cut(mediaType, "/")
+		if !ok || (typ == "*" && subType != "*") {
+			continue
+		}
+
+		q := 1.0
+		if v, ok := params["q"]; ok {
+			if q, err = strconv.ParseFloat(v, 64); err != nil || q < 0 || q > 1 {
+				continue
+			}
+		}
+		accepted = append(accepted, acceptedMediaType{typ: typ, subType: subType, q: q})
+	}
+
+	return accepted
+}
+
+// clientNegotiator uses a fixed content type to pick the serializer of a NegotiatedSerializer.
+type clientNegotiator struct {
+	serializer  NegotiatedSerializer
+	contentType string
+}
+
+var _ ClientNegotiator = &clientNegotiator{}
+
+// NewClientNegotiator returns a ClientNegotiator that encodes and decodes contentType with the
+// matching serializer of serializer. The methods return a NegotiateError when no serializer, or
+// no stream serializer, is registered for contentType.
+func NewClientNegotiator(serializer NegotiatedSerializer, contentType string) ClientNegotiator {
+	return &clientNegotiator{serializer: serializer, contentType: contentType}
+}
+
+func (n *clientNegotiator) Encoder() (Encoder, error) {
+	info, err := NegotiateInputSerializer(n.contentType, n.serializer)
+	if err != nil {
+		return nil, err
+	}
+
+	return info.Serializer, nil
+}
+
+func (n *clientNegotiator) Decoder() (Decoder, error) {
+	info, err := NegotiateInputSerializer(n.contentType, n.serializer)
+	if err != nil {
+		return nil, err
+	}
+
+	return info.Serializer, nil
+}
+
+func (n *clientNegotiator) StreamEncoder(w io.Writer) (StreamEncoder, error) {
+	info, err := NegotiateInputSerializerStream(n.contentType, n.serializer)
+	if err != nil {
+		return nil, err
+	}
+
+	return info.StreamSerializer.NewEncoder(w), nil
+}
+
+func (n *clientNegotiator) StreamDecoder(r io.Reader) (StreamDecoder, error) {
+	info, err := NegotiateInputSerializerStream(n.contentType, n.serializer)
+	if err != nil {
+		return nil, err
+	}
+
+	return info.StreamSerializer.NewDecoder(r), nil
+}
@@ -0,0 +1,220 @@
+package runtime
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+type testObject struct {
+	Name  string   `json:"name"`
+	Count int      `json:"count,omitempty"`
+	Tags  []string `json:"tags,omitempty"`
+}
+
+func TestNegotiateOutputMediaType(t *testing.T) {
+	ns := NewNegotiatedSerializer()
+
+	tests := []struct {
+		accept   string
+		expected string
+	}{
+		{accept: "", expected: ContentTypeJSON},
+		{accept: "*/*", expected: ContentTypeJSON},
+		{accept: "application/yaml", expected: ContentTypeYAML},
+		{accept: "application/yaml;q=0.5, application/x-msgpack", expected: ContentTypeMsgpack},
+		{accept: "text/html, application/*;q=0.8, application/x-protobuf;q=0.8", expected: ContentTypeProtobuf},
+		{accept: "application/json;q=0, */*;q=0.1", expected: ContentTypeYAML},
+		{accept: "application/json; charset=utf-8", expected: ContentTypeJSON},
+	}
+	for _, tt := range tests {
+		info, err := NegotiateOutputMediaType(tt.accept, ns)
+		if err != nil {
+			t.Errorf("%q: unexpected error: %v", tt.accept, err)
+
+			continue
+		}
+		if info.MediaType != tt.expected {
+			t.Errorf("%q: expected %s, got %s", tt.accept, tt.expected, info.MediaType)
+		}
+	}
+
+	_, err := NegotiateOutputMediaType("text/html, application/json;q=0", ns)
+	var negotiateErr NegotiateError
+	if !errors.As(err, &negotiateErr) || negotiateErr.Stream {
+		t.Errorf("expected NegotiateError, got %v", err)
+	}
+}
+
+func TestNegotiateStream(t *testing.T) {
+	ns := NewNegotiatedSerializer()
+
+	info, err := NegotiateOutputMediaTypeStream("application/x-protobuf;q=0.5, application/x-msgpack", ns)
+	if err != nil || info.MediaType != ContentTypeMsgpack {
+		t.Errorf("expected msgpack stream serializer, got %v, %v", info.MediaType, err)
+	}
+
+	if info, err := NegotiateInputSerializerStream(ContentTypeProtobuf, ns); err != nil || info.StreamSerializer == nil {
+		t.Errorf("expected protobuf stream serializer, got %v, %v", info.MediaType, err)
+	}
+
+	// a registry without a stream variant for protobuf
+	r := NewSerializerRegistry()
+	_ = r.Register(SerializerInfo{MediaType: ContentTypeProtobuf, Serializer: protobufSerializer{}})
+	_, err = NegotiateInputSerializerStream(ContentTypeProtobuf, r)
+	var negotiateErr NegotiateError
+	if !errors.As(err, &negotiateErr) || !negotiateErr.Stream {
+		t.Errorf("expected stream NegotiateError, got %v", err)
+	}
+	if err.Error() != "no stream serializers registered for application/x-protobuf" {
+		t.Errorf("unexpected error message: %v", err)
+	}
+}
+
+func TestNegotiateInputSerializer(t *testing.T) {
+	ns := NewNegotiatedSerializer()
+
+	info, err := NegotiateInputSerializer("application/yaml; charset=utf-8", ns)
+	if err != nil || info.MediaType != ContentTypeYAML {
+		t.Errorf("expected yaml serializer, got %v, %v", info.MediaType, err)
+	}
+	if info, err := NegotiateInputSerializer("", ns); err != nil || info.MediaType != ContentTypeJSON {
+		t.Errorf("expected default json serializer, got %v, %v", info.MediaType, err)
+	}
+	if _, err := NegotiateInputSerializer("application/*", ns); err == nil {
+		t.Errorf("expected error for wildcard content type")
+	}
+}
+
+func TestSerializerRegistryRegister(t *testing.T) {
+	r := NewSerializerRegistry()
+	if err := r.Register(SerializerInfo{MediaType: "application/*", Serializer: jsonSerializer{}}); err == nil {
+		t.Errorf("expected error registering a wildcard media type")
+	}
+	if err := r.Register(SerializerInfo{MediaType: "application/json"}); err == nil {
+		t.Errorf("expected error registering without a serializer")
+	}
+	if err := r.Register(SerializerInfo{MediaType: "application/vnd.api+json", Serializer: jsonSerializer{}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	info, err := NegotiateOutputMediaType("application/vnd.api+json", r)
+	if err != nil || info.MediaTypeType != "application" || info.MediaTypeSubType != "vnd.api+json" {
+		t.Errorf("unexpected serializer info: %+v, %v", info, err)
+	}
+}
+
+func TestSerializersRoundTrip(t *testing.T) {
+	in := testObject{Name: "colin", Count: 3, Tags: []string{"a", "b"}}
+
+	for _, info := range NewNegotiatedSerializer().SupportedMediaTypes() {
+		if info.MediaType == ContentTypeProtobuf {
+			continue
+		}
+		data, err := info.Serializer.Encode(in)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", info.MediaType, err)
+
+			continue
+		}
+		var out testObject
+		if err := info.Serializer.Decode(data, &out); err != nil {
+			t.Errorf("%s: unexpected error: %v", info.MediaType, err)
+
+			continue
+		}
+		if out.Name != in.Name || out.Count != in.Count || len(out.Tags) != 2 {
+			t.Errorf("%s: expected %+v, got %+v", info.MediaType, in, out)
+		}
+	}
+}
+
+func TestYAMLSerializerUsesJSONTags(t *testing.T) {
+	data, err := yamlSerializer{}.Encode(testObject{Name: "colin", Count: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if expected := "name: colin\ncount: 1\n"; string(data) != expected {
+		t.Errorf("expected %q, got %q", expected, data)
+	}
+
+	// strings that look like other types must survive the round trip
+	data, err = yamlSerializer{}.Encode(testObject{Name: "true", Tags: []string{"1"}})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	var out testObject
+	if err := (yamlSerializer{}).Decode(data, &out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.Name != "true" || len(out.Tags) != 1 || out.Tags[0] != "1" {
+		t.Errorf("unexpected object decoded from %q: %+v", data, out)
+	}
+}
+
+func TestProtobufSerializer(t *testing.T) {
+	s := protobufSerializer{}
+
+	data, err := s.Encode(wrapperspb.String("colin"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	out := &wrapperspb.StringValue{}
+	if err := s.Decode(data, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !proto.Equal(out, wrapperspb.String("colin")) {
+		t.Errorf("unexpected message: %v", out)
+	}
+
+	if _, err := s.Encode(testObject{}); !errors.Is(err, errNotProtoMessage) {
+		t.Errorf("expected errNotProtoMessage, got %v", err)
+	}
+}
+
+func TestClientNegotiatorStream(t *testing.T) {
+	for _, contentType := range []string{ContentTypeJSON, ContentTypeYAML, ContentTypeMsgpack} {
+		n := NewClientNegotiator(NewNegotiatedSerializer(), contentType)
+
+		var buf bytes.Buffer
+		enc, err := n.StreamEncoder(&buf)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", contentType, err)
+		}
+		for _, name := range []string{"a", "b"} {
+			if err := enc.Encode(testObject{Name: name}); err != nil {
+				t.Fatalf("%s: unexpected error: %v", contentType, err)
+			}
+		}
+
+		dec, err := n.StreamDecoder(&buf)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", contentType, err)
+		}
+		var names []string
+		for {
+			var out testObject
+			if err := dec.Decode(&out); err != nil {
+				if !errors.Is(err, io.EOF) {
+					t.Errorf("%s: unexpected error: %v", contentType, err)
+				}
+
+				break
+			}
+			names = append(names, out.Name)
+		}
+		if len(names) != 2 || names[0] != "a" || names[1] != "b" {
+			t.Errorf("%s: unexpected objects: %v", contentType, names)
+		}
+	}
+
+	_, err := NewClientNegotiator(NewNegotiatedSerializer(), "text/plain").Encoder()
+	var negotiateErr NegotiateError
+	if !errors.As(err, &negotiateErr) || negotiateErr.ContentType != "text/plain" {
+		t.Errorf("expected NegotiateError, got %v", err)
+	}
+}
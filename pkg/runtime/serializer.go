@@ -0,0 +1,244 @@
+package runtime
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	ugorji "github.com/ugorji/go/codec"
+	"google.golang.org/protobuf/proto"
+	"gopkg.in/yaml.v3"
+
+	"github.com/gzwillyy/components/pkg/json"
+)
+
+// Media types of the serializers registered by NewNegotiatedSerializer.
+const (
+	ContentTypeJSON     = "application/json"
+	ContentTypeYAML     = "application/yaml"
+	ContentTypeProtobuf = "application/x-protobuf"
+	ContentTypeMsgpack  = "application/x-msgpack"
+)
+
+// jsonSerializer encodes objects as JSON.
+type jsonSerializer struct{}
+
+var _ Serializer = jsonSerializer{}
+
+func (jsonSerializer) Encode(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonSerializer) Decode(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+// yamlSerializer encodes objects as YAML. Objects are converted through JSON first, so the json
+// struct tags of API types are honored.
+type yamlSerializer struct{}
+
+var (
+	_ Serializer       = yamlSerializer{}
+	_ StreamSerializer = yamlSerializer{}
+)
+
+func (yamlSerializer) Encode(v interface{}) ([]byte, error) {
+	node, err := jsonToYAMLNode(v)
+	if err != nil {
+		return nil, err
+	}
+
+	return yaml.Marshal(node)
+}
+
+func (yamlSerializer) Decode(data []byte, v interface{}) error {
+	var obj interface{}
+	if err := yaml.Unmarshal(data, &obj); err != nil {
+		return err
+	}
+
+	return yamlToJSONInto(obj, v)
+}
+
+// NewEncoder returns an encoder that writes YAML documents separated by "---".
+func (yamlSerializer) NewEncoder(w io.Writer) StreamEncoder {
+	return &yamlStreamEncoder{enc: yaml.NewEncoder(w)}
+}
+
+// NewDecoder returns a decoder that reads a sequence of YAML documents.
+func (yamlSerializer) NewDecoder(r io.Reader) StreamDecoder {
+	return &yamlStreamDecoder{dec: yaml.NewDecoder(r)}
+}
+
+type yamlStreamEncoder struct {
+	enc *yaml.Encoder
+}
+
+func (e *yamlStreamEncoder) Encode(v interface{}) error {
+	node, err := jsonToYAMLNode(v)
+	if err != nil {
+		return err
+	}
+
+	return e.enc.Encode(node)
+}
+
+type yamlStreamDecoder struct {
+	dec *yaml.Decoder
+}
+
+func (d *yamlStreamDecoder) Decode(v interface{}) error {
+	var obj interface{}
+	if err := d.dec.Decode(&obj); err != nil {
+		return err
+	}
+
+	return yamlToJSONInto(obj, v)
+}
+
+// jsonToYAMLNode marshals v to JSON and parses the result as a YAML node, which keeps the
+// field order of the JSON document.
+func jsonToYAMLNode(v interface{}) (*yaml.Node, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return nil, err
+	}
+	// emit block style and plain scalars instead of the styles inherited from the JSON document,
+	// strings that would be read back as another type are still quoted by the encoder
+	clearStyle(&node)
+
+	return &node, nil
+}
+
+func clearStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		clearStyle(child)
+	}
+}
+
+// yamlToJSONInto converts a generic YAML value to JSON and unmarshals it into v.
+func yamlToJSONInto(obj, v interface{}) error {
+	obj, err := convertYAMLKeys(obj)
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(obj)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, v)
+}
+
+// convertYAMLKeys converts the map keys of a generic YAML value to strings, as JSON requires.
+func convertYAMLKeys(obj interface{}) (interface{}, error) {
+	switch val := obj.(type) {
+	case map[string]interface{}:
+		for k, item := range val {
+			converted, err := convertYAMLKeys(item)
+			if err != nil {
+				return nil, err
+			}
+			val[k] = converted
+		}
+
+		return val, nil
+	case map[interface{}]interface{}:
+		out := make(map[string]interface{}, len(val))
+		for k, item := range val {
+			converted, err := convertYAMLKeys(item)
+			if err != nil {
+				return nil, err
+			}
+			out[fmt.Sprint(k)] = converted
+		}
+
+		return out, nil
+	case []interface{}:
+		for i, item := range val {
+			converted, err := convertYAMLKeys(item)
+			if err != nil {
+				return nil, err
+			}
+			val[i] = converted
+		}
+
+		return val, nil
+	default:
+		return obj, nil
+	}
+}
+
+// errNotProtoMessage is returned by the protobuf serializer for objects that are not protobuf messages.
+var errNotProtoMessage = errors.New("object does not implement the protobuf message interface")
+
+// protobufSerializer encodes objects that implement proto.Message. Protobuf messages are not
+// self-delimiting, so streams of them need a length-delimited framer.
+type protobufSerializer struct{}
+
+var _ Serializer = protobufSerializer{}
+
+func (protobufSerializer) Encode(v interface{}) ([]byte, error) {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("%T: %w", v, errNotProtoMessage)
+	}
+
+	return proto.Marshal(m)
+}
+
+func (protobufSerializer) Decode(data []byte, v interface{}) error {
+	m, ok := v.(proto.Message)
+	if !ok {
+		return fmt.Errorf("%T: %w", v, errNotProtoMessage)
+	}
+
+	return proto.Unmarshal(data, m)
+}
+
+// msgpackSerializer encodes objects as MessagePack, honoring the json struct tags like gin does.
+type msgpackSerializer struct {
+	handle *ugorji.MsgpackHandle
+}
+
+var (
+	_ Serializer       = msgpackSerializer{}
+	_ StreamSerializer = msgpackSerializer{}
+)
+
+func newMsgpackSerializer() msgpackSerializer {
+	h := &ugorji.MsgpackHandle{}
+	h.RawToString = true
+	h.WriteExt = true
+
+	return msgpackSerializer{handle: h}
+}
+
+func (s msgpackSerializer) Encode(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := ugorji.NewEncoder(&buf, s.handle).Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func (s msgpackSerializer) Decode(data []byte, v interface{}) error {
+	return ugorji.NewDecoderBytes(data, s.handle).Decode(v)
+}
+
+// NewEncoder returns an encoder that writes consecutive MessagePack values.
+func (s msgpackSerializer) NewEncoder(w io.Writer) StreamEncoder {
+	return ugorji.NewEncoder(w, s.handle)
+}
+
+// NewDecoder returns a decoder that reads consecutive MessagePack values.
+func (s msgpackSerializer) NewDecoder(r io.Reader) StreamDecoder {
+	return ugorji.NewDecoder(r, s.handle)
+}
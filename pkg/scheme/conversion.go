@@ -0,0 +1,192 @@
+package scheme
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// APIVersionInternal may be used if you are registering a type that should not
+// be considered stable or serialized - it is a convention only and has no
+// special behavior in this package. The internal version is the hub all
+// versioned (external) types are converted through.
+const APIVersionInternal = "__internal"
+
+// GroupVersioner refines a set of possible conversion targets into a single option.
+type GroupVersioner interface {
+	// KindForGroupVersionKinds returns a desired target group version kind for the given input, or returns ok false if no
+	// target is known. In general, if the return target is not in the input list, the caller is expected to invoke
+	// Scheme.New(target) and then perform a conversion between the current Go type and the destination Go type.
+	// Sophisticated implementations may use additional information about the input kinds to pick a destination kind.
+	KindForGroupVersionKinds(kinds []GroupVersionKind) (target GroupVersionKind, ok bool)
+	// Identifier returns string representation of the object.
+	// Identifiers of two different encoders should be equal only if for every input
+	// kinds they return the same result.
+	Identifier() string
+}
+
+var (
+	_ GroupVersioner = GroupVersion{}
+	_ GroupVersioner = GroupVersions{}
+	_ GroupVersioner = internalGroupVersioner{}
+)
+
+// InternalGroupVersioner will always prefer the internal version for a given group version kind.
+var InternalGroupVersioner GroupVersioner = internalGroupVersioner{}
+
+type internalGroupVersioner struct{}
+
+// KindForGroupVersionKinds returns an internal Kind if one is found, or converts the first provided kind to the
+// internal version.
+func (internalGroupVersioner) KindForGroupVersionKinds(kinds []GroupVersionKind) (GroupVersionKind, bool) {
+	for _, kind := range kinds {
+		if kind.Version == APIVersionInternal {
+			return kind, true
+		}
+	}
+	if len(kinds) == 0 {
+		return GroupVersionKind{}, false
+	}
+
+	return GroupVersionKind{Group: kinds[0].Group, Version: APIVersionInternal, Kind: kinds[0].Kind}, true
+}
+
+// Identifier implements GroupVersioner interface.
+func (internalGroupVersioner) Identifier() string {
+	return "internal"
+}
+
+// ConversionFunc converts the object a into the object b. a and b are pointers to the
+// types the function was registered for.
+type ConversionFunc func(a, b interface{}) error
+
+type typePair struct {
+	source reflect.Type
+	dest   reflect.Type
+}
+
+// AddConversionFunc registers a function that converts between a and b by passing objects of those
+// types to the provided function. a and b must be pointers to structs. Conversions between two
+// versions of a kind are normally registered to and from the internal version only, Convert then
+// goes through the internal version when no direct conversion is registered.
+func (s *Scheme) AddConversionFunc(a, b interface{}, fn ConversionFunc) error {
+	typeA, typeB := reflect.TypeOf(a), reflect.TypeOf(b)
+	if typeA == nil || typeA.Kind() != reflect.Ptr || typeB == nil || typeB.Kind() != reflect.Ptr {
+		return fmt.Errorf("conversion functions must be registered for pointer types, got %v and %v", typeA, typeB)
+	}
+	if fn == nil {
+		return fmt.Errorf("conversion function for %v to %v must not be nil", typeA, typeB)
+	}
+	s.conversionFuncs[typePair{source: typeA, dest: typeB}] = fn
+
+	return nil
+}
+
+// AddTypeDefaultingFunc registers a function that is passed a pointer to an
+// object and can default fields on the object. These functions will be invoked
+// when Default() is called. The function will never be called unless the
+// defaulted object matches srcType. If this function is invoked twice with the
+// same srcType, the fn passed to the later call will be used instead.
+func (s *Scheme) AddTypeDefaultingFunc(srcType Object, fn func(interface{})) {
+	s.defaulterFuncs[reflect.TypeOf(srcType)] = fn
+}
+
+// Default sets defaults on the provided Object.
+func (s *Scheme) Default(src Object) {
+	if fn, ok := s.defaulterFuncs[reflect.TypeOf(src)]; ok {
+		fn(src)
+	}
+}
+
+// Convert will attempt to convert in into out. Both must be pointers. A conversion function
+// registered for the exact pair of types is used first. Otherwise in is converted to the
+// internal version of its kind and from there into out.
+func (s *Scheme) Convert(in, out interface{}) error {
+	inType, outType := reflect.TypeOf(in), reflect.TypeOf(out)
+	if _, err := enforcePtr(in); err != nil {
+		return fmt.Errorf("converting (%v) to (%v): %w", inType, outType, err)
+	}
+	if _, err := enforcePtr(out); err != nil {
+		return fmt.Errorf("converting (%v) to (%v): %w", inType, outType, err)
+	}
+
+	if fn, ok := s.conversionFuncs[typePair{source: inType, dest: outType}]; ok {
+		return fn(in, out)
+	}
+	if inType == outType {
+		reflect.ValueOf(out).Elem().Set(reflect.ValueOf(in).Elem())
+
+		return nil
+	}
+
+	hub, err := s.internalObjectFor(in)
+	if err != nil {
+		return err
+	}
+	hubType := reflect.TypeOf(hub)
+	toHub, ok := s.conversionFuncs[typePair{source: inType, dest: hubType}]
+	if !ok || hubType == outType {
+		return fmt.Errorf("converting (%v) to (%v): unknown conversion", inType, outType)
+	}
+	fromHub, ok := s.conversionFuncs[typePair{source: hubType, dest: outType}]
+	if !ok {
+		return fmt.Errorf("converting (%v) to (%v): unknown conversion", inType, outType)
+	}
+	if err := toHub(in, hub); err != nil {
+		return err
+	}
+
+	return fromHub(hub, out)
+}
+
+// ConvertToVersion attempts to convert an input object to its matching Kind in another
+// version within this scheme. The target is chosen by target.KindForGroupVersionKinds from the
+// kinds registered for the type of in. in is never modified and the returned object carries the
+// type information of the target version, unless the target is the internal version.
+func (s *Scheme) ConvertToVersion(in Object, target GroupVersioner) (Object, error) {
+	kinds, err := s.ObjectKinds(in)
+	if err != nil {
+		return nil, err
+	}
+
+	gvk, ok := target.KindForGroupVersionKinds(kinds)
+	if !ok {
+		return nil, fmt.Errorf("%v is not suitable for converting to %q", reflect.TypeOf(in), target.Identifier())
+	}
+
+	out, err := s.New(gvk)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.Convert(in, out); err != nil {
+		return nil, err
+	}
+	setTargetKind(out, gvk)
+
+	return out, nil
+}
+
+// internalObjectFor returns a new object of the internal version of the kind of in.
+func (s *Scheme) internalObjectFor(in interface{}) (Object, error) {
+	obj, ok := in.(Object)
+	if !ok {
+		return nil, fmt.Errorf("%v is not a registered object", reflect.TypeOf(in))
+	}
+	kinds, err := s.ObjectKinds(obj)
+	if err != nil {
+		return nil, err
+	}
+	gvk, _ := InternalGroupVersioner.KindForGroupVersionKinds(kinds)
+
+	return s.New(gvk)
+}
+
+// setTargetKind sets the type information of obj to gvk. Internal objects are never serialized,
+// so their type information is cleared instead.
+func setTargetKind(obj Object, gvk GroupVersionKind) {
+	if gvk.Version == APIVersionInternal {
+		obj.GetObjectKind().SetGroupVersionKind(GroupVersionKind{})
+
+		return
+	}
+	obj.GetObjectKind().SetGroupVersionKind(gvk)
+}
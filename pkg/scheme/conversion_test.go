@@ -0,0 +1,135 @@
+package scheme_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/scheme"
+)
+
+// InternalUser is the hub version of the User kind.
+type InternalUser struct {
+	metav1.TypeMeta
+	FirstName string
+	LastName  string
+	Nickname  string
+}
+
+// UserV2 is the v2 shape of the User kind, v1 uses User.
+type UserV2 struct {
+	metav1.TypeMeta `json:",inline"`
+	FirstName       string `json:"firstName"`
+	LastName        string `json:"lastName"`
+	Nickname        string `json:"nickname"`
+}
+
+var internalGV = scheme.GroupVersion{Group: "iam.api", Version: scheme.APIVersionInternal}
+
+func newConversionScheme(t *testing.T) *scheme.Scheme {
+	s := scheme.NewScheme()
+	s.AddKnownTypeWithName(internalGV.WithKind("User"), &InternalUser{})
+	s.AddKnownTypes(v1GV, &User{})
+	s.AddKnownTypeWithName(v2GV.WithKind("User"), &UserV2{})
+
+	funcs := []struct {
+		a, b interface{}
+		fn   scheme.ConversionFunc
+	}{
+		{&User{}, &InternalUser{}, func(a, b interface{}) error {
+			in, out := a.(*User), b.(*InternalUser)
+			out.FirstName, out.LastName, _ = strings.Cut(in.Name, " ")
+			return nil
+		}},
+		{&InternalUser{}, &User{}, func(a, b interface{}) error {
+			in, out := a.(*InternalUser), b.(*User)
+			out.Name = strings.TrimSpace(in.FirstName + " " + in.LastName)
+			return nil
+		}},
+		{&UserV2{}, &InternalUser{}, func(a, b interface{}) error {
+			in, out := a.(*UserV2), b.(*InternalUser)
+			out.FirstName, out.LastName, out.Nickname = in.FirstName, in.LastName, in.Nickname
+			return nil
+		}},
+		{&InternalUser{}, &UserV2{}, func(a, b interface{}) error {
+			in, out := a.(*InternalUser), b.(*UserV2)
+			out.FirstName, out.LastName, out.Nickname = in.FirstName, in.LastName, in.Nickname
+			return nil
+		}},
+	}
+	for _, f := range funcs {
+		if err := s.AddConversionFunc(f.a, f.b, f.fn); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+	s.AddTypeDefaultingFunc(&UserV2{}, func(obj interface{}) {
+		user := obj.(*UserV2)
+		if user.Nickname == "" {
+			user.Nickname = strings.ToLower(user.FirstName)
+		}
+	})
+
+	return s
+}
+
+func TestSchemeConvertThroughHub(t *testing.T) {
+	s := newConversionScheme(t)
+
+	out := &UserV2{}
+	if err := s.Convert(&User{Name: "Colin Kube"}, out); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if out.FirstName != "Colin" || out.LastName != "Kube" {
+		t.Errorf("unexpected conversion result: %#v", out)
+	}
+
+	if err := s.Convert(&User{}, &Secret{}); err == nil {
+		t.Errorf("expected error converting between unrelated kinds")
+	}
+}
+
+func TestSchemeConvertToVersion(t *testing.T) {
+	s := newConversionScheme(t)
+
+	in := &UserV2{FirstName: "Colin", LastName: "Kube"}
+	in.SetGroupVersionKind(v2GV.WithKind("User"))
+
+	tests := []struct {
+		target   scheme.GroupVersioner
+		expected string
+	}{
+		{target: v1GV, expected: `&{TypeMeta:{Kind:User APIVersion:iam.api/v1} Name:Colin Kube}`},
+		{target: scheme.GroupVersions{{Group: "other", Version: "v1"}, v1GV}, expected: `&{TypeMeta:{Kind:User APIVersion:iam.api/v1} Name:Colin Kube}`},
+		{target: v2GV, expected: `&{TypeMeta:{Kind:User APIVersion:iam.api/v2} FirstName:Colin LastName:Kube Nickname:}`},
+		{target: scheme.InternalGroupVersioner, expected: `&{TypeMeta:{Kind: APIVersion:} FirstName:Colin LastName:Kube Nickname:}`},
+	}
+	for _, tt := range tests {
+		out, err := s.ConvertToVersion(in, tt.target)
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tt.target.Identifier(), err)
+
+			continue
+		}
+		if got := fmt.Sprintf("%+v", out); got != tt.expected {
+			t.Errorf("%s: expected %s, got %s", tt.target.Identifier(), tt.expected, got)
+		}
+	}
+
+	if _, err := s.ConvertToVersion(in, scheme.GroupVersion{Group: "other", Version: "v1"}); err == nil {
+		t.Errorf("expected error converting to an unknown group")
+	}
+}
+
+func TestSchemeDefault(t *testing.T) {
+	s := newConversionScheme(t)
+
+	user := &UserV2{FirstName: "Colin"}
+	s.Default(user)
+	if user.Nickname != "colin" {
+		t.Errorf("expected defaulted nickname, got %q", user.Nickname)
+	}
+
+	// types without a defaulting function are left untouched
+	s.Default(&User{})
+}
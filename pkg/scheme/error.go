@@ -0,0 +1,42 @@
+package scheme
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+type notRegisteredErr struct {
+	gvk GroupVersionKind
+	t   reflect.Type
+}
+
+// NewNotRegisteredErrForKind returns an error indicating that gvk has not been registered in the scheme.
+func NewNotRegisteredErrForKind(gvk GroupVersionKind) error {
+	return &notRegisteredErr{gvk: gvk}
+}
+
+// NewNotRegisteredErrForType returns an error indicating that the go type t has not been registered
+// in the scheme.
+func NewNotRegisteredErrForType(t reflect.Type) error {
+	return &notRegisteredErr{t: t}
+}
+
+func (k *notRegisteredErr) Error() string {
+	if k.t != nil {
+		return fmt.Sprintf("no kind is registered for the type %v", k.t)
+	}
+	if len(k.gvk.Kind) == 0 {
+		return fmt.Sprintf("no version %q has been registered", k.gvk.GroupVersion())
+	}
+
+	return fmt.Sprintf("no kind %q is registered for version %q", k.gvk.Kind, k.gvk.GroupVersion())
+}
+
+// IsNotRegisteredError returns true if the error indicates the provided
+// object or input data is not registered.
+func IsNotRegisteredError(err error) bool {
+	var target *notRegisteredErr
+
+	return errors.As(err, &target)
+}
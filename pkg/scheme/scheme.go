@@ -0,0 +1,294 @@
+package scheme
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// Object is the interface all API types registered in a Scheme must implement. Types that embed
+// meta/v1.TypeMeta satisfy it automatically.
+type Object interface {
+	GetObjectKind() ObjectKind
+}
+
+// Scheme defines methods for mapping between Go types and GroupVersionKinds. It is the base for
+// decoding payloads whose concrete type is only known from their apiVersion and kind fields.
+//
+// A Scheme is not safe for concurrent registration; register all types during initialization and
+// only read from it afterwards.
+type Scheme struct {
+	// gvkToType allows one to figure out the go type of an object with the given version and name.
+	gvkToType map[GroupVersionKind]reflect.Type
+
+	// typeToGVK allows one to find metadata for a given go object. The first registered
+	// GroupVersionKind of a type is its preferred one.
+	typeToGVK map[reflect.Type][]GroupVersionKind
+
+	// observedVersions keeps track of the order in which versions were registered.
+	observedVersions []GroupVersion
+
+	// conversionFuncs holds the registered conversion functions keyed by source and destination type.
+	conversionFuncs map[typePair]ConversionFunc
+
+	// defaulterFuncs is a map to funcs to be called with an object to provide defaulting
+	// the provided object must be a pointer.
+	defaulterFuncs map[reflect.Type]func(interface{})
+}
+
+// NewScheme creates a new Scheme. This scheme is pluggable by default.
+func NewScheme() *Scheme {
+	return &Scheme{
+		gvkToType:       map[GroupVersionKind]reflect.Type{},
+		typeToGVK:       map[reflect.Type][]GroupVersionKind{},
+		conversionFuncs: map[typePair]ConversionFunc{},
+		defaulterFuncs:  map[reflect.Type]func(interface{}){},
+	}
+}
+
+// AddKnownTypes registers all types passed in 'types' as being members of version 'version'.
+// All objects passed to types should be pointers to structs. The name that go reports for
+// the struct becomes the "kind" field when encoding.
+func (s *Scheme) AddKnownTypes(gv GroupVersion, types ...Object) {
+	s.addObservedVersion(gv)
+	for _, obj := range types {
+		t := structType(obj)
+		s.AddKnownTypeWithName(gv.WithKind(t.Name()), obj)
+	}
+}
+
+// AddKnownTypeWithName is like AddKnownTypes, but it lets you specify what this type should
+// be encoded as. Registering a different type under an already registered GroupVersionKind panics.
+func (s *Scheme) AddKnownTypeWithName(gvk GroupVersionKind, obj Object) {
+	if len(gvk.Version) == 0 {
+		panic(fmt.Sprintf("version is required on all types: %s %v", gvk, reflect.TypeOf(obj)))
+	}
+	if len(gvk.Kind) == 0 {
+		panic(fmt.Sprintf("kind is required on all types: %s %v", gvk, reflect.TypeOf(obj)))
+	}
+	s.addObservedVersion(gvk.GroupVersion())
+
+	t := structType(obj)
+	if oldT, found := s.gvkToType[gvk]; found {
+		if oldT != t {
+			panic(fmt.Sprintf("double registration of different types for %v: old=%v.%v, new=%v.%v",
+				gvk, oldT.PkgPath(), oldT.Name(), t.PkgPath(), t.Name()))
+		}
+
+		return
+	}
+
+	s.gvkToType[gvk] = t
+	s.typeToGVK[t] = append(s.typeToGVK[t], gvk)
+}
+
+// KnownTypes returns the types known for the given version.
+func (s *Scheme) KnownTypes(gv GroupVersion) map[string]reflect.Type {
+	types := make(map[string]reflect.Type)
+	for gvk, t := range s.gvkToType {
+		if gv != gvk.GroupVersion() {
+			continue
+		}
+		types[gvk.Kind] = t
+	}
+
+	return types
+}
+
+// AllKnownTypes returns the all known types.
+func (s *Scheme) AllKnownTypes() map[GroupVersionKind]reflect.Type {
+	types := make(map[GroupVersionKind]reflect.Type, len(s.gvkToType))
+	for gvk, t := range s.gvkToType {
+		types[gvk] = t
+	}
+
+	return types
+}
+
+// Recognizes returns true if the scheme is able to handle the provided group,version,kind
+// of an object.
+func (s *Scheme) Recognizes(gvk GroupVersionKind) bool {
+	_, exists := s.gvkToType[gvk]
+
+	return exists
+}
+
+// IsVersionRegistered returns true if types have been registered for the given version.
+func (s *Scheme) IsVersionRegistered(gv GroupVersion) bool {
+	for _, observed := range s.observedVersions {
+		if observed == gv {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ObjectKinds returns all possible group,version,kind of the go object. The first entry is the
+// preferred one. An error is returned if the type of obj has not been registered.
+func (s *Scheme) ObjectKinds(obj Object) ([]GroupVersionKind, error) {
+	t, err := objectType(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	gvks, ok := s.typeToGVK[t]
+	if !ok {
+		return nil, NewNotRegisteredErrForType(t)
+	}
+
+	return append([]GroupVersionKind(nil), gvks...), nil
+}
+
+// ObjectKind returns the preferred group,version,kind of the go object. If the object already
+// carries a registered GroupVersionKind for its type, that one is returned instead.
+func (s *Scheme) ObjectKind(obj Object) (GroupVersionKind, error) {
+	gvks, err := s.ObjectKinds(obj)
+	if err != nil {
+		return GroupVersionKind{}, err
+	}
+
+	if current := obj.GetObjectKind().GroupVersionKind(); !current.Empty() {
+		for _, gvk := range gvks {
+			if gvk == current {
+				return gvk, nil
+			}
+		}
+	}
+
+	return gvks[0], nil
+}
+
+// SetObjectKind fills the type information of obj (the apiVersion and kind fields of TypeMeta)
+// with the GroupVersionKind returned by ObjectKind. The type information of internal objects is
+// cleared.
+func (s *Scheme) SetObjectKind(obj Object) error {
+	gvk, err := s.ObjectKind(obj)
+	if err != nil {
+		return err
+	}
+	setTargetKind(obj, gvk)
+
+	return nil
+}
+
+// New returns a new API object of the given version and name, or an error if it hasn't
+// been registered. The version and kind fields must be specified. The type information of
+// the returned object is already set to gvk, unless gvk is an internal version.
+func (s *Scheme) New(gvk GroupVersionKind) (Object, error) {
+	t, exists := s.gvkToType[gvk]
+	if !exists {
+		return nil, NewNotRegisteredErrForKind(gvk)
+	}
+
+	obj, _ := reflect.New(t).Interface().(Object)
+	setTargetKind(obj, gvk)
+
+	return obj, nil
+}
+
+// PreferredVersions returns the registered versions in registration order.
+func (s *Scheme) PreferredVersions() []GroupVersion {
+	return append([]GroupVersion(nil), s.observedVersions...)
+}
+
+// PrioritizedVersionsForGroup returns versions for a single group in registration order.
+func (s *Scheme) PrioritizedVersionsForGroup(group string) []GroupVersion {
+	var versions []GroupVersion
+	for _, gv := range s.observedVersions {
+		if gv.Group == group {
+			versions = append(versions, gv)
+		}
+	}
+
+	return versions
+}
+
+// Kinds returns all registered GroupVersionKinds sorted by their string form, mainly for
+// diagnostics.
+func (s *Scheme) Kinds() []GroupVersionKind {
+	gvks := make([]GroupVersionKind, 0, len(s.gvkToType))
+	for gvk := range s.gvkToType {
+		gvks = append(gvks, gvk)
+	}
+	sort.Slice(gvks, func(i, j int) bool {
+		return gvks[i].String() < gvks[j].String()
+	})
+
+	return gvks
+}
+
+func (s *Scheme) addObservedVersion(gv GroupVersion) {
+	if len(gv.Version) == 0 || s.IsVersionRegistered(gv) {
+		return
+	}
+	s.observedVersions = append(s.observedVersions, gv)
+}
+
+// structType returns the struct type obj points to, and panics if obj is not a pointer to a struct.
+func structType(obj Object) reflect.Type {
+	t := reflect.TypeOf(obj)
+	if t == nil || t.Kind() != reflect.Ptr || t.Elem().Kind() != reflect.Struct {
+		panic(fmt.Sprintf("all types must be pointers to structs, got %v", t))
+	}
+
+	return t.Elem()
+}
+
+func objectType(obj Object) (reflect.Type, error) {
+	v, err := enforcePtr(obj)
+	if err != nil {
+		return nil, err
+	}
+
+	return v.Type(), nil
+}
+
+// enforcePtr ensures that obj is a non-nil pointer of some sort. Returns a reflect.Value
+// of the dereferenced pointer.
+func enforcePtr(obj interface{}) (reflect.Value, error) {
+	v := reflect.ValueOf(obj)
+	if v.Kind() != reflect.Ptr {
+		if v.Kind() == reflect.Invalid {
+			return reflect.Value{}, fmt.Errorf("expected pointer, but got invalid kind")
+		}
+
+		return reflect.Value{}, fmt.Errorf("expected pointer, but got %v type", v.Type())
+	}
+	if v.IsNil() {
+		return reflect.Value{}, fmt.Errorf("expected pointer, but got nil")
+	}
+
+	return v.Elem(), nil
+}
+
+// SchemeBuilder collects functions that add things to a scheme. It's to allow
+// code to compile without explicitly referencing generated types. You should
+// declare one in each package that will have generated deep copy or conversion
+// functions.
+type SchemeBuilder []func(*Scheme) error
+
+// AddToScheme applies all the stored functions to the scheme. A non-nil error
+// indicates that one function failed and the attempt was abandoned.
+func (sb *SchemeBuilder) AddToScheme(s *Scheme) error {
+	for _, f := range *sb {
+		if err := f(s); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Register adds a scheme setup function to the list.
+func (sb *SchemeBuilder) Register(funcs ...func(*Scheme) error) {
+	*sb = append(*sb, funcs...)
+}
+
+// NewSchemeBuilder calls Register for you.
+func NewSchemeBuilder(funcs ...func(*Scheme) error) SchemeBuilder {
+	var sb SchemeBuilder
+	sb.Register(funcs...)
+
+	return sb
+}
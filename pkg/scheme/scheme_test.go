@@ -0,0 +1,125 @@
+package scheme_test
+
+import (
+	"reflect"
+	"testing"
+
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/scheme"
+)
+
+type User struct {
+	metav1.TypeMeta `json:",inline"`
+	Name            string `json:"name"`
+}
+
+type Secret struct {
+	metav1.TypeMeta `json:",inline"`
+	Value           string `json:"value"`
+}
+
+var (
+	v1GV = scheme.GroupVersion{Group: "iam.api", Version: "v1"}
+	v2GV = scheme.GroupVersion{Group: "iam.api", Version: "v2"}
+)
+
+func newTestScheme(t *testing.T) *scheme.Scheme {
+	builder := scheme.NewSchemeBuilder(func(s *scheme.Scheme) error {
+		s.AddKnownTypes(v1GV, &User{}, &Secret{})
+		s.AddKnownTypes(v2GV, &User{})
+
+		return nil
+	})
+	s := scheme.NewScheme()
+	if err := builder.AddToScheme(s); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return s
+}
+
+func TestSchemeNew(t *testing.T) {
+	s := newTestScheme(t)
+
+	obj, err := s.New(v2GV.WithKind("User"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	user, ok := obj.(*User)
+	if !ok {
+		t.Fatalf("expected *User, got %T", obj)
+	}
+	if user.APIVersion != "iam.api/v2" || user.Kind != "User" {
+		t.Errorf("unexpected type meta: %#v", user.TypeMeta)
+	}
+
+	_, err = s.New(v2GV.WithKind("Secret"))
+	if !scheme.IsNotRegisteredError(err) {
+		t.Errorf("expected not registered error, got %v", err)
+	}
+}
+
+func TestSchemeObjectKinds(t *testing.T) {
+	s := newTestScheme(t)
+
+	gvks, err := s.ObjectKinds(&User{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := []scheme.GroupVersionKind{v1GV.WithKind("User"), v2GV.WithKind("User")}
+	if !reflect.DeepEqual(gvks, expected) {
+		t.Errorf("expected %v, got %v", expected, gvks)
+	}
+
+	type Unknown struct{ metav1.TypeMeta }
+	if _, err := s.ObjectKinds(&Unknown{}); !scheme.IsNotRegisteredError(err) {
+		t.Errorf("expected not registered error, got %v", err)
+	}
+}
+
+func TestSchemeSetObjectKind(t *testing.T) {
+	s := newTestScheme(t)
+
+	user := &User{}
+	if err := s.SetObjectKind(user); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gvk := user.GroupVersionKind(); gvk != v1GV.WithKind("User") {
+		t.Errorf("expected preferred kind, got %v", gvk)
+	}
+
+	// a registered kind already set on the object is kept
+	user.SetGroupVersionKind(v2GV.WithKind("User"))
+	if err := s.SetObjectKind(user); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if gvk := user.GroupVersionKind(); gvk != v2GV.WithKind("User") {
+		t.Errorf("expected existing kind to be kept, got %v", gvk)
+	}
+}
+
+func TestSchemeKnownTypes(t *testing.T) {
+	s := newTestScheme(t)
+
+	types := s.KnownTypes(v1GV)
+	if len(types) != 2 || types["User"] != reflect.TypeOf(User{}) || types["Secret"] != reflect.TypeOf(Secret{}) {
+		t.Errorf("unexpected known types: %v", types)
+	}
+	if !s.Recognizes(v1GV.WithKind("Secret")) || s.Recognizes(v2GV.WithKind("Secret")) {
+		t.Errorf("unexpected recognized kinds: %v", s.Kinds())
+	}
+	if versions := s.PrioritizedVersionsForGroup("iam.api"); !reflect.DeepEqual(versions, []scheme.GroupVersion{v1GV, v2GV}) {
+		t.Errorf("unexpected versions: %v", versions)
+	}
+}
+
+func TestSchemeDoubleRegistration(t *testing.T) {
+	s := newTestScheme(t)
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("expected panic on double registration")
+		}
+	}()
+	s.AddKnownTypeWithName(v1GV.WithKind("User"), &Secret{})
+}
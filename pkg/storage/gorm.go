@@ -0,0 +1,164 @@
+package storage
+
+import (
+	"context"
+	"reflect"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/gzwillyy/components/errors"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+)
+
+// gormStorage stores every type of object in its own table of a gorm database.
+type gormStorage struct {
+	db *gorm.DB
+}
+
+var _ Interface = &gormStorage{}
+
+// NewGormStorage returns a storage backed by db. The tables of the stored types must exist, e.g.
+// created with db.AutoMigrate.
+func NewGormStorage(db *gorm.DB) Interface {
+	return &gormStorage{db: db}
+}
+
+// Get implements Interface.
+func (s *gormStorage) Get(ctx context.Context, name string, out metav1.Object, _ metav1.GetOptions) error {
+	err := s.db.WithContext(ctx).Where(nameEq(name)).Take(out).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return notFound(name)
+	}
+
+	return err
+}
+
+// List implements Interface.
+func (s *gormStorage) List(ctx context.Context, list interface{}, opts metav1.ListOptions) error {
+	listMeta, items, elem, err := listFields(list)
+	if err != nil {
+		return err
+	}
+	selectors, err := metav1.ListOptionsScope(opts, metav1.SelectableFields(reflect.New(elem).Interface())...)
+	if err != nil {
+		return err
+	}
+	pager, err := metav1.NewPager(opts)
+	if err != nil {
+		return err
+	}
+
+	query := s.db.WithContext(ctx).Model(reflect.New(elem).Interface()).Scopes(selectors).Session(&gorm.Session{})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return err
+	}
+
+	page := query.Scopes(pager.Scope)
+	if opts.Offset != nil {
+		page = page.Offset(int(*opts.Offset))
+	}
+	if err := page.Find(items.Addr().Interface()).Error; err != nil {
+		return err
+	}
+	if err := pager.Complete(query, listMeta, items.Addr().Interface()); err != nil {
+		return err
+	}
+	listMeta.TotalCount = total
+
+	return nil
+}
+
+// Create implements Interface.
+func (s *gormStorage) Create(ctx context.Context, obj metav1.Object, opts metav1.CreateOptions) error {
+	db := s.db.WithContext(ctx)
+	if len(opts.DryRun) > 0 {
+		var count int64
+		if err := db.Model(newObject(reflect.TypeOf(obj).Elem())).Where(nameEq(obj.GetName())).Count(&count).Error; err != nil {
+			return err
+		}
+		if count > 0 {
+			return alreadyExists(obj.GetName())
+		}
+		dryRunCreate(obj)
+
+		return nil
+	}
+
+	// the unique index of the name column rejects concurrent creates, a failed insert is
+	// reported as ErrAlreadyExists if the name is taken
+	err := db.Create(obj).Error
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return alreadyExists(obj.GetName())
+	}
+	var count int64
+	if db.Model(newObject(reflect.TypeOf(obj).Elem())).Where(nameEq(obj.GetName())).Count(&count).Error == nil && count > 0 {
+		return alreadyExists(obj.GetName())
+	}
+
+	return err
+}
+
+// Update implements Interface.
+func (s *gormStorage) Update(ctx context.Context, obj metav1.Object, opts metav1.UpdateOptions) error {
+	stored, err := s.stored(ctx, obj)
+	if err != nil {
+		return err
+	}
+	if err := opts.Preconditions.Check(stored); err != nil {
+		return err
+	}
+	obj.SetID(stored.GetID())
+	if len(opts.DryRun) > 0 {
+		return dryRunUpdate(stored, obj)
+	}
+
+	return s.db.WithContext(ctx).Save(obj).Error
+}
+
+// Delete implements Interface.
+func (s *gormStorage) Delete(ctx context.Context, obj metav1.Object, opts metav1.DeleteOptions) error {
+	stored, err := s.stored(ctx, obj)
+	if err != nil {
+		return err
+	}
+
+	// the finalizers and the deletion timestamp of the stored object decide whether it is purged,
+	// the resource version of obj, if set, is the precondition of marking it terminating
+	if rv := obj.GetResourceVersion(); rv != 0 {
+		stored.SetResourceVersion(rv)
+	}
+	terminating := metav1.IsTerminating(stored)
+	purged, err := metav1.Delete(s.db.WithContext(ctx), stored, opts)
+	if err != nil || purged {
+		return err
+	}
+
+	obj.SetID(stored.GetID())
+	obj.SetFinalizers(stored.GetFinalizers())
+	obj.SetDeletionTimestamp(stored.GetDeletionTimestamp())
+	if !terminating {
+		obj.SetResourceVersion(stored.GetResourceVersion() + 1)
+	}
+
+	return nil
+}
+
+// stored returns the stored object with the name of obj.
+func (s *gormStorage) stored(ctx context.Context, obj metav1.Object) (metav1.Object, error) {
+	stored := newObject(reflect.TypeOf(obj).Elem())
+	if err := s.Get(ctx, obj.GetName(), stored, metav1.GetOptions{}); err != nil {
+		return nil, err
+	}
+
+	return stored, nil
+}
+
+// nameEq is the condition selecting the object called name.
+func nameEq(name string) clause.Expression {
+	return clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "name"}, Value: name}
+}
@@ -0,0 +1,71 @@
+// Package storage defines the storage of API objects embedding meta/v1.ObjectMeta and provides a
+// gorm and an in-memory implementation with identical semantics. The in-memory storage lets tests
+// run without a database. storagetest holds the conformance tests every implementation must pass.
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/gzwillyy/components/errors"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+)
+
+// Interface stores API objects. Objects are identified by their name within their type. Missing
+// objects are reported with metav1.ErrNotFound errors, names already taken with
+// metav1.ErrAlreadyExists errors and concurrent modifications with metav1.ErrConflict errors.
+type Interface interface {
+	// Get reads the object called name into out.
+	Get(ctx context.Context, name string, out metav1.Object, opts metav1.GetOptions) error
+
+	// List reads the objects matching the selectors of opts into list, a pointer to a struct with a
+	// ListMeta and an Items field holding a slice of objects or of pointers to objects. The items
+	// are ordered by ID and paginated with continue tokens, ListMeta.TotalCount is the number of
+	// objects matching the selectors.
+	List(ctx context.Context, list interface{}, opts metav1.ListOptions) error
+
+	// Create stores the new object obj. Its ID, resource version and timestamps are set. Names are
+	// unique per type, ErrAlreadyExists is returned if the name is taken. A dry run checks the name
+	// and sets the resource version and timestamps of obj without storing it.
+	Create(ctx context.Context, obj metav1.Object, opts metav1.CreateOptions) error
+
+	// Update stores obj, it is conditional on the resource version of obj, which is incremented,
+	// and on the preconditions of opts. An object that is terminating and has no finalizers left is
+	// purged. A dry run checks the conditions and updates obj without storing it.
+	Update(ctx context.Context, obj metav1.Object, opts metav1.UpdateOptions) error
+
+	// Delete deletes the object named by obj like metav1.Delete does: a stored object with
+	// finalizers is only marked as terminating, obj is updated to reflect that. Marking requires a
+	// non-zero resource version of obj to match the stored one, otherwise ErrConflict is returned.
+	Delete(ctx context.Context, obj metav1.Object, opts metav1.DeleteOptions) error
+}
+
+// notFound returns the error for a missing object.
+func notFound(name string) error {
+	return errors.WithCode(metav1.ErrNotFound, "object %q not found", name)
+}
+
+// alreadyExists returns the error for a name that is already taken.
+func alreadyExists(name string) error {
+	return errors.WithCode(metav1.ErrAlreadyExists, "object %q already exists", name)
+}
+
+// dryRunCreate sets the fields of obj a create would set, except the ID.
+func dryRunCreate(obj metav1.Object) {
+	now := time.Now()
+	obj.SetResourceVersion(1)
+	obj.SetCreatedAt(now)
+	obj.SetUpdatedAt(now)
+}
+
+// dryRunUpdate checks the resource version of obj against stored and sets the fields of obj an
+// update would set.
+func dryRunUpdate(stored, obj metav1.Object) error {
+	if stored.GetResourceVersion() != obj.GetResourceVersion() {
+		return metav1.NewConflict(obj.GetName(), obj.GetResourceVersion())
+	}
+	obj.SetResourceVersion(obj.GetResourceVersion() + 1)
+	obj.SetUpdatedAt(time.Now())
+
+	return nil
+}
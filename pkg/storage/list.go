@@ -0,0 +1,48 @@
+package storage
+
+import (
+	"fmt"
+	"reflect"
+
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+)
+
+var listMetaType = reflect.TypeOf(metav1.ListMeta{})
+
+// listFields returns the ListMeta and the Items slice of list and the struct type of the items.
+func listFields(list interface{}) (*metav1.ListMeta, reflect.Value, reflect.Type, error) {
+	v := reflect.ValueOf(list)
+	if v.Kind() != reflect.Ptr || v.Elem().Kind() != reflect.Struct {
+		return nil, reflect.Value{}, nil, fmt.Errorf("list must be a pointer to a struct, got %T", list)
+	}
+	v = v.Elem()
+
+	meta := v.FieldByName("ListMeta")
+	if !meta.IsValid() || meta.Type() != listMetaType {
+		return nil, reflect.Value{}, nil, fmt.Errorf("%T has no ListMeta field", list)
+	}
+	items := v.FieldByName("Items")
+	if !items.IsValid() || items.Kind() != reflect.Slice {
+		return nil, reflect.Value{}, nil, fmt.Errorf("%T has no Items slice", list)
+	}
+
+	elem := items.Type().Elem()
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+	}
+	if elem.Kind() != reflect.Struct || !reflect.PtrTo(elem).Implements(objectType) {
+		return nil, reflect.Value{}, nil, fmt.Errorf("the items of %T don't implement the metav1.Object interface", list)
+	}
+	listMeta, _ := meta.Addr().Interface().(*metav1.ListMeta)
+
+	return listMeta, items, elem, nil
+}
+
+var objectType = reflect.TypeOf((*metav1.Object)(nil)).Elem()
+
+// newObject returns a new object of the struct type t.
+func newObject(t reflect.Type) metav1.Object {
+	obj, _ := reflect.New(t).Interface().(metav1.Object)
+
+	return obj
+}
@@ -0,0 +1,412 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/schema"
+
+	"github.com/gzwillyy/components/errors"
+	"github.com/gzwillyy/components/pkg/fields"
+	"github.com/gzwillyy/components/pkg/labels"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/selection"
+)
+
+// memoryStorage keeps deep copies of the objects in memory.
+type memoryStorage struct {
+	mu      sync.RWMutex
+	tables  map[reflect.Type]*memoryTable
+	schemas sync.Map
+}
+
+// memoryTable holds the objects of one type by name.
+type memoryTable struct {
+	lastID  uint64
+	objects map[string]metav1.Object
+}
+
+var _ Interface = &memoryStorage{}
+
+// NewMemoryStorage returns a storage keeping the objects in memory. It behaves like the gorm
+// storage: selectors, pagination, resource versions, timestamps and graceful deletion follow the
+// same rules. Field selectors compare the gorm column names of the fields.
+func NewMemoryStorage() Interface {
+	return &memoryStorage{tables: map[reflect.Type]*memoryTable{}}
+}
+
+// Get implements Interface.
+func (s *memoryStorage) Get(_ context.Context, name string, out metav1.Object, _ metav1.GetOptions) error {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stored, ok := s.table(out).objects[name]
+	if !ok {
+		return notFound(name)
+	}
+	reflect.ValueOf(out).Elem().Set(deepCopy(reflect.ValueOf(stored)).Elem())
+
+	return nil
+}
+
+// List implements Interface.
+func (s *memoryStorage) List(_ context.Context, list interface{}, opts metav1.ListOptions) error {
+	listMeta, items, elem, err := listFields(list)
+	if err != nil {
+		return err
+	}
+	match, err := s.matcher(elem, opts)
+	if err != nil {
+		return err
+	}
+	pager, err := metav1.NewPager(opts)
+	if err != nil {
+		return err
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	var matching []metav1.Object
+	for _, obj := range s.tables[elem].objectsByID(pager.Order()) {
+		if match(obj) {
+			matching = append(matching, obj)
+		}
+	}
+
+	page := matching
+	if after := pager.After(); after != nil {
+		page = objectsAfter(page, after.Key, pager.Order())
+	}
+	if opts.Offset != nil {
+		page = page[min(int(*opts.Offset), len(page)):]
+	}
+
+	*listMeta = metav1.ListMeta{TotalCount: int64(len(matching))}
+	if limit := int(pager.Limit()); limit > 0 && len(page) > limit {
+		page = page[:limit]
+		last := page[limit-1]
+		if listMeta.Continue, err = pager.ContinueAfter(last); err != nil {
+			return err
+		}
+		remaining := int64(len(objectsAfter(matching, last.GetID(), pager.Order())))
+		listMeta.RemainingItemCount = &remaining
+	}
+
+	result := reflect.MakeSlice(items.Type(), 0, len(page))
+	for _, obj := range page {
+		item := deepCopy(reflect.ValueOf(obj))
+		if items.Type().Elem().Kind() != reflect.Ptr {
+			item = item.Elem()
+		}
+		result = reflect.Append(result, item)
+	}
+	items.Set(result)
+
+	return nil
+}
+
+// Create implements Interface.
+func (s *memoryStorage) Create(_ context.Context, obj metav1.Object, opts metav1.CreateOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table := s.table(obj)
+	if _, ok := table.objects[obj.GetName()]; ok {
+		return alreadyExists(obj.GetName())
+	}
+	if len(opts.DryRun) > 0 {
+		dryRunCreate(obj)
+
+		return nil
+	}
+
+	if obj.GetID() == 0 {
+		obj.SetID(table.lastID + 1)
+	}
+	table.lastID = max(table.lastID, obj.GetID())
+	if obj.GetResourceVersion() == 0 {
+		obj.SetResourceVersion(1)
+	}
+	now := time.Now()
+	if obj.GetCreatedAt().IsZero() {
+		obj.SetCreatedAt(now)
+	}
+	if obj.GetUpdatedAt().IsZero() {
+		obj.SetUpdatedAt(now)
+	}
+	table.store(obj)
+
+	return nil
+}
+
+// Update implements Interface.
+func (s *memoryStorage) Update(_ context.Context, obj metav1.Object, opts metav1.UpdateOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table := s.table(obj)
+	stored, ok := table.objects[obj.GetName()]
+	if !ok {
+		return notFound(obj.GetName())
+	}
+	if err := opts.Preconditions.Check(stored); err != nil {
+		return err
+	}
+	if len(opts.DryRun) > 0 {
+		obj.SetID(stored.GetID())
+
+		return dryRunUpdate(stored, obj)
+	}
+	if stored.GetResourceVersion() != obj.GetResourceVersion() {
+		return metav1.NewConflict(obj.GetName(), obj.GetResourceVersion())
+	}
+
+	obj.SetID(stored.GetID())
+	obj.SetResourceVersion(obj.GetResourceVersion() + 1)
+	obj.SetUpdatedAt(time.Now())
+	if metav1.IsTerminating(obj) && len(obj.GetFinalizers()) == 0 {
+		delete(table.objects, obj.GetName())
+
+		return nil
+	}
+	table.store(obj)
+
+	return nil
+}
+
+// Delete implements Interface.
+func (s *memoryStorage) Delete(_ context.Context, obj metav1.Object, opts metav1.DeleteOptions) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	table := s.table(obj)
+	stored, ok := table.objects[obj.GetName()]
+	if !ok {
+		return notFound(obj.GetName())
+	}
+
+	// like gormStorage the stored object decides whether it is purged, the resource version of obj,
+	// if set, is the precondition of marking it terminating
+	if opts.Unscoped || len(stored.GetFinalizers()) == 0 {
+		delete(table.objects, obj.GetName())
+
+		return nil
+	}
+	if metav1.IsTerminating(stored) {
+		return nil
+	}
+	if rv := obj.GetResourceVersion(); rv != 0 && rv != stored.GetResourceVersion() {
+		return metav1.NewConflict(obj.GetName(), rv)
+	}
+
+	// like metav1.Delete only the deletion timestamp of the stored object is updated
+	now := time.Now()
+	stored.SetDeletionTimestamp(&now)
+	stored.SetResourceVersion(stored.GetResourceVersion() + 1)
+	stored.SetUpdatedAt(now)
+	obj.SetID(stored.GetID())
+	obj.SetFinalizers(append([]string(nil), stored.GetFinalizers()...))
+	obj.SetDeletionTimestamp(&now)
+	obj.SetResourceVersion(stored.GetResourceVersion())
+	obj.SetUpdatedAt(now)
+
+	return nil
+}
+
+// table returns the table of the type of obj, creating it if needed. The caller holds the lock.
+func (s *memoryStorage) table(obj metav1.Object) *memoryTable {
+	t := reflect.TypeOf(obj).Elem()
+	table, ok := s.tables[t]
+	if !ok {
+		table = &memoryTable{objects: map[string]metav1.Object{}}
+		if s.tables != nil {
+			s.tables[t] = table
+		}
+	}
+
+	return table
+}
+
+// matcher returns a function reporting whether an object of type t matches the selectors and the
+// terminating policy of opts. Invalid options return the errors of metav1.ListOptionsScope.
+func (s *memoryStorage) matcher(t reflect.Type, opts metav1.ListOptions) (func(metav1.Object) bool, error) {
+	labelSelector, err := labels.Parse(opts.LabelSelector)
+	if err != nil {
+		return nil, errors.WrapC(err, metav1.ErrInvalidSelector, "invalid label selector %q", opts.LabelSelector)
+	}
+	fieldSelector, err := fields.ParseSelector(opts.FieldSelector)
+	if err != nil {
+		return nil, errors.WrapC(err, metav1.ErrInvalidSelector, "invalid field selector %q", opts.FieldSelector)
+	}
+	if _, err := metav1.TerminatingScope(opts.Terminating); err != nil {
+		return nil, err
+	}
+
+	sch, err := schema.Parse(reflect.New(t).Interface(), &s.schemas, schema.NamingStrategy{})
+	if err != nil {
+		return nil, err
+	}
+	type fieldRequirement struct {
+		field *schema.Field
+		equal bool
+		value string
+	}
+	var requirements []fieldRequirement
+	for _, r := range fieldSelector.Requirements() {
+		column, err := metav1.FieldSelectorColumn(r.Field, metav1.SelectableFields(reflect.New(t).Interface()))
+		if err != nil {
+			return nil, err
+		}
+		f := sch.LookUpField(column)
+		if f == nil || f.DBName == "" {
+			return nil, errors.WithCode(metav1.ErrInvalidSelector, "field %q is not supported by field selectors", r.Field)
+		}
+		switch r.Operator {
+		case selection.Equals, selection.DoubleEquals, selection.NotEquals:
+		default:
+			return nil, errors.WithCode(metav1.ErrInvalidSelector, "operator %q is not supported by field selectors", r.Operator)
+		}
+		requirements = append(requirements, fieldRequirement{field: f, equal: r.Operator != selection.NotEquals, value: r.Value})
+	}
+
+	return func(obj metav1.Object) bool {
+		switch opts.Terminating {
+		case metav1.TerminatingExclude:
+			if metav1.IsTerminating(obj) {
+				return false
+			}
+		case metav1.TerminatingOnly:
+			if !metav1.IsTerminating(obj) {
+				return false
+			}
+		}
+		if !labelSelector.Matches(labels.Set(obj.GetLabels())) {
+			return false
+		}
+		for _, r := range requirements {
+			value, _ := r.field.ValueOf(context.Background(), reflect.ValueOf(obj).Elem())
+			if (fieldString(value) == r.value) != r.equal {
+				return false
+			}
+		}
+
+		return true
+	}, nil
+}
+
+// store keeps a copy of obj.
+func (t *memoryTable) store(obj metav1.Object) {
+	stored, _ := deepCopy(reflect.ValueOf(obj)).Interface().(metav1.Object)
+	t.objects[obj.GetName()] = stored
+}
+
+// objectsByID returns the objects of the table ordered by ID. t may be nil.
+func (t *memoryTable) objectsByID(order metav1.SortOrder) []metav1.Object {
+	if t == nil {
+		return nil
+	}
+
+	objects := make([]metav1.Object, 0, len(t.objects))
+	for _, obj := range t.objects {
+		objects = append(objects, obj)
+	}
+	sort.Slice(objects, func(i, j int) bool {
+		if order == metav1.Descending {
+			return objects[i].GetID() > objects[j].GetID()
+		}
+
+		return objects[i].GetID() < objects[j].GetID()
+	})
+
+	return objects
+}
+
+// objectsAfter returns the objects sorted in order that come after the ID key.
+func objectsAfter(objects []metav1.Object, key uint64, order metav1.SortOrder) []metav1.Object {
+	i := sort.Search(len(objects), func(i int) bool {
+		if order == metav1.Descending {
+			return objects[i].GetID() < key
+		}
+
+		return objects[i].GetID() > key
+	})
+
+	return objects[i:]
+}
+
+// fieldString returns the string form of a field value a field selector compares.
+func fieldString(value interface{}) string {
+	v := reflect.ValueOf(value)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	if !v.IsValid() {
+		return ""
+	}
+
+	return fmt.Sprint(v.Interface())
+}
+
+// deepCopy returns a deep copy of v. Maps, slices and pointers are copied, so the copy shares no
+// memory with v that could be modified through the exported fields of either.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Ptr:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.New(v.Type().Elem())
+		c.Elem().Set(deepCopy(v.Elem()))
+
+		return c
+	case reflect.Interface:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.New(v.Type()).Elem()
+		c.Set(deepCopy(v.Elem()))
+
+		return c
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			c.SetMapIndex(iter.Key(), deepCopy(iter.Value()))
+		}
+
+		return c
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		c := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			c.Index(i).Set(deepCopy(v.Index(i)))
+		}
+
+		return c
+	case reflect.Struct:
+		c := reflect.New(v.Type()).Elem()
+		c.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if c.Field(i).CanSet() {
+				c.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+
+		return c
+	default:
+		return v
+	}
+}
@@ -0,0 +1,35 @@
+package storage_test
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/gzwillyy/components/pkg/storage"
+	"github.com/gzwillyy/components/pkg/storage/storagetest"
+)
+
+func TestGormStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Interface {
+		// a database file, unlike ":memory:", is shared by the connections of concurrent requests
+		dsn := filepath.Join(t.TempDir(), "storage.db") + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+		db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Discard})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := db.AutoMigrate(&storagetest.Secret{}); err != nil {
+			t.Fatal(err)
+		}
+
+		return storage.NewGormStorage(db)
+	})
+}
+
+func TestMemoryStorage(t *testing.T) {
+	storagetest.Run(t, func(t *testing.T) storage.Interface {
+		return storage.NewMemoryStorage()
+	})
+}
@@ -0,0 +1,493 @@
+// Package storagetest provides a conformance suite for implementations of storage.Interface.
+//
+//	func TestStorage(t *testing.T) {
+//		storagetest.Run(t, func(t *testing.T) storage.Interface {
+//			return storage.NewMemoryStorage()
+//		})
+//	}
+package storagetest
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"gorm.io/gorm"
+
+	"github.com/gzwillyy/components/errors"
+	metav1 "github.com/gzwillyy/components/pkg/meta/v1"
+	"github.com/gzwillyy/components/pkg/storage"
+)
+
+// Secret is the object stored by the suite. Storage backed by a database must be able to store it,
+// e.g. with db.AutoMigrate(&storagetest.Secret{}).
+type Secret struct {
+	metav1.TypeMeta   `json:",inline" gorm:"-"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Description string `json:"description" gorm:"column:description"`
+	Expires     int64  `json:"expires" gorm:"column:expires"`
+}
+
+// BeforeCreate assigns the instance ID like the applications do.
+func (s *Secret) BeforeCreate(tx *gorm.DB) error {
+	if s.InstanceID == "" {
+		s.InstanceID = "secret-" + s.Name
+	}
+
+	return s.ObjectMeta.BeforeCreate(tx)
+}
+
+// SelectableFields implements metav1.FieldSelectable, expires is deliberately not selectable.
+func (s *Secret) SelectableFields() []string {
+	return []string{"description"}
+}
+
+// SecretList is the list of secrets.
+type SecretList struct {
+	metav1.ListMeta `json:",inline"`
+
+	Items []*Secret `json:"items"`
+}
+
+// Run runs the conformance suite. newStorage returns an empty storage for each subtest.
+func Run(t *testing.T, newStorage func(t *testing.T) storage.Interface) {
+	t.Helper()
+
+	tests := []struct {
+		name string
+		test func(t *testing.T, s storage.Interface)
+	}{
+		{"CreateGet", testCreateGet},
+		{"ConcurrentCreate", testConcurrentCreate},
+		{"Update", testUpdate},
+		{"Delete", testDelete},
+		{"GracefulDelete", testGracefulDelete},
+		{"Selectors", testSelectors},
+		{"Terminating", testTerminating},
+		{"Pagination", testPagination},
+		{"InvalidListOptions", testInvalidListOptions},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newStorage(t))
+		})
+	}
+}
+
+func testCreateGet(t *testing.T, s storage.Interface) {
+	ctx := context.Background()
+
+	obj := newSecret("colin", nil)
+	if err := s.Create(ctx, obj, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	if obj.ID == 0 || obj.ResourceVersion != 1 || obj.CreatedAt.IsZero() || obj.UpdatedAt.IsZero() {
+		t.Errorf("expected ID, resource version and timestamps to be set, got %+v", obj.ObjectMeta)
+	}
+
+	if err := s.Create(ctx, newSecret("colin", nil), metav1.CreateOptions{}); !errors.IsCode(err, metav1.ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists for a duplicate name, got %v", err)
+	}
+
+	var got Secret
+	if err := s.Get(ctx, "colin", &got, metav1.GetOptions{}); err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.ID != obj.ID || got.Description != "colin's secret" || got.Labels["app"] != "iam" || got.ResourceVersion != 1 {
+		t.Errorf("unexpected object %+v", got)
+	}
+
+	// the stored object doesn't share memory with the objects passed in and out
+	got.Labels["app"] = "changed"
+	obj.Labels["app"] = "changed"
+	var again Secret
+	if err := s.Get(ctx, "colin", &again, metav1.GetOptions{}); err != nil || again.Labels["app"] != "iam" {
+		t.Errorf("expected the stored labels to be unchanged, got %v, %v", again.Labels, err)
+	}
+
+	if err := s.Get(ctx, "lisa", &got, metav1.GetOptions{}); !errors.IsCode(err, metav1.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing object, got %v", err)
+	}
+}
+
+func testConcurrentCreate(t *testing.T, s storage.Interface) {
+	const n = 10
+	errs := make(chan error, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			errs <- s.Create(context.Background(), newSecret("colin", nil), metav1.CreateOptions{})
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !errors.IsCode(err, metav1.ErrAlreadyExists):
+			t.Errorf("expected ErrAlreadyExists, got %v", err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("expected exactly one create to succeed, got %d", created)
+	}
+	if list := mustList(t, s, metav1.ListOptions{}); len(list.Items) != 1 {
+		t.Errorf("expected one stored object, got %v", names(list))
+	}
+}
+
+func testUpdate(t *testing.T, s storage.Interface) {
+	ctx := context.Background()
+	obj := mustCreate(t, s, newSecret("colin", nil))
+
+	var stored Secret
+	mustGet(t, s, "colin", &stored)
+	stored.Description = "rotated"
+	if err := s.Update(ctx, &stored, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if stored.ResourceVersion != 2 || stored.ID != obj.ID {
+		t.Errorf("expected resource version 2 and ID %d, got %+v", obj.ID, stored.ObjectMeta)
+	}
+
+	var got Secret
+	mustGet(t, s, "colin", &got)
+	if got.Description != "rotated" || got.ResourceVersion != 2 || got.UpdatedAt.Before(obj.UpdatedAt) {
+		t.Errorf("unexpected object after update %+v", got)
+	}
+
+	obj.Description = "stale"
+	if err := s.Update(ctx, obj, metav1.UpdateOptions{}); !errors.IsCode(err, metav1.ErrConflict) {
+		t.Errorf("expected ErrConflict for a stale resource version, got %v", err)
+	}
+
+	if err := s.Update(ctx, newSecret("lisa", nil), metav1.UpdateOptions{}); !errors.IsCode(err, metav1.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing object, got %v", err)
+	}
+
+	// preconditions are checked against the stored object
+	got.Description = "preconditions"
+	if err := s.Update(ctx, &got, metav1.UpdateOptions{Preconditions: metav1.NewRVPreconditions(1)}); !errors.IsCode(err, metav1.ErrConflict) {
+		t.Errorf("expected ErrConflict for failed preconditions, got %v", err)
+	}
+
+	// a dry run updates obj but not the stored object
+	got.Description = "dry run"
+	if err := s.Update(ctx, &got, metav1.UpdateOptions{DryRun: []string{"All"}}); err != nil || got.ResourceVersion != 3 {
+		t.Errorf("dry run update: %v %+v", err, got.ObjectMeta)
+	}
+	var stale Secret
+	mustGet(t, s, "colin", &stale)
+	if stale.Description != "rotated" || stale.ResourceVersion != 2 {
+		t.Errorf("expected a dry run not to update the stored object, got %+v", stale)
+	}
+	if err := s.Update(ctx, &got, metav1.UpdateOptions{DryRun: []string{"All"}}); !errors.IsCode(err, metav1.ErrConflict) {
+		t.Errorf("expected ErrConflict for a dry run with a stale resource version, got %v", err)
+	}
+
+	// a dry run create checks the name but doesn't store the object
+	if err := s.Create(ctx, newSecret("colin", nil), metav1.CreateOptions{DryRun: []string{"All"}}); !errors.IsCode(err, metav1.ErrAlreadyExists) {
+		t.Errorf("expected ErrAlreadyExists for a dry run create, got %v", err)
+	}
+	dryRun := newSecret("lisa", nil)
+	if err := s.Create(ctx, dryRun, metav1.CreateOptions{DryRun: []string{"All"}}); err != nil || dryRun.ResourceVersion != 1 {
+		t.Errorf("dry run create: %v %+v", err, dryRun.ObjectMeta)
+	}
+	if err := s.Get(ctx, "lisa", &Secret{}, metav1.GetOptions{}); !errors.IsCode(err, metav1.ErrNotFound) {
+		t.Errorf("expected a dry run create not to store the object, got %v", err)
+	}
+}
+
+func testDelete(t *testing.T, s storage.Interface) {
+	ctx := context.Background()
+	obj := mustCreate(t, s, newSecret("colin", nil))
+
+	if err := s.Delete(ctx, obj, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	var got Secret
+	if err := s.Get(ctx, "colin", &got, metav1.GetOptions{}); !errors.IsCode(err, metav1.ErrNotFound) {
+		t.Errorf("expected the object to be purged, got %v", err)
+	}
+
+	if err := s.Delete(ctx, obj, metav1.DeleteOptions{}); !errors.IsCode(err, metav1.ErrNotFound) {
+		t.Errorf("expected ErrNotFound for a missing object, got %v", err)
+	}
+
+	// a new object may reuse the name
+	mustCreate(t, s, newSecret("colin", nil))
+}
+
+func testGracefulDelete(t *testing.T, s storage.Interface) {
+	ctx := context.Background()
+	obj := mustCreate(t, s, newSecret("colin", nil, "iam.gzwillyy.com/cleanup"))
+
+	if err := s.Delete(ctx, obj, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	var got Secret
+	mustGet(t, s, "colin", &got)
+	if !metav1.IsTerminating(&got) || got.ResourceVersion != 2 {
+		t.Fatalf("expected a terminating object with resource version 2, got %+v", got.ObjectMeta)
+	}
+
+	// deleting a terminating object again keeps it
+	if err := s.Delete(ctx, &got, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete terminating object: %v", err)
+	}
+	mustGet(t, s, "colin", &got)
+
+	// removing the last finalizer purges it
+	metav1.RemoveFinalizer(&got, "iam.gzwillyy.com/cleanup")
+	if err := s.Update(ctx, &got, metav1.UpdateOptions{}); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	if err := s.Get(ctx, "colin", &got, metav1.GetOptions{}); !errors.IsCode(err, metav1.ErrNotFound) {
+		t.Errorf("expected the object to be purged after removing its finalizers, got %v", err)
+	}
+
+	// the finalizers of the stored object are honored when deleting by name
+	mustCreate(t, s, newSecret("tom", nil, "iam.gzwillyy.com/cleanup"))
+	byName := &Secret{ObjectMeta: metav1.ObjectMeta{Name: "tom"}}
+	if err := s.Delete(ctx, byName, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete by name: %v", err)
+	}
+	if !metav1.IsTerminating(byName) {
+		t.Errorf("expected the deleted object to be terminating, got %+v", byName.ObjectMeta)
+	}
+	var tom Secret
+	mustGet(t, s, "tom", &tom)
+	if !metav1.IsTerminating(&tom) || !metav1.ContainsFinalizer(&tom, "iam.gzwillyy.com/cleanup") {
+		t.Errorf("expected a terminating object keeping its finalizers, got %+v", tom.ObjectMeta)
+	}
+
+	// a stale resource version is a conflict
+	mustCreate(t, s, newSecret("jack", nil, "iam.gzwillyy.com/cleanup"))
+	stale := &Secret{ObjectMeta: metav1.ObjectMeta{Name: "jack", ResourceVersion: 5}}
+	if err := s.Delete(ctx, stale, metav1.DeleteOptions{}); !errors.IsCode(err, metav1.ErrConflict) {
+		t.Errorf("expected ErrConflict for a stale resource version, got %v", err)
+	}
+
+	// unscoped deletion ignores finalizers
+	obj = mustCreate(t, s, newSecret("lisa", nil, "iam.gzwillyy.com/cleanup"))
+	if err := s.Delete(ctx, obj, metav1.DeleteOptions{Unscoped: true}); err != nil {
+		t.Fatalf("unscoped delete: %v", err)
+	}
+	if err := s.Get(ctx, "lisa", &Secret{}, metav1.GetOptions{}); !errors.IsCode(err, metav1.ErrNotFound) {
+		t.Errorf("expected the object to be purged by an unscoped deletion, got %v", err)
+	}
+}
+
+func testSelectors(t *testing.T, s storage.Interface) {
+	mustCreate(t, s, newSecret("colin", map[string]string{"app": "iam", "tier": "backend"}))
+	mustCreate(t, s, newSecret("lisa", map[string]string{"app": "iam", "tier": "frontend"}))
+	mustCreate(t, s, newSecret("tom", map[string]string{"app": "apiserver"}))
+
+	tests := []struct {
+		name string
+		opts metav1.ListOptions
+		want []string
+	}{
+		{"all", metav1.ListOptions{}, []string{"colin", "lisa", "tom"}},
+		{"label equals", metav1.ListOptions{LabelSelector: "app=iam"}, []string{"colin", "lisa"}},
+		{"label not equals", metav1.ListOptions{LabelSelector: "tier!=backend"}, []string{"lisa", "tom"}},
+		{"label in", metav1.ListOptions{LabelSelector: "tier in (backend,frontend)"}, []string{"colin", "lisa"}},
+		{"label exists", metav1.ListOptions{LabelSelector: "!tier"}, []string{"tom"}},
+		{"field", metav1.ListOptions{FieldSelector: "metadata.name=lisa"}, []string{"lisa"}},
+		{"field not equals", metav1.ListOptions{FieldSelector: "description!=tom's secret"}, []string{"colin", "lisa"}},
+		{"label and field", metav1.ListOptions{LabelSelector: "app=iam", FieldSelector: "name!=colin"}, []string{"lisa"}},
+		{"no match", metav1.ListOptions{LabelSelector: "app=none"}, nil},
+	}
+	for _, tt := range tests {
+		list := mustList(t, s, tt.opts)
+		if got := names(list); !equal(got, tt.want) || list.TotalCount != int64(len(tt.want)) {
+			t.Errorf("%s: got %v (total %d), want %v", tt.name, got, list.TotalCount, tt.want)
+		}
+	}
+}
+
+func testTerminating(t *testing.T, s storage.Interface) {
+	mustCreate(t, s, newSecret("colin", nil))
+	lisa := mustCreate(t, s, newSecret("lisa", nil, "iam.gzwillyy.com/cleanup"))
+	if err := s.Delete(context.Background(), lisa, metav1.DeleteOptions{}); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	tests := []struct {
+		policy metav1.TerminatingPolicy
+		want   []string
+	}{
+		{"", []string{"colin", "lisa"}},
+		{metav1.TerminatingInclude, []string{"colin", "lisa"}},
+		{metav1.TerminatingExclude, []string{"colin"}},
+		{metav1.TerminatingOnly, []string{"lisa"}},
+	}
+	for _, tt := range tests {
+		list := mustList(t, s, metav1.ListOptions{Terminating: tt.policy})
+		if got := names(list); !equal(got, tt.want) {
+			t.Errorf("terminating %q: got %v, want %v", tt.policy, got, tt.want)
+		}
+	}
+}
+
+func testPagination(t *testing.T, s storage.Interface) {
+	all := []string{"a", "b", "c", "d", "e"}
+	for _, name := range all {
+		mustCreate(t, s, newSecret(name, map[string]string{"app": "iam"}))
+	}
+	mustCreate(t, s, newSecret("other", map[string]string{"app": "other"}))
+
+	for _, order := range []metav1.SortOrder{metav1.Ascending, metav1.Descending} {
+		want := all
+		if order == metav1.Descending {
+			want = []string{"e", "d", "c", "b", "a"}
+		}
+
+		var got []string
+		opts := metav1.ListOptions{LabelSelector: "app=iam", Limit: int64Ptr(2), Order: order}
+		for page := 0; ; page++ {
+			list := mustList(t, s, opts)
+			got = append(got, names(list)...)
+			if list.TotalCount != int64(len(all)) {
+				t.Errorf("%s page %d: expected total count %d, got %d", order, page, len(all), list.TotalCount)
+			}
+			if list.Continue == "" {
+				if list.RemainingItemCount != nil {
+					t.Errorf("%s page %d: expected no remaining item count on the last page", order, page)
+				}
+
+				break
+			}
+			if remaining := int64(len(all) - len(got)); list.RemainingItemCount == nil || *list.RemainingItemCount != remaining {
+				t.Errorf("%s page %d: expected %d remaining items, got %v", order, page, remaining, list.RemainingItemCount)
+			}
+			if page > len(all) {
+				t.Fatalf("%s: pagination doesn't end", order)
+			}
+			opts.Continue = list.Continue
+		}
+		if !equal(got, want) {
+			t.Errorf("%s: paginated %v, want %v", order, got, want)
+		}
+	}
+
+	// items created after the first page show up on a later page
+	list := mustList(t, s, metav1.ListOptions{LabelSelector: "app=iam", Limit: int64Ptr(3)})
+	mustCreate(t, s, newSecret("f", map[string]string{"app": "iam"}))
+	list = mustList(t, s, metav1.ListOptions{LabelSelector: "app=iam", Limit: int64Ptr(3), Continue: list.Continue})
+	if got := names(list); !equal(got, []string{"d", "e", "f"}) || list.Continue != "" {
+		t.Errorf("expected the last page to include the new item, got %v %q", got, list.Continue)
+	}
+
+	list = mustList(t, s, metav1.ListOptions{LabelSelector: "app=iam", Offset: int64Ptr(4), Limit: int64Ptr(1)})
+	if got := names(list); !equal(got, []string{"e"}) || list.TotalCount != 6 {
+		t.Errorf("offset: got %v (total %d)", got, list.TotalCount)
+	}
+}
+
+func testInvalidListOptions(t *testing.T, s storage.Interface) {
+	tests := []struct {
+		name string
+		opts metav1.ListOptions
+		code int
+	}{
+		{"label selector", metav1.ListOptions{LabelSelector: "app in (iam"}, metav1.ErrInvalidSelector},
+		{"field operator", metav1.ListOptions{FieldSelector: "name=a,name~b"}, metav1.ErrInvalidSelector},
+		{"field not selectable", metav1.ListOptions{FieldSelector: "expires=3600"}, metav1.ErrInvalidSelector},
+		{"metadata field not selectable", metav1.ListOptions{FieldSelector: "metadata.description=x"}, metav1.ErrInvalidSelector},
+		{"terminating policy", metav1.ListOptions{Terminating: "Sometimes"}, metav1.ErrInvalidSelector},
+		{"continue", metav1.ListOptions{Continue: "garbage"}, metav1.ErrInvalidContinue},
+		{"continue with offset", metav1.ListOptions{Continue: continueToken(t, s), Offset: int64Ptr(1)}, metav1.ErrInvalidContinue},
+		{"order", metav1.ListOptions{Order: "random"}, metav1.ErrValidation},
+		{"limit", metav1.ListOptions{Limit: int64Ptr(-1)}, metav1.ErrValidation},
+	}
+	for _, tt := range tests {
+		var list SecretList
+		if err := s.List(context.Background(), &list, tt.opts); !errors.IsCode(err, tt.code) {
+			t.Errorf("%s: expected code %d, got %v", tt.name, tt.code, err)
+		}
+	}
+}
+
+// continueToken returns a valid continue token of a list of s.
+func continueToken(t *testing.T, s storage.Interface) string {
+	t.Helper()
+
+	mustCreate(t, s, newSecret("colin", nil))
+	mustCreate(t, s, newSecret("lisa", nil))
+
+	return mustList(t, s, metav1.ListOptions{Limit: int64Ptr(1)}).Continue
+}
+
+func newSecret(name string, labels map[string]string, finalizers ...string) *Secret {
+	if labels == nil {
+		labels = map[string]string{"app": "iam"}
+	}
+
+	return &Secret{
+		ObjectMeta:  metav1.ObjectMeta{Name: name, Labels: labels, Finalizers: finalizers},
+		Description: name + "'s secret",
+		Expires:     3600,
+	}
+}
+
+func mustCreate(t *testing.T, s storage.Interface, obj *Secret) *Secret {
+	t.Helper()
+
+	if err := s.Create(context.Background(), obj, metav1.CreateOptions{}); err != nil {
+		t.Fatalf("create %s: %v", obj.Name, err)
+	}
+
+	return obj
+}
+
+func mustGet(t *testing.T, s storage.Interface, name string, out *Secret) {
+	t.Helper()
+
+	if err := s.Get(context.Background(), name, out, metav1.GetOptions{}); err != nil {
+		t.Fatalf("get %s: %v", name, err)
+	}
+}
+
+func mustList(t *testing.T, s storage.Interface, opts metav1.ListOptions) *SecretList {
+	t.Helper()
+
+	var list SecretList
+	if err := s.List(context.Background(), &list, opts); err != nil {
+		t.Fatalf("list %+v: %v", opts, err)
+	}
+
+	return &list
+}
+
+func names(list *SecretList) []string {
+	var names []string
+	for _, item := range list.Items {
+		names = append(names, item.Name)
+	}
+
+	return names
+}
+
+func equal(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+func int64Ptr(i int64) *int64 {
+	return &i
+}
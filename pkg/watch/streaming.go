@@ -0,0 +1,104 @@
+package watch
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+
+	"github.com/gzwillyy/components/pkg/runtime"
+)
+
+// eventType is used to read the type of an event before its object is decoded.
+type eventType struct {
+	Type EventType `json:"type"`
+}
+
+// eventObject is used to decode the object of an event into a typed value.
+type eventObject struct {
+	Object interface{} `json:"object"`
+}
+
+// Encoder serializes watch events into frames of an underlying stream. Events are written as
+// {"type": ..., "object": ...} envelopes with the negotiated serializer, so the serializer must be
+// able to encode plain structs (JSON, YAML and msgpack can, protobuf can't).
+type Encoder struct {
+	w          io.Writer
+	serializer runtime.Serializer
+	frames     runtime.FrameWriter
+}
+
+// NewEncoder returns an Encoder that writes events to w. If w is an http.Flusher, every event is
+// flushed to the client as soon as it is written.
+func NewEncoder(w io.Writer, serializer runtime.Serializer, framer runtime.Framer) *Encoder {
+	return &Encoder{
+		w:          w,
+		serializer: serializer,
+		frames:     framer.NewFrameWriter(w),
+	}
+}
+
+// Encode writes an event to the stream.
+func (e *Encoder) Encode(event Event) error {
+	if !event.Type.IsValid() {
+		return fmt.Errorf("unknown watch event type %q", event.Type)
+	}
+	data, err := e.serializer.Encode(&event)
+	if err != nil {
+		return fmt.Errorf("unable to encode watch event %s: %w", event.Type, err)
+	}
+	if err := e.frames.WriteFrame(data); err != nil {
+		return err
+	}
+	if flusher, ok := e.w.(http.Flusher); ok {
+		flusher.Flush()
+	}
+
+	return nil
+}
+
+// Decoder reads the events written by an Encoder.
+type Decoder struct {
+	serializer runtime.Serializer
+	frames     runtime.FrameReader
+	newObject  func(EventType) interface{}
+}
+
+// NewDecoder returns a Decoder that reads events from r. newObject returns the pointer the object of
+// an event of the given type is decoded into; the objects of Error events are always decoded into
+// a *Status.
+func NewDecoder(r io.Reader, serializer runtime.Serializer, framer runtime.Framer, newObject func(EventType) interface{}) *Decoder {
+	return &Decoder{
+		serializer: serializer,
+		frames:     framer.NewFrameReader(r),
+		newObject:  newObject,
+	}
+}
+
+// Decode reads the next event from the stream. It returns io.EOF when the stream ends at an
+// event boundary.
+func (d *Decoder) Decode() (Event, error) {
+	frame, err := d.frames.ReadFrame()
+	if err != nil {
+		return Event{}, err
+	}
+
+	var typ eventType
+	if err := d.serializer.Decode(frame, &typ); err != nil {
+		return Event{}, fmt.Errorf("unable to decode watch event: %w", err)
+	}
+	if !typ.Type.IsValid() {
+		return Event{}, fmt.Errorf("got invalid watch event type: %q", typ.Type)
+	}
+
+	obj := eventObject{}
+	if typ.Type == Error {
+		obj.Object = &Status{}
+	} else {
+		obj.Object = d.newObject(typ.Type)
+	}
+	if err := d.serializer.Decode(frame, &obj); err != nil {
+		return Event{}, fmt.Errorf("unable to decode watch event %s: %w", typ.Type, err)
+	}
+
+	return Event{Type: typ.Type, Object: obj.Object}, nil
+}
@@ -0,0 +1,93 @@
+package watch
+
+import (
+	"errors"
+	"io"
+	"sync"
+)
+
+// StreamWatcher turns a stream of encoded events into a watch.Interface. It stops watching when the
+// stream ends, when decoding fails or when Stop is called.
+type StreamWatcher struct {
+	sync.Mutex
+	source  io.ReadCloser
+	decoder *Decoder
+	result  chan Event
+	done    chan struct{}
+}
+
+var _ Interface = &StreamWatcher{}
+
+// NewStreamWatcher creates a StreamWatcher that reads events with decoder from source. source is
+// the stream the decoder reads from, it is closed when the watcher stops.
+func NewStreamWatcher(source io.ReadCloser, decoder *Decoder) *StreamWatcher {
+	sw := &StreamWatcher{
+		source:  source,
+		decoder: decoder,
+		// It's easy for a consumer to add buffering via an extra
+		// goroutine/channel, but impossible for them to remove it,
+		// so nonbuffered is better.
+		result: make(chan Event),
+		// If the watcher is externally stopped there is no receiver anymore
+		// and the send operations on the result channel, especially the
+		// error reporting might block forever.
+		// Therefore a dedicated stop channel is used to resolve this blocking.
+		done: make(chan struct{}),
+	}
+	go sw.receive()
+
+	return sw
+}
+
+// ResultChan implements Interface.
+func (sw *StreamWatcher) ResultChan() <-chan Event {
+	return sw.result
+}
+
+// Stop implements Interface.
+func (sw *StreamWatcher) Stop() {
+	// Call Close() exactly once by locking and setting a flag.
+	sw.Lock()
+	defer sw.Unlock()
+	// closing a closed channel always panics, therefore check before closing
+	select {
+	case <-sw.done:
+	default:
+		close(sw.done)
+		_ = sw.source.Close()
+	}
+}
+
+// stopping returns true if Stop() has been called.
+func (sw *StreamWatcher) stopping() bool {
+	select {
+	case <-sw.done:
+		return true
+	default:
+		return false
+	}
+}
+
+// receive reads result from the decoder in a loop and sends down the result channel.
+func (sw *StreamWatcher) receive() {
+	defer close(sw.result)
+	defer sw.Stop()
+	for {
+		event, err := sw.decoder.Decode()
+		if err != nil {
+			// Ignore expected error.
+			if sw.stopping() || errors.Is(err, io.EOF) {
+				return
+			}
+			event = NewErrorEvent(err)
+		}
+		select {
+		case <-sw.done:
+			return
+		case sw.result <- event:
+		}
+		if err != nil {
+			return
+		}
+	}
+}
@@ -0,0 +1,90 @@
+// Package watch contains a generic watchable interface, and the event types and encoders used to
+// push a stream of changes to a client.
+package watch
+
+import (
+	"github.com/gzwillyy/components/errors"
+)
+
+// Interface can be implemented by anything that knows how to watch and report changes.
+type Interface interface {
+	// Stop stops watching. Will close the channel returned by ResultChan(). Releases
+	// any resources used by the watch.
+	Stop()
+
+	// ResultChan returns a chan which will receive all the events. If an error occurs
+	// or Stop() is called, the implementation will close this channel and
+	// release any resources used by the watch.
+	ResultChan() <-chan Event
+}
+
+// EventType defines the possible types of events.
+type EventType string
+
+// The event types of a watch stream.
+const (
+	Added    EventType = "ADDED"
+	Modified EventType = "MODIFIED"
+	Deleted  EventType = "DELETED"
+	Bookmark EventType = "BOOKMARK"
+	Error    EventType = "ERROR"
+)
+
+// IsValid returns true if t is one of the known event types.
+func (t EventType) IsValid() bool {
+	switch t {
+	case Added, Modified, Deleted, Bookmark, Error:
+		return true
+	default:
+		return false
+	}
+}
+
+// Event represents a single event to a watched resource.
+type Event struct {
+	Type EventType `json:"type"`
+
+	// Object is:
+	//  * If Type is Added or Modified: the new state of the object.
+	//  * If Type is Deleted: the state of the object immediately before deletion.
+	//  * If Type is Bookmark: the object (instance of a type being watched) where
+	//    only the resource version field is set. On successful restart of watch from a
+	//    bookmark resource version, client is guaranteed to not get repeat event
+	//    nor miss any events.
+	//  * If Type is Error: *Status is recommended; other types may make sense
+	//    depending on context.
+	Object interface{} `json:"object"`
+}
+
+// Status is the object of an Error event. It carries the same information as the error
+// responses of the REST API.
+type Status struct {
+	// Code defines the business error code.
+	Code int `json:"code"`
+
+	// Message contains the detail of this message, it is safe to be exposed to external.
+	Message string `json:"message"`
+
+	// Reference returns the reference document which maybe useful to solve this error.
+	Reference string `json:"reference,omitempty"`
+}
+
+// Error implements the error interface.
+func (s *Status) Error() string {
+	return s.Message
+}
+
+// NewErrorEvent returns an Error event for err. err is parsed with errors.ParseCoder, so only the
+// user-safe message of a coded error is sent to the client.
+func NewErrorEvent(err error) Event {
+	coder := errors.ParseCoder(err)
+
+	return Event{
+		Type: Error,
+		Object: &Status{
+			Code:      coder.Code(),
+			Message:   coder.String(),
+			Reference: coder.Reference(),
+		},
+	}
+}
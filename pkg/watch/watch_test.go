@@ -0,0 +1,136 @@
+package watch_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gzwillyy/components/pkg/runtime"
+	"github.com/gzwillyy/components/pkg/watch"
+)
+
+type user struct {
+	Name            string `json:"name"`
+	ResourceVersion uint64 `json:"resourceVersion,omitempty"`
+}
+
+func newUser(watch.EventType) interface{} {
+	return &user{}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	events := []watch.Event{
+		{Type: watch.Added, Object: &user{Name: "colin", ResourceVersion: 1}},
+		{Type: watch.Modified, Object: &user{Name: "colin", ResourceVersion: 1 << 60}},
+		{Type: watch.Bookmark, Object: &user{ResourceVersion: 3}},
+		{Type: watch.Deleted, Object: &user{Name: "colin", ResourceVersion: 4}},
+		{Type: watch.Error, Object: &watch.Status{Code: 100101, Message: "Database error"}},
+	}
+
+	for _, info := range runtime.NewNegotiatedSerializer().SupportedMediaTypes() {
+		if info.MediaType == runtime.ContentTypeProtobuf {
+			continue
+		}
+		framer := runtime.FramerFor(info)
+
+		var buf bytes.Buffer
+		enc := watch.NewEncoder(&buf, info.Serializer, framer)
+		for _, event := range events {
+			if err := enc.Encode(event); err != nil {
+				t.Fatalf("%s: unexpected error: %v", info.MediaType, err)
+			}
+		}
+
+		dec := watch.NewDecoder(&buf, info.Serializer, framer, newUser)
+		for _, expected := range events {
+			event, err := dec.Decode()
+			if err != nil {
+				t.Fatalf("%s: unexpected error: %v", info.MediaType, err)
+			}
+			if event.Type != expected.Type {
+				t.Errorf("%s: expected event %s, got %s", info.MediaType, expected.Type, event.Type)
+			}
+			switch obj := event.Object.(type) {
+			case *user:
+				if *obj != *expected.Object.(*user) {
+					t.Errorf("%s: expected %+v, got %+v", info.MediaType, expected.Object, obj)
+				}
+			case *watch.Status:
+				if *obj != *expected.Object.(*watch.Status) {
+					t.Errorf("%s: expected %+v, got %+v", info.MediaType, expected.Object, obj)
+				}
+			default:
+				t.Errorf("%s: unexpected object %T", info.MediaType, obj)
+			}
+		}
+		if _, err := dec.Decode(); !errors.Is(err, io.EOF) {
+			t.Errorf("%s: expected io.EOF, got %v", info.MediaType, err)
+		}
+	}
+}
+
+func TestEncoderFlushes(t *testing.T) {
+	rec := httptest.NewRecorder()
+	enc := watch.NewEncoder(rec, jsonSerializer(t), runtime.NewlineDelimitedFramer)
+	if err := enc.Encode(watch.Event{Type: watch.Added, Object: &user{Name: "colin"}}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !rec.Flushed {
+		t.Errorf("expected the response to be flushed")
+	}
+	if expected := `{"type":"ADDED","object":{"name":"colin"}}` + "\n"; rec.Body.String() != expected {
+		t.Errorf("expected %q, got %q", expected, rec.Body.String())
+	}
+
+	if err := enc.Encode(watch.Event{Type: "UPDATED"}); err == nil {
+		t.Errorf("expected error encoding an unknown event type")
+	}
+}
+
+func TestStreamWatcher(t *testing.T) {
+	var buf bytes.Buffer
+	enc := watch.NewEncoder(&buf, jsonSerializer(t), runtime.NewlineDelimitedFramer)
+	_ = enc.Encode(watch.Event{Type: watch.Added, Object: &user{Name: "colin"}})
+	buf.WriteString(`{"type":"UPDATED","object":{}}` + "\n")
+
+	source := io.NopCloser(&buf)
+	w := watch.NewStreamWatcher(source, watch.NewDecoder(source, jsonSerializer(t), runtime.NewlineDelimitedFramer, newUser))
+
+	var events []watch.Event
+	for event := range w.ResultChan() {
+		events = append(events, event)
+	}
+	if len(events) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(events))
+	}
+	if events[0].Type != watch.Added || events[0].Object.(*user).Name != "colin" {
+		t.Errorf("unexpected event: %+v", events[0])
+	}
+	if events[1].Type != watch.Error {
+		t.Errorf("expected an error event for an invalid event type, got %+v", events[1])
+	}
+	w.Stop()
+}
+
+func TestStreamWatcherStop(t *testing.T) {
+	r, pw := io.Pipe()
+	w := watch.NewStreamWatcher(r, watch.NewDecoder(r, jsonSerializer(t), runtime.NewlineDelimitedFramer, newUser))
+	w.Stop()
+	if _, ok := <-w.ResultChan(); ok {
+		t.Errorf("expected the result channel to be closed")
+	}
+	if _, err := pw.Write([]byte("{}\n")); !errors.Is(err, io.ErrClosedPipe) {
+		t.Errorf("expected the source to be closed, got %v", err)
+	}
+}
+
+func jsonSerializer(t *testing.T) runtime.Serializer {
+	info, err := runtime.NegotiateInputSerializer(runtime.ContentTypeJSON, runtime.NewNegotiatedSerializer())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	return info.Serializer
+}
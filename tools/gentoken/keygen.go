@@ -0,0 +1,147 @@
+package main
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"os"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func runKeygen(args []string) error {
+	fs := newFlagSet("keygen", "gentoken keygen [OPTIONS]")
+	algorithm := fs.StringP("algorithm", "a", "RS256",
+		"Algorithm of the key - RS*, PS*, ES256, ES384, ES512, EdDSA, or HS* to generate a random secret key")
+	bits := fs.Int("bits", 2048, "Size of RSA keys")
+	kid := fs.String("kid", "", "Key ID, defaults to the RFC 7638 thumbprint of the public key")
+	out := fs.StringP("out", "o", "", "Write the keys to OUT.key, OUT.pub and OUT.jwks.json instead of stdout")
+	jwksFile := fs.String("jwks", "", "Add the public key to the JWKS in this file, e.g. to rotate keys")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+
+		return fmt.Errorf("keygen takes no arguments")
+	}
+
+	method := jwt.GetSigningMethod(*algorithm)
+	if method == nil {
+		return fmt.Errorf("unsupported algorithm %q", *algorithm)
+	}
+	if hmacMethod, ok := method.(*jwt.SigningMethodHMAC); ok {
+		secret := make([]byte, hmacMethod.Hash.Size())
+		if _, err := rand.Read(secret); err != nil {
+			return err
+		}
+		fmt.Println(encodeSegment(secret))
+
+		return nil
+	}
+
+	private, err := generateKey(method, *bits)
+	if err != nil {
+		return err
+	}
+	jwk, err := newJWK(*kid, *algorithm, private.Public())
+	if err != nil {
+		return err
+	}
+	if jwk.Kid == "" {
+		jwk.Kid = thumbprint(jwk)
+	}
+
+	jwks := &JWKS{}
+	if *jwksFile != "" {
+		if jwks, err = readJWKS(*jwksFile); err != nil {
+			return err
+		}
+		for _, key := range jwks.Keys {
+			if key.Kid == jwk.Kid {
+				return fmt.Errorf("key %q already exists in %s", jwk.Kid, *jwksFile)
+			}
+		}
+	}
+	jwks.Keys = append(jwks.Keys, jwk)
+
+	privatePEM, publicPEM, err := encodeKeyPair(private)
+	if err != nil {
+		return err
+	}
+	jwksJSON, err := json.MarshalIndent(jwks, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if *jwksFile != "" {
+		if err := os.WriteFile(*jwksFile, append(jwksJSON, '\n'), 0o644); err != nil {
+			return err
+		}
+	}
+	if *out == "" {
+		fmt.Printf("Key ID: %s\n%s%s", jwk.Kid, privatePEM, publicPEM)
+		if *jwksFile == "" {
+			fmt.Println(string(jwksJSON))
+		}
+
+		return nil
+	}
+
+	files := map[string][]byte{*out + ".key": privatePEM, *out + ".pub": publicPEM}
+	if *jwksFile == "" {
+		files[*out+".jwks.json"] = append(jwksJSON, '\n')
+	}
+	for name, data := range files {
+		perm := os.FileMode(0o644)
+		if name == *out+".key" {
+			perm = 0o600
+		}
+		if err := os.WriteFile(name, data, perm); err != nil {
+			return err
+		}
+	}
+	fmt.Printf("Key ID: %s\n", jwk.Kid)
+
+	return nil
+}
+
+// generateKey generates a private key of the algorithm.
+func generateKey(method jwt.SigningMethod, bits int) (crypto.Signer, error) {
+	switch m := method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		return rsa.GenerateKey(rand.Reader, bits)
+	case *jwt.SigningMethodECDSA:
+		curves := map[int]elliptic.Curve{256: elliptic.P256(), 384: elliptic.P384(), 521: elliptic.P521()}
+
+		return ecdsa.GenerateKey(curves[m.CurveBits], rand.Reader)
+	case *jwt.SigningMethodEd25519:
+		_, private, err := ed25519.GenerateKey(rand.Reader)
+
+		return private, err
+	default:
+		return nil, fmt.Errorf("unsupported algorithm %q", method.Alg())
+	}
+}
+
+// encodeKeyPair returns the PKCS#8 private key and the PKIX public key in PEM.
+func encodeKeyPair(private crypto.Signer) ([]byte, []byte, error) {
+	privateDER, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, nil, err
+	}
+	publicDER, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: privateDER}),
+		pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: publicDER}), nil
+}
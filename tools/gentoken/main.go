@@ -1,61 +1,66 @@
+// gentoken signs, decodes and verifies JWT tokens and generates the keys to sign them.
 package main
 
 import (
 	"fmt"
 	"os"
-	"time"
 
-	"github.com/dgrijalva/jwt-go/v4"
 	"github.com/spf13/pflag"
 )
 
-var (
-	cliAlgorithm = pflag.StringP(
-		"algorithm",
-		"",
-		"HS256",
-		"Signing algorithm - possible values are HS256, HS384, HS512",
-	)
-	cliTimeout = pflag.DurationP("timeout", "", 2*time.Hour, "JWT token expires time")
-	help       = pflag.BoolP("help", "h", false, "Print this help message")
-)
+const usage = `Usage: gentoken COMMAND [OPTIONS] [ARGS]
 
-func main() {
-	pflag.Usage = func() {
-		fmt.Println(`Usage: gentoken [OPTIONS] SECRETID SECRETKEY`)
-		pflag.PrintDefaults()
-	}
-	pflag.Parse()
+Commands:
+  sign     Sign a token: gentoken sign [OPTIONS] SECRETID [SECRETKEY]
+  decode   Print the header and claims of a token without verifying it: gentoken decode TOKEN
+  verify   Verify a token and print its claims: gentoken verify [OPTIONS] TOKEN
+  keygen   Generate a key pair and its JWKS: gentoken keygen [OPTIONS]
 
-	if *help {
-		pflag.Usage()
+"gentoken SECRETID SECRETKEY" is a shorthand of "gentoken sign SECRETID SECRETKEY".
+Run "gentoken COMMAND --help" for the options of a command. TOKEN "-" reads the token from stdin.`
 
-		return
-	}
+// command runs a subcommand with its arguments.
+type command func(args []string) error
 
-	if pflag.NArg() != 2 {
-		pflag.Usage()
-		os.Exit(1)
-	}
+var commands = map[string]command{
+	"sign":   runSign,
+	"decode": runDecode,
+	"verify": runVerify,
+	"keygen": runKeygen,
+}
 
-	token, err := createJWTToken(*cliAlgorithm, *cliTimeout, os.Args[1], os.Args[2])
-	if err != nil {
-		fmt.Printf("Error: %s\n", err.Error())
+func main() {
+	args := os.Args[1:]
+	if len(args) == 0 || args[0] == "-h" || args[0] == "--help" || args[0] == "help" {
+		fmt.Println(usage)
 
 		return
 	}
 
-	fmt.Println(token)
-}
+	run, ok := commands[args[0]]
+	if ok {
+		args = args[1:]
+	} else {
+		run = runSign
+	}
 
-func createJWTToken(algorithm string, timeout time.Duration, secretID, secretKey string) (string, error) {
-	expire := time.Now().Add(timeout)
+	if err := run(args); err != nil {
+		if err == pflag.ErrHelp {
+			return
+		}
+		fmt.Fprintf(os.Stderr, "Error: %s\n", err.Error())
+		os.Exit(1)
+	}
+}
 
-	token := jwt.NewWithClaims(jwt.GetSigningMethod(algorithm), jwt.MapClaims{
-		"kid": secretID,
-		"exp": expire.Unix(),
-		"iat": time.Now().Unix(),
-	})
+// newFlagSet returns the flag set of a subcommand printing usageLine with the defaults on --help.
+func newFlagSet(name, usageLine string) *pflag.FlagSet {
+	fs := pflag.NewFlagSet(name, pflag.ContinueOnError)
+	fs.SetOutput(os.Stdout)
+	fs.Usage = func() {
+		fmt.Println("Usage: " + usageLine)
+		fs.PrintDefaults()
+	}
 
-	return token.SignedString([]byte(secretKey))
+	return fs
 }
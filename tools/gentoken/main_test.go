@@ -0,0 +1,113 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// run runs a subcommand and returns what it printed to stdout.
+func run(t *testing.T, args ...string) (string, error) {
+	t.Helper()
+
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	output := make(chan string)
+	go func() {
+		data, _ := io.ReadAll(r)
+		output <- string(data)
+	}()
+	err = commands[args[0]](args[1:])
+	_ = w.Close()
+
+	return <-output, err
+}
+
+// keygen generates a key pair of the algorithm in dir and returns its file prefix and key ID.
+func keygen(t *testing.T, dir, algorithm string) (string, string) {
+	t.Helper()
+
+	out := filepath.Join(dir, algorithm)
+	printed, err := run(t, "keygen", "--algorithm", algorithm, "--out", out)
+	if err != nil {
+		t.Fatalf("keygen %s: %v", algorithm, err)
+	}
+	kid := strings.TrimSpace(strings.TrimPrefix(printed, "Key ID:"))
+	if kid == "" {
+		t.Fatalf("keygen %s printed no key ID: %q", algorithm, printed)
+	}
+
+	return out, kid
+}
+
+func TestRoundTrip(t *testing.T) {
+	for _, algorithm := range []string{"RS256", "PS384", "ES256", "ES512", "EdDSA"} {
+		t.Run(algorithm, func(t *testing.T) {
+			out, kid := keygen(t, t.TempDir(), algorithm)
+
+			token, err := run(t, "sign", "--algorithm", algorithm, "--key", out+".key", "--sub", "colin", kid)
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			printed, err := run(t, "verify", "--jwks", out+".jwks.json", strings.TrimSpace(token))
+			if err != nil {
+				t.Fatalf("verify: %v", err)
+			}
+			if !strings.HasPrefix(printed, "Token is valid") || !strings.Contains(printed, `"sub": "colin"`) {
+				t.Errorf("unexpected verify output %q", printed)
+			}
+
+			printed, err = run(t, "verify", "--key", out+".pub", strings.TrimSpace(token))
+			if err != nil || !strings.HasPrefix(printed, "Token is valid") {
+				t.Errorf("verify with the public key: %q %v", printed, err)
+			}
+		})
+	}
+}
+
+func TestVerifyRejectsMismatchedAlgorithm(t *testing.T) {
+	dir := t.TempDir()
+	rsaKey, rsaKid := keygen(t, dir, "RS256")
+	ecKey, ecKid := keygen(t, dir, "ES256")
+
+	tests := []struct {
+		name string
+		sign []string
+		jwks string
+	}{
+		// the JWK of the key pins RS256
+		{"other RSA algorithm", []string{"sign", "--algorithm", "PS256", "--key", rsaKey + ".key", rsaKid}, rsaKey},
+		// an HMAC token using the kid of an EC key, signed with the public key as the secret
+		{"HMAC with the kid of an EC key", []string{"sign", "--algorithm", "HS256", "--", ecKid, readFile(t, ecKey+".pub")}, ecKey},
+		// an RSA token using the kid of an EC key
+		{"RSA with the kid of an EC key", []string{"sign", "--algorithm", "RS256", "--key", rsaKey + ".key", ecKid}, ecKey},
+	}
+	for _, tt := range tests {
+		token, err := run(t, tt.sign...)
+		if err != nil {
+			t.Fatalf("%s: sign: %v", tt.name, err)
+		}
+		if printed, err := run(t, "verify", "--jwks", tt.jwks+".jwks.json", strings.TrimSpace(token)); err == nil {
+			t.Errorf("%s: expected verify to reject the token, got %q", tt.name, printed)
+		}
+	}
+}
+
+func readFile(t *testing.T, name string) string {
+	t.Helper()
+
+	data, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return string(data)
+}
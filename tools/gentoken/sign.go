@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func runSign(args []string) error {
+	fs := newFlagSet("sign", "gentoken sign [OPTIONS] SECRETID [SECRETKEY]")
+	algorithm := fs.StringP("algorithm", "a", "HS256",
+		"Signing algorithm - possible values are HS256, HS384, HS512, RS256, RS384, RS512, PS256, PS384, PS512, ES256, ES384, ES512, EdDSA")
+	keyFile := fs.StringP("key", "k", "", "PEM encoded private key of the asymmetric algorithms, SECRETKEY must be omitted")
+	timeout := fs.DurationP("timeout", "", 2*time.Hour, "JWT token expires time, 0 signs a token without exp")
+	audience := fs.StringSlice("aud", nil, "Audience of the token, can be repeated")
+	issuer := fs.String("iss", "", "Issuer of the token")
+	subject := fs.String("sub", "", "Subject of the token")
+	custom := fs.StringArray("claim", nil, "Custom claim KEY=VALUE, VALUE is parsed as JSON if possible, can be repeated")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+
+	method := jwt.GetSigningMethod(*algorithm)
+	if method == nil {
+		return fmt.Errorf("unsupported algorithm %q", *algorithm)
+	}
+	_, symmetric := method.(*jwt.SigningMethodHMAC)
+
+	var key interface{}
+	switch {
+	case symmetric && fs.NArg() == 2:
+		key = []byte(fs.Arg(1))
+	case !symmetric && fs.NArg() == 1 && *keyFile != "":
+		signer, err := readPrivateKey(*keyFile)
+		if err != nil {
+			return err
+		}
+		key = signer
+	default:
+		fs.Usage()
+
+		return fmt.Errorf("HMAC algorithms require SECRETID and SECRETKEY, asymmetric algorithms SECRETID and --key")
+	}
+
+	claims := jwt.MapClaims{}
+	for _, c := range *custom {
+		name, value, ok := strings.Cut(c, "=")
+		if !ok || name == "" {
+			return fmt.Errorf("invalid claim %q, expected KEY=VALUE", c)
+		}
+		claims[name] = claimValue(value)
+	}
+
+	now := time.Now()
+	claims["iat"] = now.Unix()
+	if *timeout > 0 {
+		claims["exp"] = now.Add(*timeout).Unix()
+	}
+	if *issuer != "" {
+		claims["iss"] = *issuer
+	}
+	if *subject != "" {
+		claims["sub"] = *subject
+	}
+	switch len(*audience) {
+	case 0:
+	case 1:
+		claims["aud"] = (*audience)[0]
+	default:
+		claims["aud"] = *audience
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = fs.Arg(0)
+	signed, err := token.SignedString(key)
+	if err != nil {
+		return err
+	}
+	fmt.Println(signed)
+
+	return nil
+}
+
+// claimValue returns value decoded as JSON, or value itself if it isn't valid JSON.
+func claimValue(value string) interface{} {
+	var v interface{}
+	if err := json.Unmarshal([]byte(value), &v); err != nil {
+		return value
+	}
+
+	return v
+}
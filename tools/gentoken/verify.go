@@ -0,0 +1,215 @@
+package main
+
+import (
+	"bufio"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func runDecode(args []string) error {
+	fs := newFlagSet("decode", "gentoken decode TOKEN")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+
+		return fmt.Errorf("decode requires TOKEN")
+	}
+	raw, err := readToken(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	claims := jwt.MapClaims{}
+	token, _, err := jwt.NewParser().ParseUnverified(raw, claims)
+	if err != nil {
+		return err
+	}
+
+	return printToken(token.Header, claims)
+}
+
+func runVerify(args []string) error {
+	fs := newFlagSet("verify", "gentoken verify [OPTIONS] TOKEN")
+	keyFile := fs.StringP("key", "k", "", "PEM encoded public key, certificate or private key")
+	secret := fs.String("secret", "", "Secret key of the HMAC algorithms")
+	jwksFile := fs.String("jwks", "", "JWKS file, the key is selected by the kid of the token")
+	algorithm := fs.StringP("algorithm", "a", "", "Expected signing algorithm, defaults to the algorithms of the key")
+	leeway := fs.Duration("leeway", time.Minute, "Allowed clock skew when checking exp, nbf and iat")
+	audience := fs.String("aud", "", "Expected audience")
+	issuer := fs.String("iss", "", "Expected issuer")
+	requireExp := fs.Bool("require-exp", true, "Reject tokens without exp")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+
+		return fmt.Errorf("verify requires TOKEN")
+	}
+	raw, err := readToken(fs.Arg(0))
+	if err != nil {
+		return err
+	}
+
+	keyFunc, methods, err := verificationKey(*keyFile, *secret, *jwksFile)
+	if err != nil {
+		return err
+	}
+	if *algorithm != "" {
+		methods = []string{*algorithm}
+	}
+
+	opts := []jwt.ParserOption{jwt.WithLeeway(*leeway), jwt.WithIssuedAt(), jwt.WithValidMethods(methods)}
+	if *audience != "" {
+		opts = append(opts, jwt.WithAudience(*audience))
+	}
+	if *issuer != "" {
+		opts = append(opts, jwt.WithIssuer(*issuer))
+	}
+	if *requireExp {
+		opts = append(opts, jwt.WithExpirationRequired())
+	}
+
+	claims := jwt.MapClaims{}
+	token, err := jwt.NewParser(opts...).ParseWithClaims(raw, claims, keyFunc)
+	if err != nil {
+		return fmt.Errorf("token is invalid: %w", err)
+	}
+	fmt.Println("Token is valid")
+
+	return printToken(token.Header, claims)
+}
+
+// verificationKey returns the key function and the accepted algorithms of the key given by
+// exactly one of the flags.
+func verificationKey(keyFile, secret, jwksFile string) (jwt.Keyfunc, []string, error) {
+	set := 0
+	for _, v := range []string{keyFile, secret, jwksFile} {
+		if v != "" {
+			set++
+		}
+	}
+	if set != 1 {
+		return nil, nil, fmt.Errorf("exactly one of --key, --secret and --jwks is required")
+	}
+
+	switch {
+	case secret != "":
+		return func(*jwt.Token) (interface{}, error) { return []byte(secret), nil }, []string{"HS256", "HS384", "HS512"}, nil
+	case keyFile != "":
+		key, err := readPublicKey(keyFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		methods, err := methodsOf(key)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		return func(*jwt.Token) (interface{}, error) { return key, nil }, methods, nil
+	default:
+		jwks, err := readJWKS(jwksFile)
+		if err != nil {
+			return nil, nil, err
+		}
+		keyFunc := func(token *jwt.Token) (interface{}, error) {
+			kid, _ := token.Header["kid"].(string)
+			for _, jwk := range jwks.Keys {
+				if jwk.Kid != kid {
+					continue
+				}
+				if jwk.Alg != "" && jwk.Alg != token.Method.Alg() {
+					return nil, fmt.Errorf("key %q requires algorithm %s", kid, jwk.Alg)
+				}
+				key, err := jwk.PublicKey()
+				if err != nil {
+					return nil, err
+				}
+				methods, err := methodsOf(key)
+				if err != nil {
+					return nil, err
+				}
+				if !contains(methods, token.Method.Alg()) {
+					return nil, fmt.Errorf("key %q can't verify algorithm %s", kid, token.Method.Alg())
+				}
+
+				return key, nil
+			}
+
+			return nil, fmt.Errorf("key %q not found in %s", kid, jwksFile)
+		}
+
+		return keyFunc, []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}, nil
+	}
+}
+
+// methodsOf returns the algorithms a public key can verify.
+func methodsOf(key crypto.PublicKey) ([]string, error) {
+	switch key.(type) {
+	case *rsa.PublicKey:
+		return []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512"}, nil
+	case *ecdsa.PublicKey, ed25519.PublicKey:
+		alg, err := algorithmOf(key)
+
+		return []string{alg}, err
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", key)
+	}
+}
+
+// readToken returns arg, or the first line of stdin if arg is "-".
+func readToken(arg string) (string, error) {
+	if arg != "-" {
+		return strings.TrimSpace(arg), nil
+	}
+
+	line, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if line = strings.TrimSpace(line); line == "" {
+		return "", fmt.Errorf("no token on stdin: %v", err)
+	}
+
+	return line, nil
+}
+
+// printToken pretty-prints the header and the claims, followed by the times of the time claims.
+func printToken(header map[string]interface{}, claims jwt.MapClaims) error {
+	for _, part := range []struct {
+		name  string
+		value interface{}
+	}{{"Header", header}, {"Claims", claims}} {
+		data, err := json.MarshalIndent(part.value, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Printf("%s:\n%s\n", part.name, data)
+	}
+
+	for _, name := range []string{"iat", "nbf", "exp"} {
+		if v, ok := claims[name].(float64); ok {
+			fmt.Printf("%s: %s\n", name, time.Unix(int64(v), 0).Format(time.RFC3339))
+		}
+	}
+
+	return nil
+}
+
+func contains(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+
+	return false
+}
@@ -3,7 +3,7 @@ module github.com/gzwillyy/components/tools
 go 1.21.8
 
 require (
-	github.com/dgrijalva/jwt-go/v4 v4.0.0-preview1
+	github.com/golang-jwt/jwt/v5 v5.2.1
 	github.com/gzwillyy/components/pkg v0.0.0-20240411102357-b88938e2a810
 	github.com/spf13/pflag v1.0.5
 	golang.org/x/tools v0.20.0